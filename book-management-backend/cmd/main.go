@@ -2,14 +2,20 @@ package main
 
 import (
 	"log"
+	"time"
 
 	configs "book-management/configs"
 	_ "book-management/docs" // docs is generated by Swag CLI
 	"book-management/internal/handlers"
+	"book-management/internal/models"
 	"book-management/internal/repositories"
 	router "book-management/internal/routers"
 	"book-management/internal/services"
 	database "book-management/pkg/databases"
+	"book-management/pkg/imaging"
+	"book-management/pkg/metadata"
+	"book-management/pkg/pagination"
+	"book-management/pkg/signedurl"
 	"book-management/pkg/utils"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -28,6 +34,8 @@ import (
 func main() {
 	// 1. Load config
 	cfg := configs.LoadConfig()
+	pagination.SetMaxLimit(cfg.PaginationMaxLimit)
+	handlers.SetPublicBaseURL(cfg.PublicBaseURL)
 
 	// 2. Connect DB
 	db, err := database.ConnectPostgres(cfg)
@@ -41,13 +49,32 @@ func main() {
 		log.Fatal("❌ Failed to init Cloudinary: ", err)
 	}
 
+	// 2.2 Init metadata providers (ISBN lookup)
+	metadataProviders, err := metadata.NewProviders(cfg)
+	if err != nil {
+		log.Fatal("❌ Failed to init metadata providers: ", err)
+	}
+
 	// 3. Init repository, service, handler
 	authorRepo := repositories.NewAuthorRepository()
 	authorService := services.NewAuthorService(authorRepo, db)
 	authorHandler := handlers.NewAuthorHandler(authorService)
 
+	seriesRepo := repositories.NewSeriesRepository()
+	seriesService := services.NewSeriesService(seriesRepo, db)
+	seriesHandler := handlers.NewSeriesHandler(seriesService)
+
+	copyRepo := repositories.NewCopyRepository()
+	loanRepo := repositories.NewLoanRepository()
+	holdRepo := repositories.NewHoldRepository()
+	bookFileRepo := repositories.NewBookFileRepository()
+	pickupWindow := time.Duration(cfg.HoldPickupDays) * 24 * time.Hour
+
 	bookRepo := repositories.NewBookRepository()
-	bookService := services.NewBookService(bookRepo, authorRepo, db, cloudUtil)
+	bookService := services.NewBookService(bookRepo, authorRepo, seriesRepo, copyRepo, loanRepo, holdRepo, bookFileRepo, db, cloudUtil, imaging.Limits{
+		MaxBytes:     cfg.CoverMaxBytes,
+		MaxDimension: cfg.CoverMaxDimension,
+	}, metadataProviders, pickupWindow)
 	bookHandler := handlers.NewBookHandler(bookService)
 
 	userRepo := repositories.NewUserRepository()
@@ -58,12 +85,87 @@ func main() {
 	genreService := services.NewGenreService(genreRepo, db)
 	genreHandler := handlers.NewGenreHandler(genreService)
 
+	workRepo := repositories.NewWorkRepository()
+	workService := services.NewWorkService(workRepo, bookRepo, genreRepo, db)
+	workHandler := handlers.NewWorkHandler(workService)
+
+	tagRepo := repositories.NewTagRepository()
+	tagService := services.NewTagService(tagRepo, bookRepo, db)
+	tagHandler := handlers.NewTagHandler(tagService)
+
+	searchRepo := repositories.NewSearchRepository()
+	searchService := services.NewSearchService(searchRepo, db)
+	searchHandler := handlers.NewSearchHandler(searchService)
+
+	importRepo := repositories.NewImportRepository()
+	importService := services.NewImportService(importRepo, bookRepo, authorRepo, genreRepo, db)
+	if n, err := importService.FailInterruptedJobs(); err != nil {
+		log.Printf("⚠️ Could not fail interrupted import jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("⚠️ %d import job(s) interrupted by the last shutdown were marked as failed", n)
+	}
+	importHandler := handlers.NewImportHandler(importService)
+
+	exportRepo := repositories.NewExportRepository()
+	exportService := services.NewExportService(exportRepo, db)
+	exportHandler := handlers.NewExportHandler(exportService)
+
+	citationService := services.NewCitationService(bookRepo, db)
+	citationHandler := handlers.NewCitationHandler(citationService)
+
+	fileSigner := signedurl.NewSigner(cfg.FileSigningSecret)
+	fileLinkTTL := time.Duration(cfg.FileURLTTLSeconds) * time.Second
+
+	opdsService := services.NewOPDSService(bookRepo, authorRepo, genreRepo, bookFileRepo, fileSigner, fileLinkTTL, db)
+	opdsHandler := handlers.NewOPDSHandler(opdsService)
+
+	publicService := services.NewPublicService(bookRepo, db)
+	publicHandler := handlers.NewPublicHandler(publicService)
+
+	bookFileService := services.NewBookFileService(bookFileRepo, bookRepo, db, cloudUtil, fileSigner, cfg.FileMaxBytes, fileLinkTTL)
+	bookFileHandler := handlers.NewBookFileHandler(bookFileService)
+
+	policyRepo := repositories.NewCirculationPolicyRepository()
+	defaultPolicy := models.CirculationPolicy{
+		Name:           "default",
+		LoanPeriodDays: cfg.LoanPeriodDays,
+		MaxRenewals:    cfg.LoanMaxRenewals,
+	}
+	if cfg.LoanMaxActive > 0 {
+		defaultPolicy.MaxConcurrentLoans = &cfg.LoanMaxActive
+	}
+	loanService := services.NewLoanService(loanRepo, copyRepo, userRepo, bookRepo, policyRepo, holdRepo, db, defaultPolicy, pickupWindow)
+	loanHandler := handlers.NewLoanHandler(loanService)
+
+	policyService := services.NewCirculationPolicyService(policyRepo, userRepo, db)
+	policyHandler := handlers.NewCirculationPolicyHandler(policyService)
+
+	copyService := services.NewCopyService(copyRepo, bookRepo, loanRepo, holdRepo, db, pickupWindow)
+	copyHandler := handlers.NewCopyHandler(copyService)
+
+	holdService := services.NewHoldService(holdRepo, bookRepo, copyRepo, loanRepo, userRepo, db, pickupWindow)
+	holdHandler := handlers.NewHoldHandler(holdService)
+
 	// 4. Setup Gin router
 	server := router.NewRouter(
 		authorHandler,
 		bookHandler,
 		authHandler,
 		genreHandler,
+		seriesHandler,
+		workHandler,
+		tagHandler,
+		searchHandler,
+		importHandler,
+		exportHandler,
+		citationHandler,
+		opdsHandler,
+		publicHandler,
+		bookFileHandler,
+		copyHandler,
+		loanHandler,
+		policyHandler,
+		holdHandler,
 		cfg,
 	)
 
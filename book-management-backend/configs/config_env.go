@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +20,9 @@ type Config struct {
 	JWTSecret  string
 	Env        string
 
+	// Scheme and host of the public site (e.g. https://books.example.com), empty means the request host
+	PublicBaseURL string
+
 	// JWT TTL
 	AccessTokenTTLMinutes int
 	RefreshTokenTTLHours  int
@@ -27,6 +31,32 @@ type Config struct {
 	CloudName string
 	APIKey    string
 	APISecret string
+
+	// Pagination
+	PaginationMaxLimit int
+
+	// Cover uploads
+	CoverMaxBytes     int64
+	CoverMaxDimension int // pixels, applies to width and height
+
+	// Digital files (e-books, audiobooks), downloaded through signed URLs
+	FileMaxBytes      int64
+	FileURLTTLSeconds int
+	FileSigningSecret string
+
+	// Circulation, defaults used when no circulation policy matches
+	LoanPeriodDays  int
+	LoanMaxRenewals int
+	LoanMaxActive   int // 0 means no limit
+	HoldPickupDays  int // how long a returned copy stays set aside for a hold
+
+	// Metadata lookup by ISBN, providers are asked in order
+	MetadataProviders      []string // e.g. openlibrary, googlebooks
+	MetadataTimeoutSeconds int
+	OpenLibraryURL         string
+	OpenLibraryCoversURL   string
+	GoogleBooksURL         string
+	GoogleBooksAPIKey      string
 }
 
 func LoadConfig() *Config {
@@ -45,6 +75,16 @@ func LoadConfig() *Config {
 	// Convert TTL
 	accessTTL, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_TTL_MIN", "15"))
 	refreshTTL, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_TTL_HOUR", "168"))
+	paginationMaxLimit, _ := strconv.Atoi(getEnv("PAGINATION_MAX_LIMIT", "100"))
+	metadataTimeout, _ := strconv.Atoi(getEnv("METADATA_TIMEOUT_SEC", "5"))
+	coverMaxBytes, _ := strconv.ParseInt(getEnv("COVER_MAX_BYTES", "5242880"), 10, 64) // 5 MB
+	coverMaxDimension, _ := strconv.Atoi(getEnv("COVER_MAX_DIMENSION", "6000"))
+	fileMaxBytes, _ := strconv.ParseInt(getEnv("FILE_MAX_BYTES", "104857600"), 10, 64) // 100 MB
+	fileURLTTL, _ := strconv.Atoi(getEnv("FILE_URL_TTL_SEC", "900"))
+	loanPeriodDays, _ := strconv.Atoi(getEnv("LOAN_PERIOD_DAYS", "14"))
+	loanMaxRenewals, _ := strconv.Atoi(getEnv("LOAN_MAX_RENEWALS", "2"))
+	loanMaxActive, _ := strconv.Atoi(getEnv("LOAN_MAX_ACTIVE", "0"))
+	holdPickupDays, _ := strconv.Atoi(getEnv("HOLD_PICKUP_DAYS", "7"))
 	// Check ENVIRONMENT
 	log.Println("========================== ENVIRONMENT ==========================")
 	log.Printf("🚀 Running with environment: %s", envFile)
@@ -55,20 +95,37 @@ func LoadConfig() *Config {
 	log.Println("=================================================================")
 
 	return &Config{
-		DBHost:                os.Getenv("DB_HOST"),
-		DBPort:                os.Getenv("DB_PORT"),
-		DBUser:                os.Getenv("DB_USER"),
-		DBPassword:            os.Getenv("DB_PASSWORD"),
-		DBName:                os.Getenv("DB_NAME"),
-		DBSSLMode:             os.Getenv("DB_SSLMODE"),
-		HTTPPort:              os.Getenv("HTTP_PORT"),
-		JWTSecret:             os.Getenv("JWT_SECRET"),
-		Env:                   env,
-		AccessTokenTTLMinutes: accessTTL,
-		RefreshTokenTTLHours:  refreshTTL,
-		CloudName:             os.Getenv("CLOUDINARY_CLOUD_NAME"),
-		APIKey:                os.Getenv("CLOUDINARY_API_KEY"),
-		APISecret:             os.Getenv("CLOUDINARY_API_SECRET"),
+		DBHost:                 os.Getenv("DB_HOST"),
+		DBPort:                 os.Getenv("DB_PORT"),
+		DBUser:                 os.Getenv("DB_USER"),
+		DBPassword:             os.Getenv("DB_PASSWORD"),
+		DBName:                 os.Getenv("DB_NAME"),
+		DBSSLMode:              os.Getenv("DB_SSLMODE"),
+		HTTPPort:               os.Getenv("HTTP_PORT"),
+		JWTSecret:              os.Getenv("JWT_SECRET"),
+		Env:                    env,
+		PublicBaseURL:          os.Getenv("PUBLIC_BASE_URL"),
+		AccessTokenTTLMinutes:  accessTTL,
+		RefreshTokenTTLHours:   refreshTTL,
+		CloudName:              os.Getenv("CLOUDINARY_CLOUD_NAME"),
+		APIKey:                 os.Getenv("CLOUDINARY_API_KEY"),
+		APISecret:              os.Getenv("CLOUDINARY_API_SECRET"),
+		PaginationMaxLimit:     paginationMaxLimit,
+		CoverMaxBytes:          coverMaxBytes,
+		CoverMaxDimension:      coverMaxDimension,
+		FileMaxBytes:           fileMaxBytes,
+		FileURLTTLSeconds:      fileURLTTL,
+		FileSigningSecret:      getEnv("FILE_SIGNING_SECRET", os.Getenv("JWT_SECRET")),
+		LoanPeriodDays:         loanPeriodDays,
+		LoanMaxRenewals:        loanMaxRenewals,
+		LoanMaxActive:          loanMaxActive,
+		HoldPickupDays:         holdPickupDays,
+		MetadataProviders:      splitList(getEnv("METADATA_PROVIDERS", "openlibrary,googlebooks")),
+		MetadataTimeoutSeconds: metadataTimeout,
+		OpenLibraryURL:         os.Getenv("OPENLIBRARY_BASE_URL"),
+		OpenLibraryCoversURL:   os.Getenv("OPENLIBRARY_COVERS_URL"),
+		GoogleBooksURL:         os.Getenv("GOOGLE_BOOKS_BASE_URL"),
+		GoogleBooksAPIKey:      os.Getenv("GOOGLE_BOOKS_API_KEY"),
 	}
 }
 
@@ -78,3 +135,14 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// splitList splits a comma separated setting, dropping blanks
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -6,6 +6,8 @@ import (
 
 	"book-management/internal/models"
 	"book-management/internal/services"
+	"book-management/pkg/fieldset"
+	"book-management/pkg/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -42,6 +44,9 @@ func NewAuthorHandler(service services.IAuthorService) *AuthorHandler {
 	return &AuthorHandler{service: service}
 }
 
+// authorRelations are the relations an author list can embed with include=
+var authorRelations = []string{"books"}
+
 // Helper: map GORM Author to AuthorResponse
 func mapAuthorResponse(author *models.Author) AuthorResponse {
 	books := make([]BookSimple, len(author.Books))
@@ -65,41 +70,56 @@ func mapAuthorResponse(author *models.Author) AuthorResponse {
 // GET /authors?limit=10&offset=0
 // GetAuthors godoc
 // @Summary      Get list of authors
-// @Description  Retrieve authors with pagination
+// @Description  Retrieve authors ordered by ID with pagination as {data, page}, books are only embedded with include=books. Pages can be walked with offset or with page.next_cursor (next/prev links are also sent in the Link header)
 // @Tags         authors
 // @Accept       json
 // @Produce      json
-// @Param        limit   query     int  false  "Number of authors per page" default(10)
-// @Param        offset  query     int  false  "Starting offset" default(0)
+// @Param        limit          query     int     false  "Number of authors per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of authors to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all authors"
+// @Param        fields         query     string  false  "Comma separated fields to return, e.g. name,email (id is always returned)"
+// @Param        include        query     string  false  "Comma separated relations to embed: books"
 // @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
 // @Failure      500     {object}  map[string]string
 // @Router       /authors [get]
 // @Security BearerAuth
 func (h *AuthorHandler) GetAuthors(c *gin.Context) {
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	if limit < 1 {
-		limit = 10
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err == nil {
+		_, err = params.AfterID() // authors are listed by id only
 	}
-	if offset < 0 {
-		offset = 0
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	authors, err := h.service.GetAuthors(limit, offset)
+	selection, err := fieldset.Parse(c.Query("fields"), c.Query("include"), fieldset.JSONFields(AuthorResponse{}), authorRelations)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.GetAuthors(params, selection.Include())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]AuthorResponse, len(authors))
-	for i := range authors {
-		resp[i] = mapAuthorResponse(&authors[i])
+	resp := make([]map[string]any, len(result.Authors))
+	for i := range result.Authors {
+		item, err := selection.Apply(mapAuthorResponse(&result.Authors[i]))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp[i] = item
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"limit":  limit,
-		"offset": offset,
-		"data":   resp,
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
 	})
 }
 
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookFileHandler struct {
+	service services.IBookFileService
+}
+
+func NewBookFileHandler(service services.IBookFileService) *BookFileHandler {
+	return &BookFileHandler{service: service}
+}
+
+type BookFileResponse struct {
+	ID            uint   `json:"id"`
+	BookID        uint   `json:"book_id"`
+	Kind          string `json:"kind"` // full or sample
+	Title         string `json:"title"`
+	FileName      string `json:"file_name"`
+	Format        string `json:"format"` // epub, pdf or mp3
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`     // bytes
+	Checksum      string `json:"checksum"` // hex SHA-256
+	DownloadCount int64  `json:"download_count"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func mapBookFileResponse(file *models.BookFile) BookFileResponse {
+	return BookFileResponse{
+		ID:            file.ID,
+		BookID:        file.BookID,
+		Kind:          string(file.Kind),
+		Title:         file.Title,
+		FileName:      file.FileName,
+		Format:        file.Format,
+		ContentType:   file.ContentType,
+		Size:          file.Size,
+		Checksum:      file.Checksum,
+		DownloadCount: file.DownloadCount,
+		CreatedAt:     file.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+type BookFileLinkResponse struct {
+	URL       string `json:"url"` // works without a token until it expires
+	ExpiresAt string `json:"expires_at"`
+}
+
+// GET /books/:id/files
+// GetBookFiles godoc
+// @Summary      List the files of a book
+// @Description  List the digital files (EPUB, PDF, MP3) attached to a book, samples first, with their download counts
+// @Tags         files
+// @Produce      json
+// @Param        id   path      string  true  "Book ID"
+// @Success      200  {array}   handlers.BookFileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/files [get]
+// @Security BearerAuth
+func (h *BookFileHandler) GetBookFiles(c *gin.Context) {
+	files, httpStatus, err := h.service.GetBookFiles(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]BookFileResponse, len(files))
+	for i := range files {
+		resp[i] = mapBookFileResponse(&files[i])
+	}
+	c.JSON(httpStatus, resp)
+}
+
+// POST /books/:id/files
+// UploadBookFile godoc
+// @Summary      Attach a file to a book
+// @Description  Upload an EPUB, PDF or MP3 file, the format is detected from the content and the file is stored privately
+// @Tags         files
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    path      string true  "Book ID"
+// @Param        file  formData  file   true  "EPUB, PDF or MP3 file"
+// @Param        kind  formData  string false "Kind of file, defaults to full" Enums(full, sample)
+// @Param        title formData  string false "Title, e.g. Chapter 3"
+// @Success      201 {object} handlers.BookFileResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Failure      415 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Failure      502 {object} map[string]string
+// @Router       /books/{id}/files [post]
+// @Security BearerAuth
+func (h *BookFileHandler) UploadBookFile(c *gin.Context) {
+	var req services.BookFileUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, httpStatus, err := h.service.UploadBookFile(c.Param("id"), req, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapBookFileResponse(file))
+}
+
+// DELETE /books/:id/files/:fileId
+// DeleteBookFile godoc
+// @Summary      Delete a file of a book
+// @Description  Remove a file from a book and from storage, links already handed out stop working
+// @Tags         files
+// @Produce      json
+// @Param        id      path  string  true  "Book ID"
+// @Param        fileId  path  string  true  "File ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/files/{fileId} [delete]
+// @Security BearerAuth
+func (h *BookFileHandler) DeleteBookFile(c *gin.Context) {
+	httpStatus, err := h.service.DeleteBookFile(c.Param("id"), c.Param("fileId"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(httpStatus)
+}
+
+// GET /books/:id/files/:fileId/link
+// GetDownloadLink godoc
+// @Summary      Get a download link
+// @Description  Issue a signed, time-limited URL to download a file, the URL needs no token so it can be opened by a browser or an e-reader. It is not bound to the user: anyone holding it can download the file until it expires.
+// @Tags         files
+// @Produce      json
+// @Param        id      path  string  true  "Book ID"
+// @Param        fileId  path  string  true  "File ID"
+// @Success      200  {object}  handlers.BookFileLinkResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/files/{fileId}/link [get]
+// @Security BearerAuth
+func (h *BookFileHandler) GetDownloadLink(c *gin.Context) {
+	link, httpStatus, err := h.service.CreateDownloadLink(c.Param("id"), c.Param("fileId"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, BookFileLinkResponse{
+		URL:       requestBaseURL(c) + link.Path,
+		ExpiresAt: link.ExpiresAt.Format("02-01-2006 15:04:05"),
+	})
+}
+
+// GET /files/:id/download
+// DownloadFile godoc
+// @Summary      Download a file
+// @Description  Stream a file through a signed link from GET /books/{id}/files/{fileId}/link, byte ranges are supported and each full download is counted
+// @Tags         files
+// @Produce      application/epub+zip,application/pdf,audio/mpeg
+// @Param        id       path   string  true  "File ID"
+// @Param        expires  query  int     true  "Expiry, unix seconds"
+// @Param        sig      query  string  true  "Signature"
+// @Success      200  {file}    file
+// @Success      206  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      502  {object}  map[string]string
+// @Router       /files/{id}/download [get]
+func (h *BookFileHandler) DownloadFile(c *gin.Context) {
+	download, httpStatus, err := h.service.OpenDownload(c.Request.Context(), c.Param("id"), c.Request.URL.Query(), c.GetHeader("Range"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	defer download.Body.Close()
+
+	c.Header("Content-Type", download.File.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": download.File.FileName}))
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("Cache-Control", "private, no-store")
+	if download.ContentLength >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(download.ContentLength, 10))
+	}
+	status := http.StatusOK
+	if download.Partial {
+		status = http.StatusPartialContent
+		c.Header("Content-Range", download.ContentRange)
+	}
+	c.Status(status)
+
+	if _, err := io.Copy(c.Writer, download.Body); err != nil {
+		log.Printf("❌ Failed to stream file %d: %v", download.File.ID, err)
+	}
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"book-management/internal/models"
 	"book-management/internal/services"
+	"book-management/pkg/fieldset"
+	"book-management/pkg/pagination"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,14 +29,76 @@ type GenreResponseForBook struct {
 	Name string `json:"name"`
 }
 
+type SeriesResponseForBook struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	Position *float64 `json:"position"`
+}
+
+type ImageVariantsResponse struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+}
+
+type CopyAvailabilityResponse struct {
+	Total     int64 `json:"total"`
+	Available int64 `json:"available"`
+	OnLoan    int64 `json:"on_loan"`
+	OnHold    int64 `json:"on_hold"` // set aside for holds
+}
+
 type BookResponse struct {
-	ID        uint                   `json:"id"`
-	Title     string                 `json:"title"`
-	Author    AuthorResponseForBook  `json:"author"`
-	Genres    []GenreResponseForBook `json:"genres"`
-	Image     string                 `json:"image"`
-	CreatedAt string                 `json:"created_at"`
-	UpdatedAt string                 `json:"updated_at"`
+	ID          uint                   `json:"id"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Author      AuthorResponseForBook  `json:"author"`
+	Genres      []GenreResponseForBook `json:"genres"`
+	Series      *SeriesResponseForBook `json:"series"`
+	Tags        []string               `json:"tags"`
+	Image       string                 `json:"image"`
+	// Resized copies of an uploaded cover, null for external image URLs
+	ImageVariants *ImageVariantsResponse `json:"image_variants"`
+
+	// Edition details
+	WorkID          *uint  `json:"work_id"`
+	ISBN            string `json:"isbn"`
+	Publisher       string `json:"publisher"`
+	Language        string `json:"language"`
+	Format          string `json:"format"`
+	PublicationYear *int   `json:"publication_year"`
+
+	// Physical copies, the rest of Total is lost, damaged or in repair
+	Availability *CopyAvailabilityResponse `json:"availability"`
+
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// bookRelations are the relations a book list can embed with include=
+var bookRelations = []string{"author", "genres", "series", "tags"}
+
+func mapImageVariants(variants *models.ImageVariants) *ImageVariantsResponse {
+	if variants == nil {
+		return nil
+	}
+	return &ImageVariantsResponse{
+		Thumbnail: variants.Thumbnail,
+		Medium:    variants.Medium,
+		Large:     variants.Large,
+	}
+}
+
+func mapCopyAvailability(availability *models.CopyAvailability) *CopyAvailabilityResponse {
+	if availability == nil {
+		return nil
+	}
+	return &CopyAvailabilityResponse{
+		Total:     availability.Total,
+		Available: availability.Available,
+		OnLoan:    availability.OnLoan,
+		OnHold:    availability.OnHold,
+	}
 }
 
 func mapBookResponse(book *models.Book) BookResponse {
@@ -52,17 +117,54 @@ func mapBookResponse(book *models.Book) BookResponse {
 		}
 	}
 
+	// map tags
+	tags := make([]string, len(book.Tags))
+	for i, t := range book.Tags {
+		tags[i] = t.Name
+	}
+
+	// map series (nil when the book is standalone)
+	var seriesResp *SeriesResponseForBook
+	if book.Series != nil {
+		seriesResp = &SeriesResponseForBook{
+			ID:       book.Series.ID,
+			Name:     book.Series.Name,
+			Position: book.SeriesPosition,
+		}
+	}
+
 	return BookResponse{
-		ID:        book.ID,
-		Title:     book.Title,
-		Author:    authorResp,
-		Genres:    genresResp,
-		Image:     book.Image,
+		ID:          book.ID,
+		Title:       book.Title,
+		Description: book.Description,
+		Author:      authorResp,
+		Genres:      genresResp,
+		Series:      seriesResp,
+		Tags:        tags,
+		Image:       book.Image,
+
+		ImageVariants: mapImageVariants(book.ImageVariants),
+
+		WorkID:          book.WorkID,
+		ISBN:            book.ISBN,
+		Publisher:       book.Publisher,
+		Language:        book.Language,
+		Format:          string(book.Format),
+		PublicationYear: book.PublicationYear,
+
+		Availability: mapCopyAvailability(book.Availability),
+
 		CreatedAt: book.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt: book.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
 
+type FacetValueResponse struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
 // GET /books/:id
 // GetBookByID godoc
 // @Summary      Get book details by ID
@@ -89,38 +191,89 @@ func (h *BookHandler) GetBookByID(c *gin.Context) {
 
 // GET /books?limit=10&offset=0
 // GetAllBooks godoc
-// @Summary      Get all books with pagination
-// @Description  Retrieve a paginated list of books, each including its author
+// @Summary      Get all books with pagination, filtering and sorting
+// @Description  Retrieve a paginated list of books as {data, page}, relations (author, genres, series, tags) are only embedded when listed in include. Pages can be walked with offset or with page.next_cursor (keyset on the sort key and id, a single sort field other than publication_year), next/prev links are also sent in the Link header.
 // @Tags         books
 // @Produce      json
-// @Param        limit   query     string  false  "Limit number of books per page"  default(10)
-// @Param        offset  query     string  false  "Number of books to skip"         default(0)
+// @Param        limit   query     int     false  "Number of books per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset  query     int     false  "Number of books to skip"         default(0)
+// @Param        title         query  string  false  "Title contains (case-insensitive)"
+// @Param        author_id     query  int     false  "Author ID"
+// @Param        genre_ids     query  string  false  "Comma separated genre IDs, e.g. 1,4,7"
+// @Param        genre_match   query  string  false  "Match any or all of genre_ids"  Enums(any, all)  default(any)
+// @Param        created_from  query  string  false  "Created on/after (YYYY-MM-DD or RFC3339)"
+// @Param        created_to    query  string  false  "Created on/before (YYYY-MM-DD) or before (RFC3339)"
+// @Param        language      query  string  false  "Language code, e.g. en"
+// @Param        publisher     query  string  false  "Publisher contains (case-insensitive)"
+// @Param        tags          query  string  false  "Comma separated tag names, books must have all of them"
+// @Param        sort          query  string  false  "Sort fields, prefix with - for descending, e.g. title,-created_at (id, title, created_at, updated_at, publication_year, author_id)"
+// @Param        facets        query  string  false  "Comma separated facets to count over all matching books: genre, author, language, decade"
+// @Param        cursor        query  string  false  "Opaque next_cursor of the previous page (keyset pagination, cannot be combined with offset)"
+// @Param        include_total query  bool    false  "Also count all matching books"
+// @Param        fields        query  string  false  "Comma separated fields to return, e.g. title,isbn (id is always returned)"
+// @Param        include       query  string  false  "Comma separated relations to embed: author, genres, series, tags"
 // @Success      200     {object}  map[string]interface{}
 // @Failure      400     {object}  map[string]string
 // @Failure      500     {object}  map[string]string
 // @Router       /books [get]
 // @Security BearerAuth
 func (h *BookHandler) GetAllBooks(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+	var query services.BookListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	books, httpStatus, err := h.service.GetAllBooks(limitStr, offsetStr)
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	selection, err := fieldset.Parse(c.Query("fields"), c.Query("include"), fieldset.JSONFields(BookResponse{}), bookRelations)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.GetAllBooks(query, params, selection.Include())
 
 	if err != nil {
 		c.JSON(httpStatus, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]BookResponse, len(*books))
-	for i := range *books {
-		resp[i] = mapBookResponse(&(*books)[i])
+	resp := make([]map[string]any, len(result.Books))
+	for i := range result.Books {
+		item, err := selection.Apply(mapBookResponse(&result.Books[i]))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp[i] = item
 	}
 
-	c.JSON(httpStatus, gin.H{
-		"limit":  limitStr,
-		"offset": offsetStr,
-		"data":   resp,
-	})
+	body := gin.H{
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
+	}
+	if result.Facets != nil {
+		facets := make(map[string][]FacetValueResponse, len(result.Facets))
+		for name, counts := range result.Facets {
+			values := make([]FacetValueResponse, len(counts))
+			for i, fc := range counts {
+				values[i] = FacetValueResponse{
+					Value: fc.Value,
+					Label: fc.Label,
+					Count: fc.Count,
+				}
+			}
+			facets[name] = values
+		}
+		body["facets"] = facets
+	}
+
+	c.JSON(httpStatus, body)
 }
 
 // POST /books
@@ -130,12 +283,26 @@ func (h *BookHandler) GetAllBooks(c *gin.Context) {
 // @Tags         books
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        title    formData  string true "Book Title"
+// @Param        title    formData  string false "Book Title, required unless enrich=true finds it by ISBN"
+// @Param        description formData string false "Book description"
 // @Param        authorId formData  int    true "Author ID"
-// @Param        image    formData  file   false "Book Image"
+// @Param        image    formData  file   false "Book cover, JPEG, PNG or WebP (metadata is stripped, thumbnail/medium/large variants are generated)"
+// @Param        seriesId formData  int    false "Series ID"
+// @Param        seriesPosition formData number false "Position in the series (e.g. 2.5)"
+// @Param        isbn     formData  string false "ISBN-10 or ISBN-13, stored as ISBN-13"
+// @Param        publisher formData string false "Publisher"
+// @Param        language formData  string false "Language code (e.g. en, vi)"
+// @Param        format   formData  string false "Format" Enums(hardcover, paperback, ebook, audiobook, other)
+// @Param        publicationYear formData int false "Publication year"
+// @Param        enrich   query     bool   false "Fill empty fields (title, description, publisher, language, year, cover) from an ISBN lookup, title may then be omitted"
 // @Success      201 {object} handlers.BookResponse
 // @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Failure      415 {object} map[string]string
 // @Failure      500 {object} map[string]string
+// @Failure      502 {object} map[string]string
 // @Router       /books [post]
 // @Security BearerAuth
 func (h *BookHandler) CreateBook(c *gin.Context) {
@@ -147,13 +314,84 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 		return
 	}
 
-	createdBook, err := h.service.CreateBook(bookCreateRequest)
+	enrich, err := strconv.ParseBool(c.DefaultQuery("enrich", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "enrich must be true or false"})
+		return
+	}
+
+	createdBook, httpStatus, err := h.service.CreateBook(bookCreateRequest, enrich)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapBookResponse(createdBook))
+}
+
+type BookLookupResponse struct {
+	ISBN            string   `json:"isbn"`
+	Title           string   `json:"title"`
+	Authors         []string `json:"authors"`
+	MatchedAuthorID *uint    `json:"matched_author_id"` // existing author named like the first author
+	Publisher       string   `json:"publisher"`
+	PublishedDate   string   `json:"published_date"`
+	PublicationYear *int     `json:"publication_year"`
+	Description     string   `json:"description"`
+	CoverURL        string   `json:"cover_url"`
+	Language        string   `json:"language"`
+	Sources         []string `json:"sources"`
+}
+
+func mapBookLookupResponse(result *services.BookLookupResult) BookLookupResponse {
+	authors := result.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	return BookLookupResponse{
+		ISBN:            result.ISBN,
+		Title:           result.Title,
+		Authors:         authors,
+		MatchedAuthorID: result.MatchedAuthorID,
+		Publisher:       result.Publisher,
+		PublishedDate:   result.PublishedDate,
+		PublicationYear: result.PublicationYear,
+		Description:     result.Description,
+		CoverURL:        result.CoverURL,
+		Language:        result.Language,
+		Sources:         result.Sources,
+	}
+}
+
+// POST /books/lookup
+// LookupBook godoc
+// @Summary      Preview book metadata by ISBN
+// @Description  Look an ISBN up in the configured external sources (Open Library, Google Books), nothing is saved. Earlier sources win, later ones only fill the gaps
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        body  body      services.BookLookupRequest  true  "ISBN-10 or ISBN-13"
+// @Success      200   {object}  BookLookupResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      502   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /books/lookup [post]
+// @Security BearerAuth
+func (h *BookHandler) LookupBook(c *gin.Context) {
+	var req services.BookLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.LookupMetadata(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, mapBookResponse(createdBook))
+	c.JSON(httpStatus, mapBookLookupResponse(result))
 }
 
 // PATCH /books/:id
@@ -192,13 +430,15 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 // DELETE /books/:id
 // DeleteBook godoc
 // @Summary      Delete a book
-// @Description  Delete a book by its ID (soft delete if GORM is configured with gorm.Model)
+// @Description  Delete a book by its ID (soft delete if GORM is configured with gorm.Model).
+// @Description  A book with copies on loan cannot be deleted, its open holds are cancelled and its attached files are removed from storage
 // @Tags         books
 // @Produce      json
 // @Param        id   path      string  true  "Book ID"
 // @Success      204  "No Content"
 // @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /books/{id} [delete]
 // @Security BearerAuth
@@ -208,7 +448,65 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
 	}
 
 	c.JSON(httpStatus, nil)
 }
+
+// PUT /books/:id/image
+// ReplaceBookImage godoc
+// @Summary      Replace a book cover
+// @Description  Upload a new cover for a book, the previous cover is deleted from storage unless another book uses it
+// @Tags         books
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    path      string true  "Book ID"
+// @Param        image formData  file   true  "Book cover, JPEG, PNG or WebP (metadata is stripped, thumbnail/medium/large variants are generated)"
+// @Success      200 {object} handlers.BookResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      413 {object} map[string]string
+// @Failure      415 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Failure      502 {object} map[string]string
+// @Router       /books/{id}/image [put]
+// @Security BearerAuth
+func (h *BookHandler) ReplaceBookImage(c *gin.Context) {
+	var req services.BookImageRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedBook, httpStatus, err := h.service.ReplaceBookImage(c.Param("id"), req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapBookResponse(updatedBook))
+}
+
+// DELETE /books/:id/image
+// DeleteBookImage godoc
+// @Summary      Remove a book cover
+// @Description  Clear the cover of a book and delete it from storage unless another book uses it
+// @Tags         books
+// @Produce      json
+// @Param        id   path      string  true  "Book ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/image [delete]
+// @Security BearerAuth
+func (h *BookHandler) DeleteBookImage(c *gin.Context) {
+	httpStatus, err := h.service.DeleteBookImage(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(httpStatus)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CirculationPolicyHandler struct {
+	service services.ICirculationPolicyService
+}
+
+func NewCirculationPolicyHandler(service services.ICirculationPolicyService) *CirculationPolicyHandler {
+	return &CirculationPolicyHandler{service: service}
+}
+
+type CirculationPolicyResponse struct {
+	ID                 uint   `json:"id"`
+	Name               string `json:"name"`
+	Role               string `json:"role"`            // empty matches every role
+	PatronCategory     string `json:"patron_category"` // empty matches every category
+	ItemType           string `json:"item_type"`       // empty matches every format
+	LoanPeriodDays     int    `json:"loan_period_days"`
+	MaxRenewals        int    `json:"max_renewals"`
+	MaxConcurrentLoans *int   `json:"max_concurrent_loans"` // null means no limit
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+type PatronCategoryResponse struct {
+	ID             uint   `json:"id"`
+	Username       string `json:"username"`
+	Role           string `json:"role"`
+	PatronCategory string `json:"patron_category"`
+}
+
+func mapCirculationPolicyResponse(policy *models.CirculationPolicy) CirculationPolicyResponse {
+	return CirculationPolicyResponse{
+		ID:                 policy.ID,
+		Name:               policy.Name,
+		Role:               string(policy.Role),
+		PatronCategory:     policy.PatronCategory,
+		ItemType:           string(policy.ItemType),
+		LoanPeriodDays:     policy.LoanPeriodDays,
+		MaxRenewals:        policy.MaxRenewals,
+		MaxConcurrentLoans: policy.MaxConcurrentLoans,
+		CreatedAt:          policy.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:          policy.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// GET /circulation-policies
+// GetAllPolicies godoc
+// @Summary      List circulation policies
+// @Description  List the lending rules. The policy matching the most criteria of a loan applies, the configured defaults apply when none matches
+// @Tags         circulation
+// @Produce      json
+// @Success      200  {array}   handlers.CirculationPolicyResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /circulation-policies [get]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) GetAllPolicies(c *gin.Context) {
+	policies, httpStatus, err := h.service.GetAllPolicies()
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]CirculationPolicyResponse, len(policies))
+	for i := range policies {
+		resp[i] = mapCirculationPolicyResponse(&policies[i])
+	}
+	c.JSON(httpStatus, resp)
+}
+
+// GET /circulation-policies/:id
+// GetPolicyByID godoc
+// @Summary      Get a circulation policy
+// @Tags         circulation
+// @Produce      json
+// @Param        id   path      string  true  "Policy ID"
+// @Success      200  {object}  handlers.CirculationPolicyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /circulation-policies/{id} [get]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) GetPolicyByID(c *gin.Context) {
+	policy, httpStatus, err := h.service.GetPolicyByID(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCirculationPolicyResponse(policy))
+}
+
+// POST /circulation-policies
+// CreatePolicy godoc
+// @Summary      Create a circulation policy
+// @Description  Create lending rules for a role, patron category and/or item type, only one policy per combination
+// @Tags         circulation
+// @Accept       json
+// @Produce      json
+// @Param        policy  body  services.CirculationPolicyCreateRequest  true  "Policy data"
+// @Success      201  {object}  handlers.CirculationPolicyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /circulation-policies [post]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) CreatePolicy(c *gin.Context) {
+	var req services.CirculationPolicyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, httpStatus, err := h.service.CreatePolicy(req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCirculationPolicyResponse(policy))
+}
+
+// PATCH /circulation-policies/:id
+// UpdatePolicy godoc
+// @Summary      Update a circulation policy
+// @Description  Partially update a policy, send maxConcurrentLoans -1 to remove the limit. Open loans keep their due date until renewed
+// @Tags         circulation
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                                  true  "Policy ID"
+// @Param        policy  body  services.CirculationPolicyUpdateRequest  true  "Fields to update"
+// @Success      200  {object}  handlers.CirculationPolicyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /circulation-policies/{id} [patch]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) UpdatePolicy(c *gin.Context) {
+	var req services.CirculationPolicyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, httpStatus, err := h.service.UpdatePolicy(c.Param("id"), req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCirculationPolicyResponse(policy))
+}
+
+// DELETE /circulation-policies/:id
+// DeletePolicy godoc
+// @Summary      Delete a circulation policy
+// @Tags         circulation
+// @Produce      json
+// @Param        id   path  string  true  "Policy ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /circulation-policies/{id} [delete]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) DeletePolicy(c *gin.Context) {
+	httpStatus, err := h.service.DeletePolicy(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(httpStatus)
+}
+
+// PUT /users/:id/patron-category
+// SetPatronCategory godoc
+// @Summary      Set the patron category of a user
+// @Description  Patron categories (e.g. student, staff) select circulation policies, an empty category clears it
+// @Tags         circulation
+// @Accept       json
+// @Produce      json
+// @Param        id        path  string                          true  "User ID"
+// @Param        category  body  services.PatronCategoryRequest  true  "Patron category"
+// @Success      200  {object}  handlers.PatronCategoryResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /users/{id}/patron-category [put]
+// @Security BearerAuth
+func (h *CirculationPolicyHandler) SetPatronCategory(c *gin.Context) {
+	var req services.PatronCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, httpStatus, err := h.service.SetPatronCategory(c.Param("id"), req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, PatronCategoryResponse{
+		ID:             user.ID,
+		Username:       user.Username,
+		Role:           string(user.Role),
+		PatronCategory: user.PatronCategory,
+	})
+}
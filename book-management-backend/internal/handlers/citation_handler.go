@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"book-management/internal/services"
+	"book-management/pkg/citation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CitationHandler struct {
+	service services.ICitationService
+}
+
+func NewCitationHandler(service services.ICitationService) *CitationHandler {
+	return &CitationHandler{service: service}
+}
+
+type CitationResponse struct {
+	BookID  uint             `json:"book_id"`
+	Key     string           `json:"key"` // BibTeX citation key
+	APA     string           `json:"apa"`
+	MLA     string           `json:"mla"`
+	Chicago string           `json:"chicago"`
+	BibTeX  string           `json:"bibtex"`
+	RIS     string           `json:"ris"`
+	CSL     citation.CSLItem `json:"csl"`
+}
+
+func mapCitationResponse(w citation.Work) CitationResponse {
+	return CitationResponse{
+		BookID:  w.ID,
+		Key:     citation.Key(w),
+		APA:     citation.APA(w),
+		MLA:     citation.MLA(w),
+		Chicago: citation.Chicago(w),
+		BibTeX:  citation.BibTeX(w),
+		RIS:     citation.RIS(w),
+		CSL:     citation.CSL(w),
+	}
+}
+
+// writeCitations sends the citations as a document in format, or as JSON when no format is given
+func (h *CitationHandler) writeCitations(c *gin.Context, idsStr string, single bool) {
+	format, err := citation.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	works, httpStatus, err := h.service.GetCitationWorks(idsStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "" {
+		if single {
+			c.JSON(httpStatus, mapCitationResponse(works[0]))
+			return
+		}
+		resp := make([]CitationResponse, len(works))
+		for i, w := range works {
+			resp[i] = mapCitationResponse(w)
+		}
+		c.JSON(httpStatus, resp)
+		return
+	}
+
+	body, err := citation.Render(format, works)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(httpStatus, format.ContentType(), body)
+}
+
+// GET /books/:id/citation?format=bibtex
+// GetBookCitation godoc
+// @Summary      Cite a book
+// @Description  Citation of a book as BibTeX, RIS, CSL-JSON or a rendered APA 7 / MLA 9 / Chicago reference (plain text). Without format, all of them are returned as JSON
+// @Tags         citations
+// @Produce      json
+// @Produce      application/x-bibtex
+// @Produce      application/x-research-info-systems
+// @Produce      application/vnd.citationstyles.csl+json
+// @Produce      plain
+// @Param        id      path      string  true   "Book ID"
+// @Param        format  query     string  false  "Citation format"  Enums(bibtex, ris, csl-json, apa, mla, chicago)
+// @Success      200     {object}  CitationResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /books/{id}/citation [get]
+// @Security BearerAuth
+func (h *CitationHandler) GetBookCitation(c *gin.Context) {
+	h.writeCitations(c, c.Param("id"), true)
+}
+
+// GET /books/citations?ids=1,2,3&format=bibtex
+// GetBookCitations godoc
+// @Summary      Cite several books
+// @Description  Citations of up to 100 books in the given order, as one BibTeX/RIS/CSL-JSON document or one rendered reference per line. Without format, a JSON array of all formats is returned
+// @Tags         citations
+// @Produce      json
+// @Produce      application/x-bibtex
+// @Produce      application/x-research-info-systems
+// @Produce      application/vnd.citationstyles.csl+json
+// @Produce      plain
+// @Param        ids     query     string  true   "Comma separated book IDs, e.g. 1,4,7"
+// @Param        format  query     string  false  "Citation format"  Enums(bibtex, ris, csl-json, apa, mla, chicago)
+// @Success      200     {array}   CitationResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /books/citations [get]
+// @Security BearerAuth
+func (h *CitationHandler) GetBookCitations(c *gin.Context) {
+	h.writeCitations(c, c.Query("ids"), false)
+}
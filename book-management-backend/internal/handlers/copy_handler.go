@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CopyHandler struct {
+	service services.ICopyService
+}
+
+func NewCopyHandler(service services.ICopyService) *CopyHandler {
+	return &CopyHandler{service: service}
+}
+
+type CopyResponse struct {
+	ID               uint     `json:"id"`
+	BookID           uint     `json:"book_id"`
+	Barcode          string   `json:"barcode"`
+	ShelfLocation    string   `json:"shelf_location"`
+	Condition        string   `json:"condition"` // new, good, fair or poor
+	Status           string   `json:"status"`    // available, on_loan, on_hold, lost, damaged or in_repair
+	AcquiredAt       string   `json:"acquired_at,omitempty"`
+	AcquisitionPrice *float64 `json:"acquisition_price"`
+	Notes            string   `json:"notes"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
+
+func mapCopyResponse(bookCopy *models.Copy) CopyResponse {
+	resp := CopyResponse{
+		ID:               bookCopy.ID,
+		BookID:           bookCopy.BookID,
+		Barcode:          bookCopy.Barcode,
+		ShelfLocation:    bookCopy.ShelfLocation,
+		Condition:        string(bookCopy.Condition),
+		Status:           string(bookCopy.Status),
+		AcquisitionPrice: bookCopy.AcquisitionPrice,
+		Notes:            bookCopy.Notes,
+		CreatedAt:        bookCopy.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        bookCopy.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if bookCopy.AcquiredAt != nil {
+		resp.AcquiredAt = bookCopy.AcquiredAt.Format("02-01-2006")
+	}
+	return resp
+}
+
+// GET /books/:id/copies
+// GetCopies godoc
+// @Summary      List the copies of a book
+// @Description  List the physical copies of a book ordered by barcode
+// @Tags         copies
+// @Produce      json
+// @Param        id   path      string  true  "Book ID"
+// @Success      200  {array}   handlers.CopyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/copies [get]
+// @Security BearerAuth
+func (h *CopyHandler) GetCopies(c *gin.Context) {
+	copies, httpStatus, err := h.service.GetCopies(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]CopyResponse, len(copies))
+	for i := range copies {
+		resp[i] = mapCopyResponse(&copies[i])
+	}
+	c.JSON(httpStatus, resp)
+}
+
+// GET /books/:id/copies/:copyId
+// GetCopy godoc
+// @Summary      Get a copy of a book
+// @Tags         copies
+// @Produce      json
+// @Param        id      path      string  true  "Book ID"
+// @Param        copyId  path      string  true  "Copy ID"
+// @Success      200  {object}  handlers.CopyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/copies/{copyId} [get]
+// @Security BearerAuth
+func (h *CopyHandler) GetCopy(c *gin.Context) {
+	bookCopy, httpStatus, err := h.service.GetCopy(c.Param("id"), c.Param("copyId"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCopyResponse(bookCopy))
+}
+
+// POST /books/:id/copies
+// CreateCopy godoc
+// @Summary      Add a copy of a book
+// @Description  Register a physical copy, the barcode must be unique across all books
+// @Tags         copies
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                      true  "Book ID"
+// @Param        copy  body  services.CopyCreateRequest  true  "Copy data"
+// @Success      201  {object}  handlers.CopyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/copies [post]
+// @Security BearerAuth
+func (h *CopyHandler) CreateCopy(c *gin.Context) {
+	var req services.CopyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookCopy, httpStatus, err := h.service.CreateCopy(c.Param("id"), req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCopyResponse(bookCopy))
+}
+
+// PATCH /books/:id/copies/:copyId
+// UpdateCopy godoc
+// @Summary      Update a copy of a book
+// @Description  Partially update a copy, e.g. its shelf location, condition or status
+// @Tags         copies
+// @Accept       json
+// @Produce      json
+// @Param        id      path  string                      true  "Book ID"
+// @Param        copyId  path  string                      true  "Copy ID"
+// @Param        copy    body  services.CopyUpdateRequest  true  "Fields to update"
+// @Success      200  {object}  handlers.CopyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/copies/{copyId} [patch]
+// @Security BearerAuth
+func (h *CopyHandler) UpdateCopy(c *gin.Context) {
+	var req services.CopyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookCopy, httpStatus, err := h.service.UpdateCopy(c.Param("id"), c.Param("copyId"), req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapCopyResponse(bookCopy))
+}
+
+// DELETE /books/:id/copies/:copyId
+// DeleteCopy godoc
+// @Summary      Delete a copy of a book
+// @Description  Withdraw a copy from the collection (soft delete), its barcode can then be reused
+// @Tags         copies
+// @Produce      json
+// @Param        id      path  string  true  "Book ID"
+// @Param        copyId  path  string  true  "Copy ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/copies/{copyId} [delete]
+// @Security BearerAuth
+func (h *CopyHandler) DeleteCopy(c *gin.Context) {
+	httpStatus, err := h.service.DeleteCopy(c.Param("id"), c.Param("copyId"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(httpStatus)
+}
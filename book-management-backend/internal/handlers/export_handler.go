@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"book-management/internal/services"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	service services.IExportService
+}
+
+func NewExportHandler(service services.IExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// writeExport streams a validated export as a file download.
+// Once streaming has started the status is already sent, so a failure can only be logged.
+func writeExport(c *gin.Context, exp *services.Export) {
+	c.Header("Content-Type", exp.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exp.FileName))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := exp.Write(c.Writer); err != nil {
+		log.Printf("❌ Export %s failed: %v", exp.FileName, err)
+	}
+}
+
+// GET /export/books?format=csv
+// ExportBooks godoc
+// @Summary      Export books
+// @Description  Download all books matching the same filters and sort as GET /books (pagination does not apply) with author, genre and tag names. The file is streamed as it is read. In CSV, genres and tags are separated by |. marc21 (binary) and marcxml give one MARC 21 bibliographic record per book
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/marc
+// @Produce      application/marcxml+xml
+// @Param        format        query  string  false  "File format"  Enums(csv, jsonl, xlsx, marc21, marcxml)  default(csv)
+// @Param        title         query  string  false  "Title contains (case-insensitive)"
+// @Param        author_id     query  int     false  "Author ID"
+// @Param        genre_ids     query  string  false  "Comma separated genre IDs, e.g. 1,4,7"
+// @Param        genre_match   query  string  false  "Match any or all of genre_ids"  Enums(any, all)  default(any)
+// @Param        created_from  query  string  false  "Created on/after (YYYY-MM-DD or RFC3339)"
+// @Param        created_to    query  string  false  "Created on/before (YYYY-MM-DD) or before (RFC3339)"
+// @Param        language      query  string  false  "Language code, e.g. en"
+// @Param        publisher     query  string  false  "Publisher contains (case-insensitive)"
+// @Param        tags          query  string  false  "Comma separated tag names, books must have all of them"
+// @Param        sort          query  string  false  "Sort fields, prefix with - for descending, e.g. title,-created_at"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Router       /export/books [get]
+// @Security BearerAuth
+func (h *ExportHandler) ExportBooks(c *gin.Context) {
+	var query services.BookListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exp, httpStatus, err := h.service.ExportBooks(c.Query("format"), query)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(c, exp)
+}
+
+// GET /export/books/:id?format=marcxml
+// ExportBook godoc
+// @Summary      Export a single book
+// @Description  Download one book in any export format, e.g. as a MARC 21 record
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce      application/marc
+// @Produce      application/marcxml+xml
+// @Param        id      path   string  true   "Book ID"
+// @Param        format  query  string  false  "File format"  Enums(csv, jsonl, xlsx, marc21, marcxml)  default(csv)
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /export/books/{id} [get]
+// @Security BearerAuth
+func (h *ExportHandler) ExportBook(c *gin.Context) {
+	exp, httpStatus, err := h.service.ExportBook(c.Param("id"), c.Query("format"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(c, exp)
+}
+
+// GET /export/authors?format=csv
+// ExportAuthors godoc
+// @Summary      Export authors
+// @Description  Download all authors with their number of books
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query  string  false  "File format"  Enums(csv, jsonl, xlsx)  default(csv)
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Router       /export/authors [get]
+// @Security BearerAuth
+func (h *ExportHandler) ExportAuthors(c *gin.Context) {
+	exp, httpStatus, err := h.service.ExportAuthors(c.Query("format"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(c, exp)
+}
+
+// GET /export/genres?format=csv
+// ExportGenres godoc
+// @Summary      Export genres
+// @Description  Download all genres with their number of books
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query  string  false  "File format"  Enums(csv, jsonl, xlsx)  default(csv)
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Router       /export/genres [get]
+// @Security BearerAuth
+func (h *ExportHandler) ExportGenres(c *gin.Context) {
+	exp, httpStatus, err := h.service.ExportGenres(c.Query("format"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeExport(c, exp)
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"book-management/internal/models"
 	"book-management/internal/services"
+	"book-management/pkg/fieldset"
+	"book-management/pkg/pagination"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -22,13 +24,28 @@ type BookResponseForGenre struct {
 }
 
 type GenreResponse struct {
-	ID        uint   `json:"id"`
-	Name      string `json:"name"`
-	Books     []BookResponseForGenre
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        uint                   `json:"id"`
+	Name      string                 `json:"name"`
+	Slug      string                 `json:"slug"`
+	Books     []BookResponseForGenre `json:"Books"` // capitalized since the first version of the API
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt string                 `json:"updated_at"`
 }
 
+// GenreListItemResponse is a genre of GET /genres, books is an opt-in relation (include=books)
+// named in lowercase like the relations of the other lists
+type GenreListItemResponse struct {
+	ID        uint                   `json:"id"`
+	Name      string                 `json:"name"`
+	Slug      string                 `json:"slug"`
+	Books     []BookResponseForGenre `json:"books"`
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt string                 `json:"updated_at"`
+}
+
+// genreRelations are the relations a genre list can embed with include=
+var genreRelations = []string{"books"}
+
 func mapGenreResponse(genre *models.Genre) GenreResponse {
 	books := make([]BookResponseForGenre, len(genre.Books))
 	for i, b := range genre.Books {
@@ -41,12 +58,29 @@ func mapGenreResponse(genre *models.Genre) GenreResponse {
 	return GenreResponse{
 		ID:        genre.ID,
 		Name:      genre.Name,
+		Slug:      genre.Slug,
 		Books:     books,
 		CreatedAt: genre.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt: genre.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
 
+func mapGenreListItemResponse(genre *models.Genre) GenreListItemResponse {
+	return GenreListItemResponse(mapGenreResponse(genre))
+}
+
+// Helper: on 409 Conflict, include the existing genre so clients can reuse it
+func respondGenreError(c *gin.Context, httpStatus int, existing *models.Genre, err error) {
+	if httpStatus == http.StatusConflict && existing != nil {
+		c.JSON(httpStatus, gin.H{
+			"error": err.Error(),
+			"genre": mapGenreResponse(existing),
+		})
+		return
+	}
+	c.JSON(httpStatus, gin.H{"error": err.Error()})
+}
+
 // GET /genres/:id
 // GetGenreByID godoc
 // @Summary      Get genre details by ID
@@ -70,37 +104,78 @@ func (h *GenreHandler) GetGenreByID(c *gin.Context) {
 	c.JSON(httpStatus, mapGenreResponse(genre))
 }
 
+// GET /genres/slug/:slug
+// GetGenreBySlug godoc
+// @Summary      Get genre details by slug
+// @Description  Get a single genre by its URL slug (e.g. "science-fiction")
+// @Tags         genres
+// @Produce      json
+// @Param        slug  path      string  true  "Genre slug"
+// @Success      200   {object}  GenreResponse
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /genres/slug/{slug} [get]
+// @Security BearerAuth
+func (h *GenreHandler) GetGenreBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	genre, httpStatus, err := h.service.GetGenreBySlug(slug)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, mapGenreResponse(genre))
+}
+
 // GET /genres?limit=10&offset=0
 // GetAllGenres godoc
 // @Summary      Get all genres with pagination
-// @Description  Retrieve a paginated list of genres
+// @Description  Retrieve a paginated list of genres ordered by ID as {data, page}, books are only embedded with include=books. Pages can be walked with offset or with page.next_cursor (next/prev links are also sent in the Link header)
 // @Tags         genres
 // @Produce      json
-// @Param        limit   query     string  false  "Limit number of genres per page"  default(10)
-// @Param        offset  query     string  false  "Number of genres to skip"         default(0)
+// @Param        limit          query     int     false  "Number of genres per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of genres to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all genres"
+// @Param        fields         query     string  false  "Comma separated fields to return, e.g. name,slug (id is always returned)"
+// @Param        include        query     string  false  "Comma separated relations to embed: books"
 // @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
 // @Failure      500     {object}  map[string]string
 // @Router       /genres [get]
 // @Security BearerAuth
 func (h *GenreHandler) GetAllGenres(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	selection, err := fieldset.Parse(c.Query("fields"), c.Query("include"), fieldset.JSONFields(GenreListItemResponse{}), genreRelations)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	genres, httpStatus, err := h.service.GetAllGenres(limitStr, offsetStr)
+	result, httpStatus, err := h.service.GetAllGenres(params, selection.Include())
 	if err != nil {
 		c.JSON(httpStatus, gin.H{"error": err.Error()})
 		return
 	}
 
-	resp := make([]GenreResponse, len(*genres))
-	for i := range *genres {
-		resp[i] = mapGenreResponse(&(*genres)[i])
+	resp := make([]map[string]any, len(result.Genres))
+	for i := range result.Genres {
+		item, err := selection.Apply(mapGenreListItemResponse(&result.Genres[i]))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		resp[i] = item
 	}
 
 	c.JSON(httpStatus, gin.H{
-		"limit":  limitStr,
-		"offset": offsetStr,
-		"data":   resp,
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
 	})
 }
 
@@ -114,6 +189,7 @@ func (h *GenreHandler) GetAllGenres(c *gin.Context) {
 // @Param        genre  body      services.GenreCreateRequest  true  "Genre data"
 // @Success      201 {object} GenreResponse
 // @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]interface{} "Genre name already exists, existing genre is returned"
 // @Failure      500 {object} map[string]string
 // @Router       /genres [post]
 // @Security BearerAuth
@@ -124,13 +200,13 @@ func (h *GenreHandler) CreateGenre(c *gin.Context) {
 		return
 	}
 
-	createdGenre, err := h.service.CreateGenre(req)
+	createdGenre, httpStatus, err := h.service.CreateGenre(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondGenreError(c, httpStatus, createdGenre, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, mapGenreResponse(createdGenre))
+	c.JSON(httpStatus, mapGenreResponse(createdGenre))
 }
 
 // PATCH /genres/:id
@@ -145,6 +221,7 @@ func (h *GenreHandler) CreateGenre(c *gin.Context) {
 // @Success      200 {object} GenreResponse
 // @Failure      400 {object} map[string]string
 // @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]interface{} "Genre name already exists, existing genre is returned"
 // @Failure      500 {object} map[string]string
 // @Router       /genres/{id} [patch]
 // @Security BearerAuth
@@ -159,7 +236,7 @@ func (h *GenreHandler) UpdateGenre(c *gin.Context) {
 
 	updatedGenre, httpStatus, err := h.service.UpdateGenre(idStr, req)
 	if err != nil {
-		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		respondGenreError(c, httpStatus, updatedGenre, err)
 		return
 	}
 
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/pagination"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	service services.IHoldService
+}
+
+func NewHoldHandler(service services.IHoldService) *HoldHandler {
+	return &HoldHandler{service: service}
+}
+
+type CopyResponseForHold struct {
+	ID      uint   `json:"id"`
+	Barcode string `json:"barcode"`
+}
+
+type BookResponseForHold struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type UserResponseForHold struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+type HoldResponse struct {
+	ID            uint                 `json:"id"`
+	Book          BookResponseForHold  `json:"book"`
+	User          UserResponseForHold  `json:"user"`
+	Status        string               `json:"status"`                   // waiting, ready, fulfilled, cancelled or expired
+	QueuePosition *int                 `json:"queue_position,omitempty"` // 1 is next in line, waiting holds only
+	Copy          *CopyResponseForHold `json:"copy,omitempty"`           // copy set aside for pickup
+	PlacedAt      string               `json:"placed_at"`
+	ReadyAt       string               `json:"ready_at,omitempty"`
+	ExpiresAt     string               `json:"expires_at,omitempty"` // pickup deadline
+	ClosedAt      string               `json:"closed_at,omitempty"`
+}
+
+func mapHoldResponse(hold *models.Hold) HoldResponse {
+	resp := HoldResponse{
+		ID:            hold.ID,
+		Book:          BookResponseForHold{ID: hold.BookID},
+		User:          UserResponseForHold{ID: hold.UserID},
+		Status:        string(hold.Status),
+		QueuePosition: hold.QueuePosition,
+		PlacedAt:      hold.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	// Relations are missing when the book or user was deleted since
+	if hold.Book != nil {
+		resp.Book.Title = hold.Book.Title
+	}
+	if hold.User != nil {
+		resp.User.Username = hold.User.Username
+	}
+	if hold.CopyID != nil {
+		resp.Copy = &CopyResponseForHold{ID: *hold.CopyID}
+		if hold.Copy != nil {
+			resp.Copy.Barcode = hold.Copy.Barcode
+		}
+	}
+	if hold.ReadyAt != nil {
+		resp.ReadyAt = hold.ReadyAt.Format("02-01-2006 15:04:05")
+	}
+	if hold.ExpiresAt != nil {
+		resp.ExpiresAt = hold.ExpiresAt.Format("02-01-2006 15:04:05")
+	}
+	if hold.ClosedAt != nil {
+		resp.ClosedAt = hold.ClosedAt.Format("02-01-2006 15:04:05")
+	}
+	return resp
+}
+
+// POST /books/:id/holds
+// PlaceHold godoc
+// @Summary      Place a hold on a book
+// @Description  Queue the caller or, for admins, userId for a book whose copies are all out. Holds are served first come first served:
+// @Description  a returned copy is set aside for the next hold, which becomes ready for pickup until its deadline, then passes to the next one.
+// @Description  A refusal answers 409 with a reason: copy_available, already_borrowed or hold_exists
+// @Tags         holds
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                      true   "Book ID"
+// @Param        hold  body      services.HoldCreateRequest  false  "Patron (admin only)"
+// @Success      201   {object}  handlers.HoldResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  handlers.CirculationRefusalResponse
+// @Failure      500   {object}  map[string]string
+// @Router       /books/{id}/holds [post]
+// @Security BearerAuth
+func (h *HoldHandler) PlaceHold(c *gin.Context) {
+	var req services.HoldCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, httpStatus, err := h.service.PlaceHold(c.Param("id"), req, c.GetUint("userID"), isAdmin(c))
+	if err != nil {
+		writeCirculationError(c, httpStatus, err)
+		return
+	}
+
+	c.JSON(httpStatus, mapHoldResponse(hold))
+}
+
+// GET /books/:id/holds
+// GetBookHolds godoc
+// @Summary      List the holds on a book
+// @Description  List the waiting and ready holds on a book in queue order
+// @Tags         holds
+// @Produce      json
+// @Param        id   path      string  true  "Book ID"
+// @Success      200  {array}   handlers.HoldResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /books/{id}/holds [get]
+// @Security BearerAuth
+func (h *HoldHandler) GetBookHolds(c *gin.Context) {
+	holds, httpStatus, err := h.service.GetBookHolds(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]HoldResponse, len(holds))
+	for i := range holds {
+		resp[i] = mapHoldResponse(&holds[i])
+	}
+	c.JSON(httpStatus, resp)
+}
+
+// POST /holds/:id/cancel
+// CancelHold godoc
+// @Summary      Cancel a hold
+// @Description  Leave the queue, a copy set aside for the hold goes to the next patron. Users can only cancel their own holds.
+// @Description  A refusal answers 409 with the reason hold_closed
+// @Tags         holds
+// @Produce      json
+// @Param        id   path      string  true  "Hold ID"
+// @Success      200  {object}  handlers.HoldResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  handlers.CirculationRefusalResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /holds/{id}/cancel [post]
+// @Security BearerAuth
+func (h *HoldHandler) CancelHold(c *gin.Context) {
+	hold, httpStatus, err := h.service.CancelHold(c.Param("id"), c.GetUint("userID"), isAdmin(c))
+	if err != nil {
+		writeCirculationError(c, httpStatus, err)
+		return
+	}
+
+	c.JSON(httpStatus, mapHoldResponse(hold))
+}
+
+// GET /me/holds
+// GetMyHolds godoc
+// @Summary      List my holds
+// @Description  List the holds of the caller ordered by ID as {data, page}, waiting holds carry their queue_position
+// @Tags         holds
+// @Produce      json
+// @Param        status         query     string  false  "Only open (waiting or ready) holds, or holds with the given status"  Enums(open, waiting, ready, fulfilled, cancelled, expired)
+// @Param        limit          query     int     false  "Number of holds per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of holds to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all matching holds"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /me/holds [get]
+// @Security BearerAuth
+func (h *HoldHandler) GetMyHolds(c *gin.Context) {
+	var query services.HoldListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.GetMyHolds(query, params, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]HoldResponse, len(result.Holds))
+	for i := range result.Holds {
+		resp[i] = mapHoldResponse(&result.Holds[i])
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
+	})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportHandler struct {
+	service services.IImportService
+}
+
+func NewImportHandler(service services.IImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+type ImportFieldUsageResponse struct {
+	Field  string `json:"field"`  // e.g. "245$a"
+	Target string `json:"target"` // empty when the field was ignored
+	Count  int    `json:"count"`
+}
+
+type ImportJobResponse struct {
+	ID             uint   `json:"id"`
+	Kind           string `json:"kind"`
+	Format         string `json:"format"`
+	FileName       string `json:"file_name"`
+	Status         string `json:"status"`
+	TotalRows      int    `json:"total_rows"`
+	ProcessedRows  int    `json:"processed_rows"`
+	CreatedRows    int    `json:"created_rows"`
+	FailedRows     int    `json:"failed_rows"`
+	Progress       int    `json:"progress"` // percentage of processed rows
+	Error          string `json:"error,omitempty"`
+	ErrorReportURL string `json:"error_report_url,omitempty"`
+	StartedAt      string `json:"started_at,omitempty"`
+	FinishedAt     string `json:"finished_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+
+	Mapping []ImportFieldUsageResponse `json:"mapping,omitempty"` // MARC imports: fields found and what they were imported as
+}
+
+func mapImportJobResponse(job *models.ImportJob) ImportJobResponse {
+	resp := ImportJobResponse{
+		ID:            job.ID,
+		Kind:          job.Kind,
+		Format:        job.Format,
+		FileName:      job.FileName,
+		Status:        string(job.Status),
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		CreatedRows:   job.CreatedRows,
+		FailedRows:    job.FailedRows,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if job.TotalRows > 0 {
+		resp.Progress = job.ProcessedRows * 100 / job.TotalRows
+	}
+	if job.FailedRows > 0 {
+		resp.ErrorReportURL = fmt.Sprintf("/api/import/jobs/%d/errors", job.ID)
+	}
+	for _, m := range job.Mapping {
+		resp.Mapping = append(resp.Mapping, ImportFieldUsageResponse{
+			Field:  m.Field,
+			Target: m.Target,
+			Count:  m.Count,
+		})
+	}
+	if job.StartedAt != nil {
+		resp.StartedAt = job.StartedAt.Format("02-01-2006 15:04:05")
+	}
+	if job.FinishedAt != nil {
+		resp.FinishedAt = job.FinishedAt.Format("02-01-2006 15:04:05")
+	}
+	return resp
+}
+
+// POST /import/books
+// ImportBooks godoc
+// @Summary      Import books from CSV
+// @Description  Upload a CSV file with a header row. Columns: title (required), author_name and/or author_email, genres (separated by | or ;), isbn, image_url, description. Missing authors and genres are created. Rows are imported in the background, poll the returned job for progress
+// @Tags         import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "CSV file (max 20 MB)"
+// @Success      202   {object}  ImportJobResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      413   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /import/books [post]
+// @Security BearerAuth
+func (h *ImportHandler) ImportBooks(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required in the [file] field"})
+		return
+	}
+
+	job, httpStatus, err := h.service.ImportBooks(file, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/import/jobs/%d", job.ID))
+	c.JSON(httpStatus, mapImportJobResponse(job))
+}
+
+// POST /import/marc
+// ImportMARC godoc
+// @Summary      Import books from MARC
+// @Description  Upload a MARC 21 file, binary (ISO 2709, UTF-8) or MARCXML, detected from the content. 245 gives the title, 100 (else 110/700/710) the author, 020 the ISBN, 650/655 the genres, 260/264 the publisher and year, 520 the description. Missing authors and genres are created. The job includes a mapping report of every field found and what it was imported as, the error report lists record numbers
+// @Tags         import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "MARC21 or MARCXML file (max 20 MB)"
+// @Success      202   {object}  ImportJobResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      413   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /import/marc [post]
+// @Security BearerAuth
+func (h *ImportHandler) ImportMARC(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a MARC file is required in the [file] field"})
+		return
+	}
+
+	job, httpStatus, err := h.service.ImportMARC(file, c.GetUint("userID"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/import/jobs/%d", job.ID))
+	c.JSON(httpStatus, mapImportJobResponse(job))
+}
+
+// GET /import/jobs/:id
+// GetImportJob godoc
+// @Summary      Get an import job
+// @Description  Get the status and progress of an import job, and the mapping report of MARC imports
+// @Tags         import
+// @Produce      json
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {object}  ImportJobResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /import/jobs/{id} [get]
+// @Security BearerAuth
+func (h *ImportHandler) GetImportJob(c *gin.Context) {
+	job, httpStatus, err := h.service.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, mapImportJobResponse(job))
+}
+
+// GET /import/jobs/:id/errors
+// DownloadImportErrors godoc
+// @Summary      Download the error report of an import job
+// @Description  CSV with the line number (record number for MARC) and error of every rejected row, followed by the source values of the row
+// @Tags         import
+// @Produce      text/csv
+// @Param        id   path      string  true  "Import job ID"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /import/jobs/{id}/errors [get]
+// @Security BearerAuth
+func (h *ImportHandler) DownloadImportErrors(c *gin.Context) {
+	job, rowErrors, httpStatus, err := h.service.GetErrorReport(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, job.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(append([]string{"line", "error"}, job.Header...))
+	for _, e := range rowErrors {
+		w.Write(append([]string{strconv.Itoa(e.Line), e.Message}, e.Values...))
+	}
+	w.Flush()
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/pagination"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoanHandler struct {
+	service services.ILoanService
+}
+
+func NewLoanHandler(service services.ILoanService) *LoanHandler {
+	return &LoanHandler{service: service}
+}
+
+type CopyResponseForLoan struct {
+	ID      uint   `json:"id"`
+	Barcode string `json:"barcode"`
+}
+
+type BookResponseForLoan struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type UserResponseForLoan struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+type LoanResponse struct {
+	ID           uint                `json:"id"`
+	Copy         CopyResponseForLoan `json:"copy"`
+	Book         BookResponseForLoan `json:"book"`
+	User         UserResponseForLoan `json:"user"`
+	CheckedOutAt string              `json:"checked_out_at"`
+	DueAt        string              `json:"due_at"`
+	ReturnedAt   string              `json:"returned_at,omitempty"`
+	Overdue      bool                `json:"overdue"` // still out past the due date
+	RenewalCount int                 `json:"renewal_count"`
+}
+
+// CirculationRefusalResponse is the error body of a checkout, renewal or hold refused by the circulation rules
+type CirculationRefusalResponse struct {
+	Error  string `json:"error"`
+	Reason string `json:"reason"`          // e.g. copy_unavailable, loan_limit_reached or hold_pending, see the services.Reason constants
+	Limit  *int   `json:"limit,omitempty"` // the limit that was hit, for the limit reasons
+}
+
+func mapLoanResponse(loan *models.Loan) LoanResponse {
+	resp := LoanResponse{
+		ID:           loan.ID,
+		Copy:         CopyResponseForLoan{ID: loan.CopyID},
+		Book:         BookResponseForLoan{ID: loan.BookID},
+		User:         UserResponseForLoan{ID: loan.UserID},
+		CheckedOutAt: loan.CheckedOutAt.Format("02-01-2006 15:04:05"),
+		DueAt:        loan.DueAt.Format("02-01-2006 15:04:05"),
+		Overdue:      loan.Overdue(time.Now()),
+		RenewalCount: loan.RenewalCount,
+	}
+	// Relations are missing when the copy, book or user was deleted since
+	if loan.Copy != nil {
+		resp.Copy.Barcode = loan.Copy.Barcode
+	}
+	if loan.Book != nil {
+		resp.Book.Title = loan.Book.Title
+	}
+	if loan.User != nil {
+		resp.User.Username = loan.User.Username
+	}
+	if loan.ReturnedAt != nil {
+		resp.ReturnedAt = loan.ReturnedAt.Format("02-01-2006 15:04:05")
+	}
+	return resp
+}
+
+// isAdmin tells whether the authenticated caller has the admin role
+func isAdmin(c *gin.Context) bool {
+	role, _ := c.Get("role")
+	return role == models.RoleAdmin
+}
+
+// writeCirculationError writes a service error, refusals also carry a machine-readable reason
+func writeCirculationError(c *gin.Context, httpStatus int, err error) {
+	var refusal *services.CirculationRefusal
+	if errors.As(err, &refusal) {
+		c.JSON(httpStatus, CirculationRefusalResponse{Error: refusal.Message, Reason: refusal.Reason, Limit: refusal.Limit})
+		return
+	}
+	c.JSON(httpStatus, gin.H{"error": err.Error()})
+}
+
+// POST /loans
+// Checkout godoc
+// @Summary      Check out a copy
+// @Description  Lend the copy with the given barcode, to the caller (self-checkout) or, for admins, to userId. The copy must be available, a copy is never lent twice.
+// @Description  The due date follows the circulation policy of the borrower and the book format. A copy set aside for a hold can only be checked out by its patron.
+// @Description  A refusal answers 409 with a reason: copy_unavailable, copy_on_hold, hold_busy (retry) or loan_limit_reached (with the limit)
+// @Tags         loans
+// @Accept       json
+// @Produce      json
+// @Param        loan  body      services.LoanCreateRequest  true  "Barcode and borrower"
+// @Success      201   {object}  handlers.LoanResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  handlers.CirculationRefusalResponse
+// @Failure      500   {object}  map[string]string
+// @Router       /loans [post]
+// @Security BearerAuth
+func (h *LoanHandler) Checkout(c *gin.Context) {
+	var req services.LoanCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loan, httpStatus, err := h.service.Checkout(req, c.GetUint("userID"), isAdmin(c))
+	if err != nil {
+		writeCirculationError(c, httpStatus, err)
+		return
+	}
+
+	c.JSON(httpStatus, mapLoanResponse(loan))
+}
+
+// POST /loans/:id/return
+// ReturnLoan godoc
+// @Summary      Return a loan
+// @Description  Close a loan and put the copy back on the shelf, or set it aside for the next hold on the book
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      string  true  "Loan ID"
+// @Success      200  {object}  handlers.LoanResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  handlers.CirculationRefusalResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /loans/{id}/return [post]
+// @Security BearerAuth
+func (h *LoanHandler) ReturnLoan(c *gin.Context) {
+	loan, httpStatus, err := h.service.ReturnLoan(c.Param("id"), c.GetUint("userID"))
+	if err != nil {
+		writeCirculationError(c, httpStatus, err)
+		return
+	}
+
+	c.JSON(httpStatus, mapLoanResponse(loan))
+}
+
+// POST /loans/:id/renew
+// RenewLoan godoc
+// @Summary      Renew a loan
+// @Description  Extend an open loan by the loan period of its circulation policy, counted from the due date (or from now when overdue). Users can only renew their own loans.
+// @Description  A refusal answers 409 with a reason: renewal_limit_reached (with the limit), hold_pending (others are waiting for the book) or loan_returned
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      string  true  "Loan ID"
+// @Success      200  {object}  handlers.LoanResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  handlers.CirculationRefusalResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /loans/{id}/renew [post]
+// @Security BearerAuth
+func (h *LoanHandler) RenewLoan(c *gin.Context) {
+	loan, httpStatus, err := h.service.RenewLoan(c.Param("id"), c.GetUint("userID"), isAdmin(c))
+	if err != nil {
+		writeCirculationError(c, httpStatus, err)
+		return
+	}
+
+	c.JSON(httpStatus, mapLoanResponse(loan))
+}
+
+// GET /loans/:id
+// GetLoanByID godoc
+// @Summary      Get a loan
+// @Description  Get a loan by ID, users can only see their own loans
+// @Tags         loans
+// @Produce      json
+// @Param        id   path      string  true  "Loan ID"
+// @Success      200  {object}  handlers.LoanResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /loans/{id} [get]
+// @Security BearerAuth
+func (h *LoanHandler) GetLoanByID(c *gin.Context) {
+	loan, httpStatus, err := h.service.GetLoanByID(c.Param("id"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	// Someone else's loan is reported as missing rather than forbidden
+	if !isAdmin(c) && loan.UserID != c.GetUint("userID") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "loan with ID [" + c.Param("id") + "] does not exist"})
+		return
+	}
+
+	c.JSON(httpStatus, mapLoanResponse(loan))
+}
+
+// GET /loans
+// GetAllLoans godoc
+// @Summary      List loans
+// @Description  List all loans ordered by ID as {data, page}, pages can be walked with offset or with page.next_cursor
+// @Tags         loans
+// @Produce      json
+// @Param        status         query     string  false  "Only active (not returned), overdue or returned loans"  Enums(active, overdue, returned)
+// @Param        user_id        query     int     false  "Borrower"
+// @Param        book_id        query     int     false  "Book"
+// @Param        copy_id        query     int     false  "Copy"
+// @Param        limit          query     int     false  "Number of loans per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of loans to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all matching loans"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /loans [get]
+// @Security BearerAuth
+func (h *LoanHandler) GetAllLoans(c *gin.Context) {
+	h.listLoans(c, nil)
+}
+
+// GET /me/loans
+// GetMyLoans godoc
+// @Summary      List my loans
+// @Description  List the loans of the caller ordered by ID as {data, page}
+// @Tags         loans
+// @Produce      json
+// @Param        status         query     string  false  "Only active (not returned), overdue or returned loans"  Enums(active, overdue, returned)
+// @Param        limit          query     int     false  "Number of loans per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of loans to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all matching loans"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /me/loans [get]
+// @Security BearerAuth
+func (h *LoanHandler) GetMyLoans(c *gin.Context) {
+	userID := c.GetUint("userID")
+	h.listLoans(c, &userID)
+}
+
+// listLoans writes one page of loans, userID limits it to the loans of one user
+func (h *LoanHandler) listLoans(c *gin.Context, userID *uint) {
+	var query services.LoanListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.GetLoans(query, params, userID)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]LoanResponse, len(result.Loans))
+	for i := range result.Loans {
+		resp[i] = mapLoanResponse(&result.Loans[i])
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
+	})
+}
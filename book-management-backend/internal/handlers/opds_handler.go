@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/opds"
+	"book-management/pkg/pagination"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+The OPDS catalog is public and read-only so that e-reader apps, which cannot send a bearer
+token, can browse it. Every feed is served twice from the same handler:
+/opds/... as OPDS 1.2 (Atom XML) and /opds/v2/... as OPDS 2.0 (JSON).
+Attached files are offered as acquisition links, signed like the links of
+GET /books/{id}/files/{fileId}/link so they expire after FILE_URL_TTL_SEC.
+*/
+
+const (
+	opdsCatalogTitle = "Book Management"
+	opdsIDPrefix     = "urn:book-management:"
+)
+
+type OPDSHandler struct {
+	service services.IOPDSService
+}
+
+func NewOPDSHandler(service services.IOPDSService) *OPDSHandler {
+	return &OPDSHandler{service: service}
+}
+
+// publicBaseURL is the configured public address of the site, empty falls back to the request
+var publicBaseURL string
+
+// SetPublicBaseURL sets the scheme and host used in absolute links (canonical, sitemaps, feeds)
+func SetPublicBaseURL(baseURL string) {
+	publicBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// requestBaseURL is the configured public base URL, or else the scheme and host the client used
+// to reach the API, honouring a TLS terminating proxy
+func requestBaseURL(c *gin.Context) string {
+	if publicBaseURL != "" {
+		return publicBaseURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// opdsV2 tells whether the request hit the OPDS 2.0 variant of a feed
+func opdsV2(c *gin.Context) bool {
+	return strings.HasPrefix(c.FullPath(), "/opds/v2")
+}
+
+// opdsHref is the absolute URL of a catalog path in the version of the current request
+func opdsHref(c *gin.Context, path string) string {
+	prefix := "/opds"
+	if opdsV2(c) {
+		prefix = "/opds/v2"
+	}
+	return requestBaseURL(c) + prefix + path
+}
+
+// opdsError answers in the format the client asked for, e-readers show the text as is
+func opdsError(c *gin.Context, httpStatus int, err error) {
+	if opdsV2(c) {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.String(httpStatus, err.Error())
+}
+
+// newOPDSFeed starts a feed with the links every page carries: self, start and search
+func newOPDSFeed(c *gin.Context, id, title string, kind opds.Kind) *opds.Feed {
+	self := requestBaseURL(c) + c.Request.URL.RequestURI()
+	feed := &opds.Feed{
+		ID:      opdsIDPrefix + id,
+		Title:   title,
+		Kind:    kind,
+		Updated: time.Now(),
+		Links: []opds.Link{
+			{Rel: opds.RelSelf, Href: self, Kind: kind},
+			{Rel: opds.RelStart, Href: opdsHref(c, ""), Kind: opds.KindNavigation},
+		},
+	}
+	if opdsV2(c) {
+		feed.Links = append(feed.Links, opds.Link{Rel: opds.RelSearch, Href: opdsHref(c, "/search{?query}"), Kind: opds.KindAcquisition, Templated: true})
+	} else {
+		feed.Links = append(feed.Links, opds.Link{Rel: opds.RelSearch, Href: requestBaseURL(c) + "/opds/opensearch.xml", Type: opds.TypeOpenSearch})
+	}
+	return feed
+}
+
+// addOPDSPaging adds the OpenSearch counters and the next/previous/first links of an offset page
+func addOPDSPaging(c *gin.Context, feed *opds.Feed, params pagination.Params, info pagination.PageInfo, total int64) {
+	feed.TotalResults = total
+	feed.ItemsPerPage = params.Limit
+	feed.StartIndex = params.Offset + 1
+
+	for _, l := range pagination.PageLinks(c.Request.URL, params, info) {
+		rel := l.Rel
+		if rel == "prev" {
+			rel = opds.RelPrevious
+		}
+		feed.Links = append(feed.Links, opds.Link{Rel: rel, Href: requestBaseURL(c) + l.URL, Kind: feed.Kind})
+	}
+}
+
+// mapOPDSEntry turns a book into a publication entry, with one acquisition link per file
+func mapOPDSEntry(c *gin.Context, book *models.Book, acquisitions []services.OPDSAcquisition) opds.Entry {
+	entry := opds.Entry{
+		ID:        fmt.Sprintf("%sbook:%d", opdsIDPrefix, book.ID),
+		Title:     book.Title,
+		Updated:   book.UpdatedAt,
+		Summary:   book.Description,
+		Language:  book.Language,
+		Publisher: book.Publisher,
+	}
+	if book.ISBN != "" {
+		entry.Identifier = "urn:isbn:" + book.ISBN
+	}
+	if book.PublicationYear != nil {
+		entry.Issued = strconv.Itoa(*book.PublicationYear)
+	}
+	if book.Author.ID != 0 {
+		entry.Authors = []opds.Person{{
+			Name: book.Author.Name,
+			URI:  opdsHref(c, fmt.Sprintf("/authors/%d", book.Author.ID)),
+		}}
+	}
+	for _, g := range book.Genres {
+		entry.Categories = append(entry.Categories, opds.Category{Term: g.Slug, Label: g.Name})
+	}
+	if book.Series != nil {
+		entry.Series = book.Series.Name
+		entry.Position = book.SeriesPosition
+	}
+
+	var thumbnail string
+	if book.ImageVariants != nil {
+		thumbnail = book.ImageVariants.Thumbnail
+	}
+	entry.Links = append(entry.Links, opds.CoverLinks(book.Image, thumbnail)...)
+	entry.Links = append(entry.Links, opds.Link{
+		Rel:  opds.RelAlternate,
+		Href: publicBookURL(c, book.ID),
+		Type: "text/html",
+	})
+	for _, a := range acquisitions {
+		rel := opds.RelAcquisition
+		if a.File.Kind == models.BookFileSample {
+			rel = opds.RelSample
+		}
+		entry.Links = append(entry.Links, opds.Link{
+			Rel:   rel,
+			Href:  requestBaseURL(c) + a.Link.Path,
+			Type:  a.File.ContentType,
+			Title: a.File.Title,
+		})
+	}
+	return entry
+}
+
+// navigationEntry is an entry of a navigation feed pointing at another feed
+func navigationEntry(c *gin.Context, id, title, content, path string, rel string, kind opds.Kind) opds.Entry {
+	return opds.Entry{
+		ID:      opdsIDPrefix + id,
+		Title:   title,
+		Updated: time.Now(),
+		Summary: content,
+		Links:   []opds.Link{{Rel: rel, Href: opdsHref(c, path), Kind: kind}},
+	}
+}
+
+// writeOPDSFeed serializes the feed in the version of the request
+func writeOPDSFeed(c *gin.Context, feed *opds.Feed) {
+	var (
+		body        []byte
+		err         error
+		contentType string
+	)
+	if opdsV2(c) {
+		body, err = feed.MarshalOPDS2()
+		contentType = opds.TypeOPDS2
+	} else {
+		body, err = feed.MarshalAtom()
+		contentType = opds.TypeAcquisition
+		if feed.Kind == opds.KindNavigation {
+			contentType = opds.TypeNavigation
+		}
+	}
+	if err != nil {
+		opdsError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
+
+// writeOPDSBooks writes an acquisition feed of one page of books
+func writeOPDSBooks(c *gin.Context, feed *opds.Feed, params pagination.Params, page *services.OPDSBookPage) {
+	for i := range page.Books {
+		book := &page.Books[i]
+		feed.Entries = append(feed.Entries, mapOPDSEntry(c, book, page.Acquisitions[book.ID]))
+	}
+	addOPDSPaging(c, feed, params, page.PageInfo, page.Total)
+	writeOPDSFeed(c, feed)
+}
+
+// opdsParams parses limit/offset of a catalog page
+func opdsParams(c *gin.Context) (pagination.Params, bool) {
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		opdsError(c, http.StatusBadRequest, err)
+		return params, false
+	}
+	return params, true
+}
+
+// GET /opds
+// Root navigation feed: newest books, genres and authors
+func (h *OPDSHandler) GetRoot(c *gin.Context) {
+	feed := newOPDSFeed(c, "opds:root", opdsCatalogTitle, opds.KindNavigation)
+	feed.Entries = []opds.Entry{
+		navigationEntry(c, "opds:new", "Newest books", "Recently added books, newest first", "/new", opds.RelNew, opds.KindAcquisition),
+		navigationEntry(c, "opds:genres", "Genres", "Browse books by genre", "/genres", opds.RelSubsection, opds.KindNavigation),
+		navigationEntry(c, "opds:authors", "Authors", "Browse books by author", "/authors", opds.RelSubsection, opds.KindNavigation),
+	}
+	writeOPDSFeed(c, feed)
+}
+
+// GET /opds/new?limit=10&offset=0
+// Acquisition feed of the newest books
+func (h *OPDSHandler) GetNewBooks(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	page, httpStatus, err := h.service.GetNewBooks(params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, "opds:new", "Newest books", opds.KindAcquisition)
+	writeOPDSBooks(c, feed, params, page)
+}
+
+// GET /opds/search?q=
+// Acquisition feed of the books whose title matches q (query on OPDS 2)
+func (h *OPDSHandler) SearchBooks(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	q := c.Query("q")
+	if q == "" {
+		q = c.Query("query")
+	}
+	page, httpStatus, err := h.service.SearchBooks(q, params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, "opds:search:"+url.QueryEscape(q), fmt.Sprintf("Search results for %q", q), opds.KindAcquisition)
+	writeOPDSBooks(c, feed, params, page)
+}
+
+// GET /opds/genres?limit=10&offset=0
+// Navigation feed with one entry per genre
+func (h *OPDSHandler) GetGenres(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	result, total, httpStatus, err := h.service.GetGenres(params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, "opds:genres", "Genres", opds.KindNavigation)
+	for _, g := range result.Genres {
+		id := strconv.FormatUint(uint64(g.ID), 10)
+		feed.Entries = append(feed.Entries, navigationEntry(c, "genre:"+id, g.Name, "Books in "+g.Name, "/genres/"+id, opds.RelSubsection, opds.KindAcquisition))
+	}
+	addOPDSPaging(c, feed, params, result.PageInfo, total)
+	writeOPDSFeed(c, feed)
+}
+
+// GET /opds/genres/:id?limit=10&offset=0
+// Acquisition feed of the books of a genre
+func (h *OPDSHandler) GetGenreBooks(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	genre, page, httpStatus, err := h.service.GetGenreBooks(c.Param("id"), params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, fmt.Sprintf("genre:%d", genre.ID), genre.Name, opds.KindAcquisition)
+	feed.Links = append(feed.Links, opds.Link{Rel: opds.RelUp, Href: opdsHref(c, "/genres"), Kind: opds.KindNavigation})
+	writeOPDSBooks(c, feed, params, page)
+}
+
+// GET /opds/authors?limit=10&offset=0
+// Navigation feed with one entry per author
+func (h *OPDSHandler) GetAuthors(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	result, total, httpStatus, err := h.service.GetAuthors(params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, "opds:authors", "Authors", opds.KindNavigation)
+	for _, a := range result.Authors {
+		id := strconv.FormatUint(uint64(a.ID), 10)
+		feed.Entries = append(feed.Entries, navigationEntry(c, "author:"+id, a.Name, "Books by "+a.Name, "/authors/"+id, opds.RelSubsection, opds.KindAcquisition))
+	}
+	addOPDSPaging(c, feed, params, result.PageInfo, total)
+	writeOPDSFeed(c, feed)
+}
+
+// GET /opds/authors/:id?limit=10&offset=0
+// Acquisition feed of the books of an author
+func (h *OPDSHandler) GetAuthorBooks(c *gin.Context) {
+	params, ok := opdsParams(c)
+	if !ok {
+		return
+	}
+
+	author, page, httpStatus, err := h.service.GetAuthorBooks(c.Param("id"), params)
+	if err != nil {
+		opdsError(c, httpStatus, err)
+		return
+	}
+
+	feed := newOPDSFeed(c, fmt.Sprintf("author:%d", author.ID), author.Name, opds.KindAcquisition)
+	feed.Links = append(feed.Links, opds.Link{Rel: opds.RelUp, Href: opdsHref(c, "/authors"), Kind: opds.KindNavigation})
+	writeOPDSBooks(c, feed, params, page)
+}
+
+// GET /opds/opensearch.xml
+// OpenSearch description telling OPDS 1.2 clients how to build search URLs
+func (h *OPDSHandler) GetOpenSearchDescription(c *gin.Context) {
+	base := requestBaseURL(c)
+	body, err := opds.MarshalOpenSearch(opdsCatalogTitle, "Search books by title", []opds.SearchTemplate{
+		{Type: opds.TypeAcquisition, Template: base + "/opds/search?q={searchTerms}"},
+		{Type: opds.TypeOPDS2, Template: base + "/opds/v2/search?query={searchTerms}"},
+	})
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, opds.TypeOpenSearch+"; charset=utf-8", body)
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"book-management/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newPage sets the Link header of a list response and returns its "page" envelope
+func newPage(c *gin.Context, params pagination.Params, info pagination.PageInfo) pagination.Page {
+	page, links := pagination.NewPage(c.Request.URL, params, info)
+	if len(links) > 0 {
+		c.Header("Link", pagination.FormatLinks(links))
+	}
+	return page
+}
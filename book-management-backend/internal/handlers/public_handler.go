@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/schemaorg"
+	"book-management/pkg/sitemap"
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+/*
+Public, unauthenticated pages meant for search engines and link previews:
+book pages carry schema.org JSON-LD and Open Graph tags, sitemap.xml lists every book page.
+*/
+
+const (
+	publicSiteName       = "Book Management"
+	publicExcerptLength  = 300 // runes of the description used in meta descriptions
+	publicSitemapPerBook = "weekly"
+)
+
+type PublicHandler struct {
+	service services.IPublicService
+}
+
+func NewPublicHandler(service services.IPublicService) *PublicHandler {
+	return &PublicHandler{service: service}
+}
+
+// publicBookURL is the canonical URL of a book page
+func publicBookURL(c *gin.Context, bookID uint) string {
+	return fmt.Sprintf("%s/public/books/%d", requestBaseURL(c), bookID)
+}
+
+// schemaBookFormats maps our formats to schema.org BookFormatType
+var schemaBookFormats = map[models.BookFormat]string{
+	models.FormatHardcover: schemaorg.FormatHardcover,
+	models.FormatPaperback: schemaorg.FormatPaperback,
+	models.FormatEbook:     schemaorg.FormatEBook,
+	models.FormatAudiobook: schemaorg.FormatAudiobook,
+}
+
+// mapSchemaBook builds the schema.org Book node of a book page
+func mapSchemaBook(c *gin.Context, book *models.Book) *schemaorg.Book {
+	url := publicBookURL(c, book.ID)
+	node := schemaorg.NewBook(book.Title)
+	node.ID = url
+	node.URL = url
+	node.Description = book.Description
+	node.Image = book.Image
+	node.ISBN = book.ISBN
+	node.InLanguage = book.Language
+	node.BookFormat = schemaBookFormats[book.Format]
+	node.DateModified = book.UpdatedAt.UTC().Format("2006-01-02")
+
+	if book.Author.Name != "" {
+		node.Author = schemaorg.NewPerson(book.Author.Name, "")
+	}
+	if book.Publisher != "" {
+		node.Publisher = schemaorg.NewOrganization(book.Publisher)
+	}
+	if book.PublicationYear != nil {
+		node.DatePublished = strconv.Itoa(*book.PublicationYear)
+	}
+	for _, g := range book.Genres {
+		node.Genre = append(node.Genre, g.Name)
+	}
+	if len(book.Tags) > 0 {
+		tags := make([]string, len(book.Tags))
+		for i, t := range book.Tags {
+			tags[i] = t.Name
+		}
+		node.Keywords = strings.Join(tags, ", ")
+	}
+	if book.Series != nil {
+		node.IsPartOf = schemaorg.NewBookSeries(book.Series.Name)
+		node.Position = book.SeriesPosition
+	}
+	return node
+}
+
+// excerpt shortens s to at most n runes, cutting at a word boundary
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+type metaTag struct {
+	Property string
+	Content  string
+}
+
+// openGraphTags lists the og:* and book:* properties of a book page, see https://ogp.me/#type_book
+func openGraphTags(c *gin.Context, book *models.Book) []metaTag {
+	tags := []metaTag{
+		{"og:site_name", publicSiteName},
+		{"og:type", "book"},
+		{"og:title", book.Title},
+		{"og:url", publicBookURL(c, book.ID)},
+	}
+	if book.Description != "" {
+		tags = append(tags, metaTag{"og:description", excerpt(book.Description, publicExcerptLength)})
+	}
+	if book.Image != "" {
+		// Link previews do not need the full size upload
+		image := book.Image
+		if book.ImageVariants != nil && book.ImageVariants.Large != "" {
+			image = book.ImageVariants.Large
+		}
+		tags = append(tags, metaTag{"og:image", image}, metaTag{"og:image:alt", "Cover of " + book.Title})
+	}
+	if book.Language != "" {
+		tags = append(tags, metaTag{"og:locale", book.Language})
+	}
+	if book.Author.Name != "" {
+		tags = append(tags, metaTag{"book:author", book.Author.Name})
+	}
+	if book.ISBN != "" {
+		tags = append(tags, metaTag{"book:isbn", book.ISBN})
+	}
+	if book.PublicationYear != nil {
+		tags = append(tags, metaTag{"book:release_date", strconv.Itoa(*book.PublicationYear)})
+	}
+	for _, g := range book.Genres {
+		tags = append(tags, metaTag{"book:tag", g.Name})
+	}
+	return tags
+}
+
+var publicBookTemplate = template.Must(template.New("book").Parse(`<!DOCTYPE html>
+<html{{if .Book.Language}} lang="{{.Book.Language}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Book.Title}}{{if .Book.Author.Name}} by {{.Book.Author.Name}}{{end}} | {{.SiteName}}</title>
+{{- if .Description}}
+<meta name="description" content="{{.Description}}">
+{{- end}}
+<link rel="canonical" href="{{.URL}}">
+<link rel="alternate" type="application/ld+json" href="{{.URL}}">
+{{- range .OpenGraph}}
+<meta property="{{.Property}}" content="{{.Content}}">
+{{- end}}
+<meta name="twitter:card" content="{{if .Book.Image}}summary_large_image{{else}}summary{{end}}">
+<script type="application/ld+json">{{.JSONLD}}</script>
+</head>
+<body>
+<article>
+<h1>{{.Book.Title}}</h1>
+{{- if .Book.Author.Name}}
+<p>by {{.Book.Author.Name}}</p>
+{{- end}}
+{{- if .Book.Image}}
+<img src="{{.Book.Image}}" alt="Cover of {{.Book.Title}}">
+{{- end}}
+{{- if .Book.Series}}
+<p>{{.Book.Series.Name}}{{if .Book.SeriesPosition}} #{{.Book.SeriesPosition}}{{end}}</p>
+{{- end}}
+{{- if .Book.Description}}
+<p>{{.Book.Description}}</p>
+{{- end}}
+<dl>
+{{- if .Book.Publisher}}<dt>Publisher</dt><dd>{{.Book.Publisher}}</dd>{{end}}
+{{- if .Book.PublicationYear}}<dt>Published</dt><dd>{{.Book.PublicationYear}}</dd>{{end}}
+{{- if .Book.ISBN}}<dt>ISBN</dt><dd>{{.Book.ISBN}}</dd>{{end}}
+{{- if .Book.Language}}<dt>Language</dt><dd>{{.Book.Language}}</dd>{{end}}
+{{- if .Book.Genres}}<dt>Genres</dt><dd>{{range $i, $g := .Book.Genres}}{{if $i}}, {{end}}{{$g.Name}}{{end}}</dd>{{end}}
+</dl>
+</article>
+</body>
+</html>
+`))
+
+type publicBookPage struct {
+	SiteName    string
+	URL         string
+	Description string
+	Book        *models.Book
+	OpenGraph   []metaTag
+	JSONLD      template.JS
+}
+
+// GET /public/books/:id
+// Public book page, HTML with embedded JSON-LD and Open Graph tags, or the bare JSON-LD
+// when the client asks for application/ld+json (or application/json)
+func (h *PublicHandler) GetBook(c *gin.Context) {
+	c.Header("Vary", "Accept")
+	format := c.NegotiateFormat(gin.MIMEHTML, schemaorg.ContentType, gin.MIMEJSON)
+	if format == "" {
+		c.String(http.StatusNotAcceptable, "supported formats: text/html, application/ld+json")
+		return
+	}
+
+	book, httpStatus, err := h.service.GetBook(c.Param("id"))
+	if err != nil {
+		if format == gin.MIMEHTML {
+			c.String(httpStatus, err.Error())
+		} else {
+			c.JSON(httpStatus, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	jsonLD, err := schemaorg.Marshal(mapSchemaBook(c, book))
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if format != gin.MIMEHTML {
+		c.Data(http.StatusOK, schemaorg.ContentType+"; charset=utf-8", jsonLD)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = publicBookTemplate.Execute(&buf, publicBookPage{
+		SiteName:    publicSiteName,
+		URL:         publicBookURL(c, book.ID),
+		Description: excerpt(book.Description, publicExcerptLength),
+		Book:        book,
+		OpenGraph:   openGraphTags(c, book),
+		JSONLD:      template.JS(jsonLD), // json.Marshal escapes <, > and &, the script cannot be closed early
+	})
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, gin.MIMEHTML+"; charset=utf-8", buf.Bytes())
+}
+
+// GET /sitemap.xml
+// Sitemap of every public book page, a sitemap index of /sitemaps/books-N.xml once there are
+// more books than a single sitemap may hold
+func (h *PublicHandler) GetSitemap(c *gin.Context) {
+	pages, httpStatus, err := h.service.CountSitemaps()
+	if err != nil {
+		c.String(httpStatus, err.Error())
+		return
+	}
+	if pages == 1 {
+		h.writeBookSitemap(c, "1")
+		return
+	}
+
+	sitemaps := make([]sitemap.URL, pages)
+	for i := range sitemaps {
+		sitemaps[i] = sitemap.URL{Loc: fmt.Sprintf("%s/sitemaps/books-%d.xml", requestBaseURL(c), i+1)}
+	}
+	body, err := sitemap.MarshalIndex(sitemaps)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, sitemap.ContentType+"; charset=utf-8", body)
+}
+
+// GET /sitemaps/:name
+// One page of the book sitemap, name is books-N.xml
+func (h *PublicHandler) GetBookSitemap(c *gin.Context) {
+	page, ok := strings.CutPrefix(c.Param("name"), "books-")
+	if ok {
+		page, ok = strings.CutSuffix(page, ".xml")
+	}
+	if !ok {
+		c.String(http.StatusNotFound, "sitemap does not exist")
+		return
+	}
+	h.writeBookSitemap(c, page)
+}
+
+func (h *PublicHandler) writeBookSitemap(c *gin.Context, page string) {
+	entries, httpStatus, err := h.service.GetSitemapEntries(page)
+	if err != nil {
+		c.String(httpStatus, err.Error())
+		return
+	}
+
+	urls := make([]sitemap.URL, len(entries))
+	for i, e := range entries {
+		urls[i] = sitemap.URL{Loc: publicBookURL(c, e.ID), LastMod: e.UpdatedAt, ChangeFreq: publicSitemapPerBook}
+	}
+	body, err := sitemap.MarshalURLSet(urls)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, sitemap.ContentType+"; charset=utf-8", body)
+}
+
+// GET /robots.txt
+// Points crawlers at the sitemap and keeps them out of the API
+func (h *PublicHandler) GetRobots(c *gin.Context) {
+	c.String(http.StatusOK, "User-agent: *\nAllow: /public/\nDisallow: /api/\nSitemap: %s/sitemap.xml\n", requestBaseURL(c))
+}
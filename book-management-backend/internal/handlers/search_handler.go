@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"book-management/internal/repositories"
+	"book-management/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	service services.ISearchService
+}
+
+func NewSearchHandler(service services.ISearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+type BookSearchHitResponse struct {
+	ID         uint    `json:"id"`
+	Title      string  `json:"title"`
+	AuthorName string  `json:"author_name"`
+	Snippet    string  `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+	Rank       float64 `json:"rank"`
+}
+
+type NamedSearchHitResponse struct {
+	ID      uint    `json:"id"`
+	Name    string  `json:"name"`
+	Snippet string  `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+	Rank    float64 `json:"rank"`
+}
+
+type SearchResponse struct {
+	Query   string                   `json:"query"`
+	Books   []BookSearchHitResponse  `json:"books,omitempty"`
+	Authors []NamedSearchHitResponse `json:"authors,omitempty"`
+	Genres  []NamedSearchHitResponse `json:"genres,omitempty"`
+}
+
+func mapNamedSearchHits(hits []repositories.NamedSearchHit) []NamedSearchHitResponse {
+	if hits == nil {
+		return nil
+	}
+	resp := make([]NamedSearchHitResponse, len(hits))
+	for i, h := range hits {
+		resp[i] = NamedSearchHitResponse{
+			ID:      h.ID,
+			Name:    h.Name,
+			Snippet: h.Snippet,
+			Rank:    h.Rank,
+		}
+	}
+	return resp
+}
+
+func mapSearchResponse(q string, result *services.SearchResult) SearchResponse {
+	var books []BookSearchHitResponse
+	if result.Books != nil {
+		books = make([]BookSearchHitResponse, len(result.Books))
+		for i, h := range result.Books {
+			books[i] = BookSearchHitResponse{
+				ID:         h.ID,
+				Title:      h.Title,
+				AuthorName: h.AuthorName,
+				Snippet:    h.Snippet,
+				Rank:       h.Rank,
+			}
+		}
+	}
+
+	return SearchResponse{
+		Query:   q,
+		Books:   books,
+		Authors: mapNamedSearchHits(result.Authors),
+		Genres:  mapNamedSearchHits(result.Genres),
+	}
+}
+
+type BookSuggestionResponse struct {
+	ID         uint    `json:"id"`
+	Title      string  `json:"title"`
+	AuthorName string  `json:"author_name"`
+	Score      float64 `json:"score"`
+}
+
+type AuthorSuggestionResponse struct {
+	ID    uint    `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// GET /search?q=dune&types=books,authors&limit=10
+// Search godoc
+// @Summary      Full-text search across books, authors and genres
+// @Description  Ranked PostgreSQL full-text search. Books match on title, author name, genre names and description (in that weight order).
+// @Description  q supports web search syntax: "quoted phrase", OR, -excluded. Results are grouped by entity type.
+// @Tags         search
+// @Produce      json
+// @Param        q      query     string  true   "Search text"
+// @Param        types  query     string  false  "Comma separated entity types to search (books, authors, genres), default all"
+// @Param        limit  query     int     false  "Max hits per entity type (1-50)"  default(10)
+// @Success      200    {object}  SearchResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /search [get]
+// @Security BearerAuth
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := c.Query("q")
+
+	result, httpStatus, err := h.service.Search(q, c.Query("types"), c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapSearchResponse(q, result))
+}
+
+// GET /books/suggest?q=tolkein&limit=5
+// SuggestBooks godoc
+// @Summary      Suggest book titles (type-ahead)
+// @Description  Typo-tolerant, accent-insensitive title suggestions using trigram similarity. Inputs shorter than 2 characters return an empty list.
+// @Tags         search
+// @Produce      json
+// @Param        q      query     string  true   "Partial title"
+// @Param        limit  query     int     false  "Max number of suggestions (1-20)"  default(5)
+// @Success      200    {array}   BookSuggestionResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /books/suggest [get]
+// @Security BearerAuth
+func (h *SearchHandler) SuggestBooks(c *gin.Context) {
+	suggestions, httpStatus, err := h.service.SuggestBooks(c.Query("q"), c.DefaultQuery("limit", "5"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]BookSuggestionResponse, len(suggestions))
+	for i, s := range suggestions {
+		resp[i] = BookSuggestionResponse{
+			ID:         s.ID,
+			Title:      s.Title,
+			AuthorName: s.AuthorName,
+			Score:      s.Score,
+		}
+	}
+
+	c.JSON(httpStatus, resp)
+}
+
+// GET /authors/suggest?q=nguyen nhat anh&limit=5
+// SuggestAuthors godoc
+// @Summary      Suggest author names (type-ahead)
+// @Description  Typo-tolerant, accent-insensitive name suggestions using trigram similarity (e.g. "nguyen nhat anh" finds "Nguyễn Nhật Ánh"). Inputs shorter than 2 characters return an empty list.
+// @Tags         search
+// @Produce      json
+// @Param        q      query     string  true   "Partial name"
+// @Param        limit  query     int     false  "Max number of suggestions (1-20)"  default(5)
+// @Success      200    {array}   AuthorSuggestionResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /authors/suggest [get]
+// @Security BearerAuth
+func (h *SearchHandler) SuggestAuthors(c *gin.Context) {
+	suggestions, httpStatus, err := h.service.SuggestAuthors(c.Query("q"), c.DefaultQuery("limit", "5"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]AuthorSuggestionResponse, len(suggestions))
+	for i, s := range suggestions {
+		resp[i] = AuthorSuggestionResponse{
+			ID:    s.ID,
+			Name:  s.Name,
+			Score: s.Score,
+		}
+	}
+
+	c.JSON(httpStatus, resp)
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeriesHandler struct {
+	service services.ISeriesService
+}
+
+func NewSeriesHandler(service services.ISeriesService) *SeriesHandler {
+	return &SeriesHandler{service: service}
+}
+
+type BookResponseForSeries struct {
+	ID       uint     `json:"id"`
+	Title    string   `json:"title"`
+	Position *float64 `json:"position"`
+}
+
+type SeriesResponse struct {
+	ID          uint                    `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Books       []BookResponseForSeries `json:"books"`
+	CreatedAt   string                  `json:"created_at"`
+	UpdatedAt   string                  `json:"updated_at"`
+}
+
+func mapSeriesResponse(series *models.Series) SeriesResponse {
+	books := make([]BookResponseForSeries, len(series.Books))
+	for i, b := range series.Books {
+		books[i] = BookResponseForSeries{
+			ID:       b.ID,
+			Title:    b.Title,
+			Position: b.SeriesPosition,
+		}
+	}
+
+	return SeriesResponse{
+		ID:          series.ID,
+		Name:        series.Name,
+		Description: series.Description,
+		Books:       books,
+		CreatedAt:   series.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:   series.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// GET /series/:id
+// GetSeriesByID godoc
+// @Summary      Get series details by ID
+// @Description  Get a single series with its books in reading order
+// @Tags         series
+// @Produce      json
+// @Param        id   path      string  true  "Series ID"
+// @Success      200  {object}  SeriesResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /series/{id} [get]
+// @Security BearerAuth
+func (h *SeriesHandler) GetSeriesByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	series, httpStatus, err := h.service.GetSeriesByID(idStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, mapSeriesResponse(series))
+}
+
+// GET /series?limit=10&offset=0
+// GetAllSeries godoc
+// @Summary      Get all series with pagination
+// @Description  Retrieve a paginated list of series ordered by ID as {data, page}, pages can be walked with offset or with page.next_cursor (next/prev links are also sent in the Link header)
+// @Tags         series
+// @Produce      json
+// @Param        limit          query     int     false  "Number of series per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of series to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all series"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /series [get]
+// @Security BearerAuth
+func (h *SeriesHandler) GetAllSeries(c *gin.Context) {
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.GetAllSeries(params)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]SeriesResponse, len(result.Series))
+	for i := range result.Series {
+		resp[i] = mapSeriesResponse(&result.Series[i])
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
+	})
+}
+
+// GET /series/:id/books
+// GetBooksInSeries godoc
+// @Summary      Get books of a series in reading order
+// @Description  Books are sorted by series position, books without a position are listed last
+// @Tags         series
+// @Produce      json
+// @Param        id   path      string  true  "Series ID"
+// @Success      200  {array}   BookResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /series/{id}/books [get]
+// @Security BearerAuth
+func (h *SeriesHandler) GetBooksInSeries(c *gin.Context) {
+	idStr := c.Param("id")
+
+	books, httpStatus, err := h.service.GetBooksInSeries(idStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]BookResponse, len(*books))
+	for i := range *books {
+		resp[i] = mapBookResponse(&(*books)[i])
+	}
+
+	c.JSON(httpStatus, resp)
+}
+
+// POST /series
+// CreateSeries godoc
+// @Summary      Create a new series
+// @Description  Create a new series with a name and optional description
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        series  body      services.SeriesCreateRequest  true  "Series data"
+// @Success      201 {object} SeriesResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /series [post]
+// @Security BearerAuth
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	var req services.SeriesCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdSeries, err := h.service.CreateSeries(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapSeriesResponse(createdSeries))
+}
+
+// PATCH /series/:id
+// UpdateSeries godoc
+// @Summary      Update a series partially
+// @Description  Update series fields partially by ID (PATCH)
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                        true  "Series ID"
+// @Param        series  body      services.SeriesUpdateRequest  true  "Series fields to update"
+// @Success      200 {object} SeriesResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /series/{id} [patch]
+// @Security BearerAuth
+func (h *SeriesHandler) UpdateSeries(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.SeriesUpdateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedSeries, httpStatus, err := h.service.UpdateSeries(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapSeriesResponse(updatedSeries))
+}
+
+// DELETE /series/:id
+// DeleteSeries godoc
+// @Summary      Delete a series
+// @Description  Delete a series by its ID, its books are kept but detached from the series
+// @Tags         series
+// @Produce      json
+// @Param        id   path      string  true  "Series ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /series/{id} [delete]
+// @Security BearerAuth
+func (h *SeriesHandler) DeleteSeries(c *gin.Context) {
+	idStr := c.Param("id")
+	httpStatus, err := h.service.DeleteSeries(idStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, nil)
+}
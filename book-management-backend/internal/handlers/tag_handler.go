@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"book-management/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service services.ITagService
+}
+
+func NewTagHandler(service services.ITagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+type TagSuggestionResponse struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	BookCount int64  `json:"book_count"`
+}
+
+// GET /tags/autocomplete?q=boo&limit=10
+// Autocomplete godoc
+// @Summary      Autocomplete tags
+// @Description  Suggest existing tags starting with the given text, most used first
+// @Tags         tags
+// @Produce      json
+// @Param        q      query     string  false  "Tag prefix"
+// @Param        limit  query     int     false  "Max number of suggestions (1-50)"  default(10)
+// @Success      200    {array}   TagSuggestionResponse
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /tags/autocomplete [get]
+// @Security BearerAuth
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	suggestions, httpStatus, err := h.service.Autocomplete(c.Query("q"), c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]TagSuggestionResponse, len(suggestions))
+	for i, t := range suggestions {
+		resp[i] = TagSuggestionResponse{
+			ID:        t.ID,
+			Name:      t.Name,
+			BookCount: t.BookCount,
+		}
+	}
+
+	c.JSON(httpStatus, resp)
+}
+
+// POST /books/:id/tags
+// AddTagsToBook godoc
+// @Summary      Tag a book
+// @Description  Attach free-form tags to a book, tags that do not exist yet are created
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                   true  "Book ID"
+// @Param        body  body      services.TagBookRequest  true  "Tag names"
+// @Success      200   {object}  BookResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /books/{id}/tags [post]
+// @Security BearerAuth
+func (h *TagHandler) AddTagsToBook(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.TagBookRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	book, httpStatus, err := h.service.AddTagsToBook(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapBookResponse(book))
+}
+
+// DELETE /books/:id/tags
+// RemoveTagsFromBook godoc
+// @Summary      Untag a book
+// @Description  Detach tags from a book by name, unknown tags are ignored
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                   true  "Book ID"
+// @Param        body  body      services.TagBookRequest  true  "Tag names"
+// @Success      200   {object}  BookResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /books/{id}/tags [delete]
+// @Security BearerAuth
+func (h *TagHandler) RemoveTagsFromBook(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.TagBookRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	book, httpStatus, err := h.service.RemoveTagsFromBook(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapBookResponse(book))
+}
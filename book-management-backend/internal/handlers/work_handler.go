@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/services"
+	"book-management/pkg/pagination"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkHandler struct {
+	service services.IWorkService
+}
+
+func NewWorkHandler(service services.IWorkService) *WorkHandler {
+	return &WorkHandler{service: service}
+}
+
+type EditionResponse struct {
+	ID              uint                  `json:"id"`
+	Title           string                `json:"title"`
+	Author          AuthorResponseForBook `json:"author"`
+	ISBN            string                `json:"isbn"`
+	Publisher       string                `json:"publisher"`
+	Language        string                `json:"language"`
+	Format          string                `json:"format"`
+	PublicationYear *int                  `json:"publication_year"`
+	Image           string                `json:"image"`
+}
+
+type WorkResponse struct {
+	ID          uint                   `json:"id"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Genres      []GenreResponseForBook `json:"genres"`
+	Editions    []EditionResponse      `json:"editions"`
+	CreatedAt   string                 `json:"created_at"`
+	UpdatedAt   string                 `json:"updated_at"`
+}
+
+func mapWorkResponse(work *models.Work) WorkResponse {
+	genres := make([]GenreResponseForBook, len(work.Genres))
+	for i, g := range work.Genres {
+		genres[i] = GenreResponseForBook{
+			ID:   g.ID,
+			Name: g.Name,
+		}
+	}
+
+	editions := make([]EditionResponse, len(work.Editions))
+	for i, b := range work.Editions {
+		editions[i] = EditionResponse{
+			ID:    b.ID,
+			Title: b.Title,
+			Author: AuthorResponseForBook{
+				ID:   b.Author.ID,
+				Name: b.Author.Name,
+			},
+			ISBN:            b.ISBN,
+			Publisher:       b.Publisher,
+			Language:        b.Language,
+			Format:          string(b.Format),
+			PublicationYear: b.PublicationYear,
+			Image:           b.Image,
+		}
+	}
+
+	return WorkResponse{
+		ID:          work.ID,
+		Title:       work.Title,
+		Description: work.Description,
+		Genres:      genres,
+		Editions:    editions,
+		CreatedAt:   work.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:   work.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// GET /works/:id
+// GetWorkByID godoc
+// @Summary      Get work details by ID
+// @Description  Get a single work with its genres and all of its editions
+// @Tags         works
+// @Produce      json
+// @Param        id   path      string  true  "Work ID"
+// @Success      200  {object}  WorkResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /works/{id} [get]
+// @Security BearerAuth
+func (h *WorkHandler) GetWorkByID(c *gin.Context) {
+	idStr := c.Param("id")
+
+	work, httpStatus, err := h.service.GetWorkByID(idStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, mapWorkResponse(work))
+}
+
+// GET /works?limit=10&offset=0
+// GetAllWorks godoc
+// @Summary      Get all works with pagination
+// @Description  Retrieve a paginated list of works with their editions, ordered by ID as {data, page}, pages can be walked with offset or with page.next_cursor (next/prev links are also sent in the Link header)
+// @Tags         works
+// @Produce      json
+// @Param        limit          query     int     false  "Number of works per page, capped to the configured maximum (100 by default)"  default(10)
+// @Param        offset         query     int     false  "Number of works to skip"  default(0)
+// @Param        cursor         query     string  false  "Opaque next_cursor of the previous page (cannot be combined with offset)"
+// @Param        include_total  query     bool    false  "Also count all works"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /works [get]
+// @Security BearerAuth
+func (h *WorkHandler) GetAllWorks(c *gin.Context) {
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, httpStatus, err := h.service.GetAllWorks(params)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]WorkResponse, len(result.Works))
+	for i := range result.Works {
+		resp[i] = mapWorkResponse(&result.Works[i])
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"data": resp,
+		"page": newPage(c, params, result.PageInfo),
+	})
+}
+
+// POST /works
+// CreateWork godoc
+// @Summary      Create a new work
+// @Description  Create a new work with a title, optional description and work-level genres
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Param        work  body      services.WorkCreateRequest  true  "Work data"
+// @Success      201 {object} WorkResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /works [post]
+// @Security BearerAuth
+func (h *WorkHandler) CreateWork(c *gin.Context) {
+	var req services.WorkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdWork, httpStatus, err := h.service.CreateWork(req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapWorkResponse(createdWork))
+}
+
+// PATCH /works/:id
+// UpdateWork godoc
+// @Summary      Update a work partially
+// @Description  Update work fields partially by ID (PATCH), genre_ids replaces all work-level genres
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                      true  "Work ID"
+// @Param        work  body      services.WorkUpdateRequest  true  "Work fields to update"
+// @Success      200 {object} WorkResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /works/{id} [patch]
+// @Security BearerAuth
+func (h *WorkHandler) UpdateWork(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.WorkUpdateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedWork, httpStatus, err := h.service.UpdateWork(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, mapWorkResponse(updatedWork))
+}
+
+// DELETE /works/:id
+// DeleteWork godoc
+// @Summary      Delete a work
+// @Description  Delete a work by its ID, its editions are kept but detached from the work
+// @Tags         works
+// @Produce      json
+// @Param        id   path      string  true  "Work ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /works/{id} [delete]
+// @Security BearerAuth
+func (h *WorkHandler) DeleteWork(c *gin.Context) {
+	idStr := c.Param("id")
+	httpStatus, err := h.service.DeleteWork(idStr)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(httpStatus, nil)
+}
+
+// =============================
+// Edition Management
+// =============================
+
+// POST /works/:id/editions
+// AttachEditions godoc
+// @Summary      Attach editions to a work
+// @Description  Link books (editions) to a work by providing their IDs, books of another work are moved
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                       true  "Work ID"
+// @Param        body  body      services.ManageBooksRequest  true  "List of book IDs"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /works/{id}/editions [post]
+// @Security BearerAuth
+func (h *WorkHandler) AttachEditions(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.ManageBooksRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	work, httpStatus, err := h.service.AttachEditions(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"message": "Editions attached successfully",
+		"work":    mapWorkResponse(work),
+	})
+}
+
+// DELETE /works/:id/editions
+// DetachEditions godoc
+// @Summary      Detach editions from a work
+// @Description  Unlink books (editions) from a work by providing their IDs
+// @Tags         works
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                       true  "Work ID"
+// @Param        body  body      services.ManageBooksRequest  true  "List of book IDs"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /works/{id}/editions [delete]
+// @Security BearerAuth
+func (h *WorkHandler) DetachEditions(c *gin.Context) {
+	idStr := c.Param("id")
+	var req services.ManageBooksRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	work, httpStatus, err := h.service.DetachEditions(idStr, req)
+	if err != nil {
+		c.JSON(httpStatus, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"message": "Editions detached successfully",
+		"work":    mapWorkResponse(work),
+	})
+}
@@ -4,7 +4,8 @@ import "gorm.io/gorm"
 
 type Author struct {
 	gorm.Model
-	Name  string `gorm:"type:varchar(100);not null"`
-	Email string `gorm:"type:varchar(100);uniqueIndex"`
+	Name string `gorm:"type:varchar(100);not null"`
+	// Email is optional for imported authors, unique when present
+	Email string `gorm:"type:varchar(100);uniqueIndex:idx_authors_email_present,where:email <> ''"`
 	Books []Book `json:"books"`
 }
@@ -2,11 +2,51 @@ package models
 
 import "gorm.io/gorm"
 
+type BookFormat string
+
+const (
+	FormatHardcover BookFormat = "hardcover"
+	FormatPaperback BookFormat = "paperback"
+	FormatEbook     BookFormat = "ebook"
+	FormatAudiobook BookFormat = "audiobook"
+	FormatOther     BookFormat = "other"
+)
+
+// ImageVariants are the URLs of the resized copies of a cover
+type ImageVariants struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+}
+
 type Book struct {
 	gorm.Model
-	Title    string  `json:"title"`
-	AuthorID uint    `json:"author_id"`
-	Author   Author  `gorm:"foreignKey:AuthorID" json:"author"`
-	Image    string  `json:"image"`
-	Genres   []Genre `gorm:"many2many:book_genres"`
+	Title       string  `json:"title"`
+	Description string  `gorm:"type:text" json:"description"`
+	AuthorID    uint    `json:"author_id"`
+	Author      Author  `gorm:"foreignKey:AuthorID" json:"author"`
+	Image       string  `json:"image"`
+	Genres      []Genre `gorm:"many2many:book_genres"`
+	Tags        []Tag   `gorm:"many2many:book_tags" json:"tags"`
+
+	// Uploaded covers only: Cloudinary public ID (content hash) and resized copies, external image URLs have neither
+	ImagePublicID string         `gorm:"type:varchar(255)" json:"-"`
+	ImageVariants *ImageVariants `gorm:"type:jsonb;serializer:json" json:"image_variants"`
+
+	// Series membership, position may be fractional (e.g. 2.5 for a novella between #2 and #3)
+	SeriesID       *uint    `gorm:"index" json:"series_id"`
+	Series         *Series  `gorm:"foreignKey:SeriesID;constraint:OnDelete:SET NULL" json:"series,omitempty"`
+	SeriesPosition *float64 `json:"series_position"`
+
+	// Edition details, a Book row is one edition of a Work
+	WorkID          *uint      `gorm:"index" json:"work_id"`
+	Work            *Work      `gorm:"foreignKey:WorkID;constraint:OnDelete:SET NULL" json:"work,omitempty"`
+	ISBN            string     `gorm:"type:varchar(13);uniqueIndex:idx_books_isbn,where:isbn <> '' AND deleted_at IS NULL" json:"isbn"`
+	Publisher       string     `gorm:"type:varchar(255)" json:"publisher"`
+	Language        string     `gorm:"type:varchar(10)" json:"language"` // ISO 639-1 code, e.g. "en", "vi"
+	Format          BookFormat `gorm:"type:varchar(20)" json:"format"`
+	PublicationYear *int       `json:"publication_year"`
+
+	// Copy counts, not stored: filled in by the service when a book is returned
+	Availability *CopyAvailability `gorm:"-" json:"availability,omitempty"`
 }
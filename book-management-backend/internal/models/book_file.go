@@ -0,0 +1,31 @@
+package models
+
+import "gorm.io/gorm"
+
+type BookFileKind string
+
+const (
+	BookFileFull   BookFileKind = "full"   // the whole e-book or an audiobook chapter
+	BookFileSample BookFileKind = "sample" // an excerpt offered before borrowing
+)
+
+// BookFile is a digital file (EPUB, PDF, MP3) attached to a book, stored privately and
+// downloaded through signed, time-limited URLs
+type BookFile struct {
+	gorm.Model
+	BookID        uint         `gorm:"not null;index" json:"book_id"`
+	Book          *Book        `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"-"`
+	Kind          BookFileKind `gorm:"type:varchar(20);not null;default:'full'" json:"kind"`
+	Title         string       `gorm:"type:varchar(255)" json:"title"` // e.g. "Chapter 3", optional
+	FileName      string       `gorm:"type:varchar(255);not null" json:"file_name"`
+	Format        string       `gorm:"type:varchar(10);not null" json:"format"` // epub, pdf or mp3, sniffed from the content
+	ContentType   string       `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size          int64        `gorm:"not null" json:"size"`                      // bytes
+	Checksum      string       `gorm:"type:varchar(64);not null" json:"checksum"` // hex SHA-256
+	DownloadCount int64        `gorm:"not null;default:0" json:"download_count"`
+	CreatedBy     uint         `json:"created_by"` // user ID
+
+	// Storage location, never exposed: files are only served through signed download URLs
+	PublicID     string `gorm:"type:varchar(255);not null" json:"-"`
+	ResourceType string `gorm:"type:varchar(10);not null" json:"-"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "gorm.io/gorm"
+
+// CirculationPolicy sets the lending rules for a kind of patron and item. Empty criteria match
+// everything, the policy with the most criteria matching a loan applies. Between policies with as
+// many criteria, such as a role-only and an item-type-only policy, the one with the lowest id applies.
+type CirculationPolicy struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(100);not null" json:"name"`
+
+	// Matching criteria, one policy per combination
+	Role           Role       `gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_circulation_policies_match,where:deleted_at IS NULL" json:"role"`
+	PatronCategory string     `gorm:"type:varchar(30);not null;default:'';uniqueIndex:idx_circulation_policies_match" json:"patron_category"`
+	ItemType       BookFormat `gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_circulation_policies_match" json:"item_type"` // format of the book
+
+	// Rules
+	LoanPeriodDays     int  `gorm:"not null" json:"loan_period_days"`
+	MaxRenewals        int  `gorm:"not null;default:0" json:"max_renewals"`
+	MaxConcurrentLoans *int `json:"max_concurrent_loans"` // open loans per patron, of ItemType when set, nil means no limit
+}
+
+// Specificity counts the matching criteria set on the policy
+func (p *CirculationPolicy) Specificity() int {
+	n := 0
+	if p.Role != "" {
+		n++
+	}
+	if p.PatronCategory != "" {
+		n++
+	}
+	if p.ItemType != "" {
+		n++
+	}
+	return n
+}
+
+// Matches tells whether the policy applies to a patron borrowing an item of the given type
+func (p *CirculationPolicy) Matches(user *User, itemType BookFormat) bool {
+	return (p.Role == "" || p.Role == user.Role) &&
+		(p.PatronCategory == "" || p.PatronCategory == user.PatronCategory) &&
+		(p.ItemType == "" || p.ItemType == itemType)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CopyStatus string
+
+const (
+	CopyAvailable CopyStatus = "available"
+	CopyOnLoan    CopyStatus = "on_loan"
+	CopyOnHold    CopyStatus = "on_hold" // set aside for a hold, waiting for pickup
+	CopyLost      CopyStatus = "lost"
+	CopyDamaged   CopyStatus = "damaged"
+	CopyInRepair  CopyStatus = "in_repair"
+)
+
+type CopyCondition string
+
+const (
+	ConditionNew  CopyCondition = "new"
+	ConditionGood CopyCondition = "good"
+	ConditionFair CopyCondition = "fair"
+	ConditionPoor CopyCondition = "poor"
+)
+
+// Copy is one physical item of a book (the title), identified by the barcode on its label
+type Copy struct {
+	gorm.Model
+	BookID           uint          `gorm:"not null;index" json:"book_id"`
+	Book             *Book         `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"book,omitempty"`
+	Barcode          string        `gorm:"type:varchar(50);not null;uniqueIndex:idx_copies_barcode,where:deleted_at IS NULL" json:"barcode"`
+	ShelfLocation    string        `gorm:"type:varchar(100)" json:"shelf_location"` // e.g. "2F-A-03"
+	Condition        CopyCondition `gorm:"type:varchar(20);not null;default:'good'" json:"condition"`
+	Status           CopyStatus    `gorm:"type:varchar(20);not null;default:'available';index" json:"status"`
+	AcquiredAt       *time.Time    `gorm:"type:date" json:"acquired_at"`
+	AcquisitionPrice *float64      `gorm:"type:numeric(10,2)" json:"acquisition_price"`
+	Notes            string        `gorm:"type:text" json:"notes"`
+}
+
+// CopyAvailability counts the copies of a book, the other copies are lost, damaged or in repair
+type CopyAvailability struct {
+	Total     int64 `json:"total"`
+	Available int64 `json:"available"`
+	OnLoan    int64 `json:"on_loan"`
+	OnHold    int64 `json:"on_hold"`
+}
@@ -4,8 +4,11 @@ import "gorm.io/gorm"
 
 type Genre struct {
 	gorm.Model
-	Name  string `json:"name"`
-	Books []Book `gorm:"many2many:book_genres"`
+	Name string `json:"name"`
+	// NormalizedName is the lowercased, trimmed name used to enforce case-insensitive uniqueness
+	NormalizedName string `gorm:"type:varchar(100);uniqueIndex:idx_genres_normalized_name,where:deleted_at IS NULL" json:"-"`
+	Slug           string `gorm:"type:varchar(120);uniqueIndex:idx_genres_slug,where:deleted_at IS NULL" json:"slug"`
+	Books          []Book `gorm:"many2many:book_genres"`
 }
 
 type GenreCreateRequest struct {
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HoldStatus string
+
+const (
+	HoldWaiting   HoldStatus = "waiting"   // in the queue for the next returned copy
+	HoldReady     HoldStatus = "ready"     // a copy is set aside for pickup until ExpiresAt
+	HoldFulfilled HoldStatus = "fulfilled" // the patron checked out a copy
+	HoldCancelled HoldStatus = "cancelled"
+	HoldExpired   HoldStatus = "expired" // the copy was not picked up in time
+)
+
+// Hold queues a user for a book, holds are served first come first served (by ID).
+// A hold is open until ClosedAt is set, the partial unique index allows a single open hold per user and book.
+type Hold struct {
+	gorm.Model
+	BookID    uint       `gorm:"not null;index;uniqueIndex:idx_holds_open_user_book,where:closed_at IS NULL AND deleted_at IS NULL" json:"book_id"`
+	Book      *Book      `gorm:"foreignKey:BookID" json:"book,omitempty"`
+	UserID    uint       `gorm:"not null;index;uniqueIndex:idx_holds_open_user_book" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Status    HoldStatus `gorm:"type:varchar(20);not null;default:'waiting';index" json:"status"`
+	CopyID    *uint      `gorm:"index" json:"copy_id"` // copy set aside for pickup, once ready
+	Copy      *Copy      `gorm:"foreignKey:CopyID" json:"copy,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"` // pickup deadline
+	ClosedAt  *time.Time `json:"closed_at"`               // fulfilled, cancelled or expired
+	CreatedBy uint       `json:"created_by"`              // user ID of whoever placed the hold
+
+	QueuePosition *int `gorm:"-" json:"queue_position,omitempty"` // 1-based, waiting holds only, filled by the service
+}
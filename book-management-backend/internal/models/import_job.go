@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusPending   ImportStatus = "pending"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed" // the job itself failed, not just some rows
+)
+
+// ImportJob tracks a bulk import processed in the background
+type ImportJob struct {
+	gorm.Model
+	Kind          string             `gorm:"type:varchar(20);not null" json:"kind"`                 // what is imported, e.g. "books"
+	Format        string             `gorm:"type:varchar(20);not null;default:'csv'" json:"format"` // csv, marc21 or marcxml
+	FileName      string             `gorm:"type:varchar(255)" json:"file_name"`
+	Status        ImportStatus       `gorm:"type:varchar(20);not null;index" json:"status"`
+	Header        []string           `gorm:"type:jsonb;serializer:json" json:"header"`  // columns of the source values, reused by the error report
+	Mapping       []ImportFieldUsage `gorm:"type:jsonb;serializer:json" json:"mapping"` // MARC imports only
+	TotalRows     int                `json:"total_rows"`
+	ProcessedRows int                `json:"processed_rows"`
+	CreatedRows   int                `json:"created_rows"`
+	FailedRows    int                `json:"failed_rows"`
+	Error         string             `gorm:"type:text" json:"error"` // why the job failed as a whole
+	CreatedBy     uint               `json:"created_by"`             // user ID
+	StartedAt     *time.Time         `json:"started_at"`
+	FinishedAt    *time.Time         `json:"finished_at"`
+}
+
+// ImportFieldUsage is one line of the mapping report of an import: how often a source field
+// (e.g. "245$a") was found and what it was imported as, Target is empty for ignored fields
+type ImportFieldUsage struct {
+	Field  string `json:"field"`
+	Target string `json:"target"`
+	Count  int    `json:"count"`
+}
+
+// ImportRowError is one line of the error report of an import job
+type ImportRowError struct {
+	ID          uint     `gorm:"primarykey" json:"id"`
+	ImportJobID uint     `gorm:"not null;index" json:"import_job_id"`
+	Line        int      `gorm:"not null" json:"line"` // CSV: line in the file (the header is line 1), MARC: record number
+	Message     string   `gorm:"type:text;not null" json:"message"`
+	Values      []string `gorm:"type:jsonb;serializer:json" json:"values"` // source values of the row, see ImportJob.Header
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loan lends a copy to a user, it is open until ReturnedAt is set.
+// The partial unique index allows a single open loan per copy, so a copy can never be lent twice.
+type Loan struct {
+	gorm.Model
+	CopyID       uint       `gorm:"not null;index;uniqueIndex:idx_loans_open_copy,where:returned_at IS NULL AND deleted_at IS NULL" json:"copy_id"`
+	Copy         *Copy      `gorm:"foreignKey:CopyID" json:"copy,omitempty"`
+	BookID       uint       `gorm:"not null;index" json:"book_id"` // book of the copy, kept for listings
+	Book         *Book      `gorm:"foreignKey:BookID" json:"book,omitempty"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	User         *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CheckedOutAt time.Time  `gorm:"not null" json:"checked_out_at"`
+	DueAt        time.Time  `gorm:"not null;index" json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at"`
+	CheckedOutBy uint       `json:"checked_out_by"` // user ID of whoever made the checkout, the borrower for self-checkout
+	ReturnedBy   *uint      `json:"returned_by"`
+
+	RenewalCount int                `gorm:"not null;default:0" json:"renewal_count"`
+	PolicyID     *uint              `json:"policy_id"` // circulation policy applied at checkout or last renewal, nil for the default rules
+	Policy       *CirculationPolicy `gorm:"foreignKey:PolicyID;constraint:OnDelete:SET NULL" json:"policy,omitempty"`
+}
+
+// Overdue tells whether the loan is still open past its due date
+func (l *Loan) Overdue(now time.Time) bool {
+	return l.ReturnedAt == nil && now.After(l.DueAt)
+}
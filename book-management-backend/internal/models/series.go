@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// Series groups books that are meant to be read in order (e.g. "Discworld")
+type Series struct {
+	gorm.Model
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Books       []Book `json:"books"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag is a free-form label any user can put on a book (e.g. "signed copy"), unlike curated genres
+type Tag struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(50);not null" json:"name"`
+	// NormalizedName is the lowercased, trimmed name used for uniqueness and filtering
+	NormalizedName string `gorm:"type:varchar(50);not null;uniqueIndex" json:"-"`
+	Books          []Book `gorm:"many2many:book_tags" json:"books,omitempty"`
+}
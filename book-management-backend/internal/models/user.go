@@ -15,4 +15,7 @@ type User struct {
 	PasswordHash string `gorm:"type:varchar(255);not null" json:"-"`
 	Role         Role   `gorm:"type:varchar(20);not null" json:"role"`
 	RefreshToken string `gorm:"type:text" json:"-"` // store refresh token
+
+	// PatronCategory groups borrowers for circulation policies, e.g. "student" or "staff"
+	PatronCategory string `gorm:"type:varchar(30);not null;default:''" json:"patron_category"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// Work is the abstract creation (e.g. "Dune"), grouping its editions (printings, translations, formats)
+type Work struct {
+	gorm.Model
+	Title       string  `gorm:"type:varchar(255);not null" json:"title"`
+	Description string  `gorm:"type:text" json:"description"`
+	Genres      []Genre `gorm:"many2many:work_genres" json:"genres"`
+	Editions    []Book  `gorm:"foreignKey:WorkID" json:"editions"`
+}
@@ -6,10 +6,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// AuthorIncludes maps the relations an author list can embed to their GORM associations
+var AuthorIncludes = map[string]string{
+	"books": "Books",
+}
+
 type IAuthorRepository interface {
 	GetAuthorByID(db *gorm.DB, authorID uint) (*models.Author, error)
-	GetAuthors(db *gorm.DB, limit, offset int) ([]models.Author, error) // Pagination
+	FindAuthorByID(db *gorm.DB, authorID uint) (*models.Author, error)                                  // books are not loaded
+	GetAuthors(db *gorm.DB, limit, offset int, afterID uint, include []string) ([]models.Author, error) // Pagination, afterID > 0 switches to keyset mode
+	CountAuthors(db *gorm.DB) (int64, error)
 	GetAuthorByEmail(db *gorm.DB, email string) (*models.Author, error)
+	GetAuthorByName(db *gorm.DB, name string) (*models.Author, error) // case-insensitive, books are not loaded
 	CreateAuthor(db *gorm.DB, author *models.Author) error
 	UpdateAuthor(db *gorm.DB, author *models.Author) error
 	DeleteAuthor(db *gorm.DB, author *models.Author) error
@@ -25,18 +33,37 @@ func (a *authorRepository) GetAuthorByID(db *gorm.DB, authorID uint) (*models.Au
 	return &author, nil
 }
 
-// Pagination
-func (a *authorRepository) GetAuthors(db *gorm.DB, limit, offset int) ([]models.Author, error) {
+func (a *authorRepository) FindAuthorByID(db *gorm.DB, authorID uint) (*models.Author, error) {
+	var author models.Author
+	if err := db.First(&author, authorID).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// Pagination, ordered by id so that pages are stable
+func (a *authorRepository) GetAuthors(db *gorm.DB, limit, offset int, afterID uint, include []string) ([]models.Author, error) {
 	var authors []models.Author
-	if err := db.Preload("Books").
-		Limit(limit).
-		Offset(offset).
-		Find(&authors).Error; err != nil {
+	query := preloadIncludes(db, include, AuthorIncludes).Order("id ASC").Limit(limit)
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	} else {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&authors).Error; err != nil {
 		return nil, err
 	}
 	return authors, nil
 }
 
+func (a *authorRepository) CountAuthors(db *gorm.DB) (int64, error) {
+	var total int64
+	if err := db.Model(&models.Author{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
 func (a *authorRepository) GetAuthorByEmail(db *gorm.DB, email string) (*models.Author, error) {
 	var author models.Author
 	if err := db.Preload("Books").Where("email = ?", email).First(&author).Error; err != nil {
@@ -45,6 +72,14 @@ func (a *authorRepository) GetAuthorByEmail(db *gorm.DB, email string) (*models.
 	return &author, nil
 }
 
+func (a *authorRepository) GetAuthorByName(db *gorm.DB, name string) (*models.Author, error) {
+	var author models.Author
+	if err := db.Where("LOWER(name) = LOWER(?)", name).Order("id ASC").First(&author).Error; err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
 func (a *authorRepository) CreateAuthor(db *gorm.DB, author *models.Author) error {
 	return db.Create(author).Error
 }
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type IBookFileRepository interface {
+	GetFilesByBookID(db *gorm.DB, bookID uint) ([]models.BookFile, error)
+	GetFilesByBookIDs(db *gorm.DB, bookIDs []uint) ([]models.BookFile, error)
+	GetFileByID(db *gorm.DB, fileID uint) (*models.BookFile, error)
+	CreateFile(db *gorm.DB, file *models.BookFile) (*models.BookFile, error)
+	DeleteFile(db *gorm.DB, file *models.BookFile) error
+	DeleteFilesByBookID(db *gorm.DB, bookID uint) error
+	IncrementDownloadCount(db *gorm.DB, fileID uint) error
+}
+
+type bookFileRepository struct{}
+
+// GetFilesByBookID lists the files of a book, samples first then in upload order
+func (r *bookFileRepository) GetFilesByBookID(db *gorm.DB, bookID uint) ([]models.BookFile, error) {
+	var files []models.BookFile
+	err := db.Where("book_id = ?", bookID).
+		Order("kind = 'sample' DESC, id").
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// GetFilesByBookIDs lists the files of several books, in the order of GetFilesByBookID within a book
+func (r *bookFileRepository) GetFilesByBookIDs(db *gorm.DB, bookIDs []uint) ([]models.BookFile, error) {
+	var files []models.BookFile
+	if len(bookIDs) == 0 {
+		return files, nil
+	}
+	err := db.Where("book_id IN ?", bookIDs).
+		Order("book_id, kind = 'sample' DESC, id").
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *bookFileRepository) GetFileByID(db *gorm.DB, fileID uint) (*models.BookFile, error) {
+	var file models.BookFile
+	if err := db.First(&file, fileID).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *bookFileRepository) CreateFile(db *gorm.DB, file *models.BookFile) (*models.BookFile, error) {
+	if err := db.Create(file).Error; err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (r *bookFileRepository) DeleteFile(db *gorm.DB, file *models.BookFile) error {
+	return db.Delete(file).Error
+}
+
+func (r *bookFileRepository) DeleteFilesByBookID(db *gorm.DB, bookID uint) error {
+	return db.Where("book_id = ?", bookID).Delete(&models.BookFile{}).Error
+}
+
+// IncrementDownloadCount counts one download atomically, updated_at is left alone
+func (r *bookFileRepository) IncrementDownloadCount(db *gorm.DB, fileID uint) error {
+	return db.Model(&models.BookFile{}).
+		Where("id = ?", fileID).
+		UpdateColumn("download_count", gorm.Expr("download_count + 1")).Error
+}
+
+func NewBookFileRepository() IBookFileRepository {
+	return &bookFileRepository{}
+}
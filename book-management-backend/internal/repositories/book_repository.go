@@ -2,16 +2,124 @@ package repositories
 
 import (
 	"book-management/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// BookFilter narrows down GetAllBooks, zero values mean "no filter"
+type BookFilter struct {
+	IDs           []uint     // only these books
+	Title         string     // case-insensitive substring of the title
+	AuthorID      *uint      // exact author
+	GenreIDs      []uint     // books in any (or all, see GenreMatchAll) of these genres
+	GenreMatchAll bool       // true: a book must belong to every genre in GenreIDs
+	CreatedFrom   *time.Time // inclusive
+	CreatedTo     *time.Time // exclusive
+	Language      string     // exact language code
+	Publisher     string     // case-insensitive substring of the publisher
+	Tags          []string   // normalized tag names, a book must carry all of them
+	Sort          []SortField
+	After         *Keyset // keyset pagination, Sort then holds at most one field from BookKeysetSortKeys
+}
+
+// Keyset points at the last row of the previous page, the next page starts strictly after it
+type Keyset struct {
+	Value any // value of the sort column, ignored when sorting by id
+	ID    uint
+}
+
+// SortField is one entry of a multi-field sort, Key must be one of BookSortColumns
+type SortField struct {
+	Key  string
+	Desc bool
+}
+
+// BookSortColumns whitelists the sort keys accepted by GetAllBooks
+var BookSortColumns = map[string]string{
+	"id":               "books.id",
+	"title":            "books.title",
+	"created_at":       "books.created_at",
+	"updated_at":       "books.updated_at",
+	"publication_year": "books.publication_year",
+	"author_id":        "books.author_id",
+}
+
+// BookIncludes maps the relations a book list can embed to their GORM associations
+var BookIncludes = map[string]string{
+	"author": "Author",
+	"genres": "Genres",
+	"series": "Series",
+	"tags":   "Tags",
+}
+
+// BookKeysetSortKeys are the sort keys usable with cursors, publication_year is left out because it is nullable
+var BookKeysetSortKeys = []string{"id", "title", "created_at", "updated_at", "author_id"}
+
+// Facet names accepted by GetBookFacets
+const (
+	FacetGenre    = "genre"
+	FacetAuthor   = "author"
+	FacetLanguage = "language"
+	FacetDecade   = "decade" // publication decade, e.g. "1990"
+)
+
+// bookFacetQueries holds one SELECT per facet, each reading from the "matched" CTE
+var bookFacetQueries = map[string]string{
+	FacetGenre: `SELECT 'genre' AS facet, genres.id::text AS value, genres.name AS label, COUNT(*) AS count
+		FROM matched
+		JOIN book_genres ON book_genres.book_id = matched.id
+		JOIN genres ON genres.id = book_genres.genre_id AND genres.deleted_at IS NULL
+		GROUP BY genres.id, genres.name`,
+	FacetAuthor: `SELECT 'author' AS facet, authors.id::text AS value, authors.name AS label, COUNT(*) AS count
+		FROM matched
+		JOIN authors ON authors.id = matched.author_id AND authors.deleted_at IS NULL
+		GROUP BY authors.id, authors.name`,
+	FacetLanguage: `SELECT 'language' AS facet, matched.language AS value, matched.language AS label, COUNT(*) AS count
+		FROM matched
+		WHERE matched.language <> ''
+		GROUP BY matched.language`,
+	FacetDecade: `SELECT 'decade' AS facet, (matched.publication_year / 10 * 10)::text AS value,
+			(matched.publication_year / 10 * 10)::text || 's' AS label, COUNT(*) AS count
+		FROM matched
+		WHERE matched.publication_year IS NOT NULL
+		GROUP BY matched.publication_year / 10 * 10`,
+}
+
+// BookFacetNames lists the facets in the order they are computed
+var BookFacetNames = []string{FacetGenre, FacetAuthor, FacetLanguage, FacetDecade}
+
+// FacetCount is the number of matching books for one value of a facet
+type FacetCount struct {
+	Facet string
+	Value string
+	Label string
+	Count int64
+}
+
 type IBookRepository interface {
 	GetBookById(db *gorm.DB, bookId uint) (*models.Book, error)
-	GetAllBooks(db *gorm.DB, limit, offset uint) (*[]models.Book, error)
+	GetAllBooks(db *gorm.DB, limit, offset uint, filter BookFilter, include []string) (*[]models.Book, error) // include: keys of BookIncludes
+	CountBooks(db *gorm.DB, filter BookFilter) (int64, error)
+	GetBookFacets(db *gorm.DB, filter BookFilter, facets []string) ([]FacetCount, error)
 	CreateBook(db *gorm.DB, book *models.Book) (*models.Book, error)
 	UpdateBook(db *gorm.DB, book *models.Book) (*models.Book, error)
 	DeleteBook(db *gorm.DB, book *models.Book) error
+	UpdateBookImage(db *gorm.DB, book *models.Book) error
+	CountBooksByImage(db *gorm.DB, publicID, image string, excludeID uint) (int64, error)
+	CountBooksByISBN(db *gorm.DB, isbn string) (int64, error)
+	GetBooksByIds(db *gorm.DB, ids []uint) ([]models.Book, error)
+	GetSitemapEntries(db *gorm.DB, limit, offset int) ([]BookSitemapEntry, error)
+}
+
+// BookSitemapEntry is the little a sitemap needs to know about a book
+type BookSitemapEntry struct {
+	ID        uint
+	UpdatedAt time.Time
 }
 
 type bookRepository struct{}
@@ -19,7 +127,7 @@ type bookRepository struct{}
 func (b *bookRepository) GetBookById(db *gorm.DB, bookId uint) (*models.Book, error) {
 	var book models.Book
 
-	result := db.Preload("Author").First(&book, bookId) // result is *gorm.DB
+	result := db.Preload("Author").Preload("Series").Preload("Tags").First(&book, bookId) // result is *gorm.DB
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -27,13 +135,14 @@ func (b *bookRepository) GetBookById(db *gorm.DB, bookId uint) (*models.Book, er
 	return &book, nil
 }
 
-func (b *bookRepository) GetAllBooks(db *gorm.DB, limit, offset uint) (*[]models.Book, error) {
+func (b *bookRepository) GetAllBooks(db *gorm.DB, limit, offset uint, filter BookFilter, include []string) (*[]models.Book, error) {
 	var books []models.Book
 
-	result := db.Limit(int(limit)).
+	query := applyBookSort(applyBookKeyset(applyBookFilter(db.Model(&models.Book{}), filter), filter), filter.Sort)
+
+	result := preloadIncludes(query, include, BookIncludes).
+		Limit(int(limit)).
 		Offset(int(offset)).
-		Preload("Author").
-		Preload("Genres").
 		Find(&books)
 
 	if result.Error != nil {
@@ -43,6 +152,143 @@ func (b *bookRepository) GetAllBooks(db *gorm.DB, limit, offset uint) (*[]models
 	return &books, nil
 }
 
+// CountBooks counts all books matching filter, ignoring pagination
+func (b *bookRepository) CountBooks(db *gorm.DB, filter BookFilter) (int64, error) {
+	var total int64
+	if err := applyBookFilter(db.Model(&models.Book{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetBookFacets counts the books matching filter per value of each requested facet, in a single query
+func (b *bookRepository) GetBookFacets(db *gorm.DB, filter BookFilter, facets []string) ([]FacetCount, error) {
+	var selects []string
+	for _, name := range BookFacetNames {
+		if slices.Contains(facets, name) {
+			selects = append(selects, bookFacetQueries[name])
+		}
+	}
+	if len(selects) == 0 {
+		return []FacetCount{}, nil
+	}
+
+	matched := applyBookFilter(db.Session(&gorm.Session{NewDB: true}).Model(&models.Book{}), filter).
+		Select("books.id, books.author_id, books.language, books.publication_year")
+
+	var counts []FacetCount
+	err := db.Raw("WITH matched AS (?) "+strings.Join(selects, " UNION ALL ")+" ORDER BY facet, count DESC, label", matched).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// applyBookFilter adds the WHERE conditions of a BookFilter to a query on books
+func applyBookFilter(db *gorm.DB, filter BookFilter) *gorm.DB {
+	if len(filter.IDs) > 0 {
+		db = db.Where("books.id IN ?", filter.IDs)
+	}
+	if filter.Title != "" {
+		db = db.Where("books.title ILIKE ?", "%"+escapeLike(filter.Title)+"%")
+	}
+	if filter.AuthorID != nil {
+		db = db.Where("books.author_id = ?", *filter.AuthorID)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("books.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("books.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.Language != "" {
+		db = db.Where("books.language = ?", filter.Language)
+	}
+	if filter.Publisher != "" {
+		db = db.Where("books.publisher ILIKE ?", "%"+escapeLike(filter.Publisher)+"%")
+	}
+
+	if len(filter.GenreIDs) > 0 {
+		genreBooks := db.Session(&gorm.Session{NewDB: true}).
+			Table("book_genres").
+			Select("book_genres.book_id").
+			Where("book_genres.genre_id IN ?", filter.GenreIDs)
+		if filter.GenreMatchAll {
+			genreBooks = genreBooks.
+				Group("book_genres.book_id").
+				Having("COUNT(DISTINCT book_genres.genre_id) = ?", len(filter.GenreIDs))
+		}
+		db = db.Where("books.id IN (?)", genreBooks)
+	}
+
+	if len(filter.Tags) > 0 {
+		// Books having every requested tag
+		taggedBooks := db.Session(&gorm.Session{NewDB: true}).
+			Table("book_tags").
+			Select("book_tags.book_id").
+			Joins("JOIN tags ON tags.id = book_tags.tag_id").
+			Where("tags.normalized_name IN ?", filter.Tags).
+			Group("book_tags.book_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		db = db.Where("books.id IN (?)", taggedBooks)
+	}
+
+	return db
+}
+
+// applyBookKeyset keeps the rows strictly after filter.After in (sort key, id) order
+func applyBookKeyset(db *gorm.DB, filter BookFilter) *gorm.DB {
+	if filter.After == nil {
+		return db
+	}
+
+	key, op := "id", ">"
+	if len(filter.Sort) > 0 {
+		key = filter.Sort[0].Key
+		if filter.Sort[0].Desc {
+			op = "<"
+		}
+	}
+
+	if key == "id" {
+		return db.Where("books.id "+op+" ?", filter.After.ID)
+	}
+	return db.Where(fmt.Sprintf("(%s, books.id) %s (?, ?)", BookSortColumns[key], op), filter.After.Value, filter.After.ID)
+}
+
+// applyBookSort orders by the whitelisted sort fields, always ending with books.id for a stable order.
+// The books.id tiebreak follows the direction of the last field so (sort key, id) can be used as a keyset.
+func applyBookSort(db *gorm.DB, sort []SortField) *gorm.DB {
+	hasID := false
+	idDesc := false
+	for _, f := range sort {
+		column, ok := BookSortColumns[f.Key]
+		if !ok {
+			continue
+		}
+		if f.Key == "id" {
+			hasID = true
+		}
+		idDesc = f.Desc
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}, Desc: f.Desc})
+	}
+	if !hasID {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "books.id", Raw: true}, Desc: idDesc})
+	}
+	return db
+}
+
+// preloadIncludes preloads only the requested relations, unknown names are ignored
+func preloadIncludes(db *gorm.DB, include []string, associations map[string]string) *gorm.DB {
+	for _, name := range include {
+		if association, ok := associations[name]; ok {
+			db = db.Preload(association)
+		}
+	}
+	return db
+}
+
 func (b *bookRepository) CreateBook(db *gorm.DB, book *models.Book) (*models.Book, error) {
 	result := db.Create(book)
 	if result.Error != nil {
@@ -64,6 +310,61 @@ func (b *bookRepository) DeleteBook(db *gorm.DB, book *models.Book) error {
 	return db.Delete(book).Error
 }
 
+// UpdateBookImage writes only the cover columns, clearing them when they are empty
+func (b *bookRepository) UpdateBookImage(db *gorm.DB, book *models.Book) error {
+	return db.Model(book).Select("image", "image_public_id", "image_variants").Updates(book).Error
+}
+
+// CountBooksByISBN counts the books with an ISBN, the unique index still guards concurrent inserts
+func (b *bookRepository) CountBooksByISBN(db *gorm.DB, isbn string) (int64, error) {
+	var count int64
+	if err := db.Model(&models.Book{}).Where("books.isbn = ?", isbn).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountBooksByImage counts the other books still using a stored cover, by public ID or by URL
+func (b *bookRepository) CountBooksByImage(db *gorm.DB, publicID, image string, excludeID uint) (int64, error) {
+	var count int64
+	query := db.Model(&models.Book{}).Where("books.id <> ?", excludeID)
+	switch {
+	case publicID != "" && image != "":
+		query = query.Where("books.image_public_id = ? OR books.image = ?", publicID, image)
+	case publicID != "":
+		query = query.Where("books.image_public_id = ?", publicID)
+	default:
+		query = query.Where("books.image = ?", image)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (b *bookRepository) GetBooksByIds(db *gorm.DB, ids []uint) ([]models.Book, error) {
+	var books []models.Book
+	if err := db.Where("id IN ?", ids).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// GetSitemapEntries lists books by id with their last change, without loading the rows
+func (b *bookRepository) GetSitemapEntries(db *gorm.DB, limit, offset int) ([]BookSitemapEntry, error) {
+	var entries []BookSitemapEntry
+	err := db.Model(&models.Book{}).
+		Select("id, updated_at").
+		Order("id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func NewBookRepository() IBookRepository {
 	return &bookRepository{}
 }
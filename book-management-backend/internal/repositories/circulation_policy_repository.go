@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ICirculationPolicyRepository interface {
+	GetAllPolicies(db *gorm.DB) ([]models.CirculationPolicy, error)
+	GetPolicyByID(db *gorm.DB, policyID uint) (*models.CirculationPolicy, error)
+	CreatePolicy(db *gorm.DB, policy *models.CirculationPolicy) (*models.CirculationPolicy, error)
+	UpdatePolicy(db *gorm.DB, policy *models.CirculationPolicy) (*models.CirculationPolicy, error)
+	DeletePolicy(db *gorm.DB, policy *models.CirculationPolicy) error
+}
+
+type circulationPolicyRepository struct{}
+
+// GetAllPolicies returns every policy ordered by id, there are only a handful of them
+func (r *circulationPolicyRepository) GetAllPolicies(db *gorm.DB) ([]models.CirculationPolicy, error) {
+	var policies []models.CirculationPolicy
+	if err := db.Order("id").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *circulationPolicyRepository) GetPolicyByID(db *gorm.DB, policyID uint) (*models.CirculationPolicy, error) {
+	var policy models.CirculationPolicy
+	if err := db.First(&policy, policyID).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *circulationPolicyRepository) CreatePolicy(db *gorm.DB, policy *models.CirculationPolicy) (*models.CirculationPolicy, error) {
+	if err := db.Create(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (r *circulationPolicyRepository) UpdatePolicy(db *gorm.DB, policy *models.CirculationPolicy) (*models.CirculationPolicy, error) {
+	if err := db.Save(policy).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (r *circulationPolicyRepository) DeletePolicy(db *gorm.DB, policy *models.CirculationPolicy) error {
+	return db.Delete(policy).Error
+}
+
+func NewCirculationPolicyRepository() ICirculationPolicyRepository {
+	return &circulationPolicyRepository{}
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICopyRepository interface {
+	GetCopiesByBookID(db *gorm.DB, bookID uint) ([]models.Copy, error)
+	GetCopyByID(db *gorm.DB, copyID uint) (*models.Copy, error)
+	GetCopyByBarcode(db *gorm.DB, barcode string) (*models.Copy, error)
+	// LockCopy and LockCopyByBarcode load a copy with SELECT ... FOR UPDATE, to be used inside a transaction
+	LockCopy(db *gorm.DB, copyID uint) (*models.Copy, error)
+	LockCopyByBarcode(db *gorm.DB, barcode string) (*models.Copy, error)
+	// LockCopiesByBookID locks every copy of a book in id order, so no copy changes hands until the transaction ends
+	LockCopiesByBookID(db *gorm.DB, bookID uint) ([]models.Copy, error)
+	UpdateCopyStatus(db *gorm.DB, copyID uint, status models.CopyStatus) error
+	CreateCopy(db *gorm.DB, bookCopy *models.Copy) (*models.Copy, error)
+	// UpdateCopy saves every column but the status, which only changes through UpdateCopyStatus
+	UpdateCopy(db *gorm.DB, bookCopy *models.Copy) (*models.Copy, error)
+	DeleteCopy(db *gorm.DB, bookCopy *models.Copy) error
+	// GetAvailability counts the copies of each book by status, books without copies are left out
+	GetAvailability(db *gorm.DB, bookIDs []uint) (map[uint]models.CopyAvailability, error)
+}
+
+type copyRepository struct{}
+
+// GetCopiesByBookID lists the copies of a book ordered by barcode
+func (r *copyRepository) GetCopiesByBookID(db *gorm.DB, bookID uint) ([]models.Copy, error) {
+	var copies []models.Copy
+	if err := db.Where("book_id = ?", bookID).Order("barcode").Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+func (r *copyRepository) GetCopyByID(db *gorm.DB, copyID uint) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := db.First(&bookCopy, copyID).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *copyRepository) GetCopyByBarcode(db *gorm.DB, barcode string) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := db.Where("barcode = ?", barcode).First(&bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *copyRepository) LockCopy(db *gorm.DB, copyID uint) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, copyID).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *copyRepository) LockCopyByBarcode(db *gorm.DB, barcode string) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("barcode = ?", barcode).First(&bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *copyRepository) LockCopiesByBookID(db *gorm.DB, bookID uint) ([]models.Copy, error) {
+	var copies []models.Copy
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).Order("id").Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	return copies, nil
+}
+
+func (r *copyRepository) UpdateCopyStatus(db *gorm.DB, copyID uint, status models.CopyStatus) error {
+	return db.Model(&models.Copy{}).Where("id = ?", copyID).Update("status", status).Error
+}
+
+func (r *copyRepository) CreateCopy(db *gorm.DB, bookCopy *models.Copy) (*models.Copy, error) {
+	if err := db.Create(bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+func (r *copyRepository) UpdateCopy(db *gorm.DB, bookCopy *models.Copy) (*models.Copy, error) {
+	if err := db.Omit("Book", "Status").Save(bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return bookCopy, nil
+}
+
+func (r *copyRepository) DeleteCopy(db *gorm.DB, bookCopy *models.Copy) error {
+	return db.Delete(bookCopy).Error
+}
+
+func (r *copyRepository) GetAvailability(db *gorm.DB, bookIDs []uint) (map[uint]models.CopyAvailability, error) {
+	availability := make(map[uint]models.CopyAvailability, len(bookIDs))
+	if len(bookIDs) == 0 {
+		return availability, nil
+	}
+
+	var rows []struct {
+		BookID uint
+		Status models.CopyStatus
+		Count  int64
+	}
+	err := db.Model(&models.Copy{}).
+		Select("book_id, status, COUNT(*) AS count").
+		Where("book_id IN ?", bookIDs).
+		Group("book_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		a := availability[row.BookID]
+		a.Total += row.Count
+		switch row.Status {
+		case models.CopyAvailable:
+			a.Available += row.Count
+		case models.CopyOnLoan:
+			a.OnLoan += row.Count
+		case models.CopyOnHold:
+			a.OnHold += row.Count
+		}
+		availability[row.BookID] = a
+	}
+	return availability, nil
+}
+
+func NewCopyRepository() ICopyRepository {
+	return &copyRepository{}
+}
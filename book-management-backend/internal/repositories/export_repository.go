@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookExportRow is one flattened book of a catalogue export
+type BookExportRow struct {
+	ID              uint
+	Title           string
+	Description     string
+	ISBN            string
+	AuthorID        uint
+	AuthorName      string
+	GenreNames      []string `gorm:"serializer:json"` // sorted by name
+	TagNames        []string `gorm:"serializer:json"`
+	SeriesName      string
+	SeriesPosition  *float64
+	Publisher       string
+	Language        string
+	Format          string
+	PublicationYear *int
+	Image           string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// NamedExportRow is one author or genre of a catalogue export
+type NamedExportRow struct {
+	ID        uint
+	Name      string
+	Email     string // authors only
+	Slug      string // genres only
+	BookCount int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type IExportRepository interface {
+	// The Stream* methods call fn for each row while reading the result set, rows are never all held in memory
+	StreamBooks(db *gorm.DB, filter BookFilter, fn func(*BookExportRow) error) error
+	GetBookRow(db *gorm.DB, bookId uint) (*BookExportRow, error)
+	StreamAuthors(db *gorm.DB, fn func(*NamedExportRow) error) error
+	StreamGenres(db *gorm.DB, fn func(*NamedExportRow) error) error
+}
+
+type ExportRepository struct{}
+
+// StreamBooks reads the books matching filter (keyset and pagination are ignored) in the filter's sort order
+func (r *ExportRepository) StreamBooks(db *gorm.DB, filter BookFilter, fn func(*BookExportRow) error) error {
+	query := applyBookSort(applyBookFilter(bookExportQuery(db), filter), filter.Sort)
+	return streamRows(db, query, fn)
+}
+
+func (r *ExportRepository) GetBookRow(db *gorm.DB, bookId uint) (*BookExportRow, error) {
+	var row BookExportRow
+	if err := bookExportQuery(db).Where("books.id = ?", bookId).Take(&row).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// bookExportQuery selects books flattened with their author, series, genre and tag names
+func bookExportQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Book{}).
+		Select(`books.id, books.title, books.description, books.isbn, books.author_id,
+			authors.name AS author_name,
+			COALESCE((SELECT json_agg(genres.name ORDER BY genres.name) FROM book_genres
+				JOIN genres ON genres.id = book_genres.genre_id AND genres.deleted_at IS NULL
+				WHERE book_genres.book_id = books.id), '[]') AS genre_names,
+			COALESCE((SELECT json_agg(tags.name ORDER BY tags.name) FROM book_tags
+				JOIN tags ON tags.id = book_tags.tag_id AND tags.deleted_at IS NULL
+				WHERE book_tags.book_id = books.id), '[]') AS tag_names,
+			series.name AS series_name, books.series_position,
+			books.publisher, books.language, books.format, books.publication_year, books.image,
+			books.created_at, books.updated_at`).
+		Joins("LEFT JOIN authors ON authors.id = books.author_id").
+		Joins("LEFT JOIN series ON series.id = books.series_id AND series.deleted_at IS NULL")
+}
+
+func (r *ExportRepository) StreamAuthors(db *gorm.DB, fn func(*NamedExportRow) error) error {
+	query := db.Model(&models.Author{}).
+		Select(`authors.id, authors.name, authors.email,
+			(SELECT COUNT(*) FROM books WHERE books.author_id = authors.id AND books.deleted_at IS NULL) AS book_count,
+			authors.created_at, authors.updated_at`).
+		Order("authors.id ASC")
+
+	return streamRows(db, query, fn)
+}
+
+func (r *ExportRepository) StreamGenres(db *gorm.DB, fn func(*NamedExportRow) error) error {
+	query := db.Model(&models.Genre{}).
+		Select(`genres.id, genres.name, genres.slug,
+			(SELECT COUNT(*) FROM book_genres
+				JOIN books ON books.id = book_genres.book_id AND books.deleted_at IS NULL
+				WHERE book_genres.genre_id = genres.id) AS book_count,
+			genres.created_at, genres.updated_at`).
+		Order("genres.id ASC")
+
+	return streamRows(db, query, fn)
+}
+
+// streamRows scans the rows of query one by one into a T and hands each of them to fn
+func streamRows[T any](db *gorm.DB, query *gorm.DB, fn func(*T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func NewExportRepository() IExportRepository {
+	return &ExportRepository{}
+}
@@ -6,9 +6,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// GenreIncludes maps the relations a genre list can embed to their GORM associations
+var GenreIncludes = map[string]string{
+	"books": "Books",
+}
+
 type IGenreRepository interface {
 	GetGenreByID(db *gorm.DB, id uint) (*models.Genre, error)
-	GetAllGenres(db *gorm.DB, limit, offset uint) (*[]models.Genre, error)
+	FindGenreByID(db *gorm.DB, id uint) (*models.Genre, error) // books are not loaded
+	GetGenreBySlug(db *gorm.DB, slug string) (*models.Genre, error)
+	FindGenreBySlug(db *gorm.DB, slug string) (*models.Genre, error) // books are not loaded
+	GetGenreByNormalizedName(db *gorm.DB, normalizedName string) (*models.Genre, error)
+	GetAllGenres(db *gorm.DB, limit, offset, afterID uint, include []string) (*[]models.Genre, error) // afterID > 0 switches to keyset mode
+	CountGenres(db *gorm.DB) (int64, error)
 	CreateGenre(db *gorm.DB, genre *models.Genre) (*models.Genre, error)
 	UpdateGenre(db *gorm.DB, genre *models.Genre) (*models.Genre, error)
 	DeleteGenre(db *gorm.DB, genre *models.Genre) error
@@ -30,14 +40,61 @@ func (r *GenreRepository) GetGenreByID(db *gorm.DB, id uint) (*models.Genre, err
 	return &genre, nil
 }
 
-func (r *GenreRepository) GetAllGenres(db *gorm.DB, limit, offset uint) (*[]models.Genre, error) {
+func (r *GenreRepository) FindGenreByID(db *gorm.DB, id uint) (*models.Genre, error) {
+	var genre models.Genre
+	if err := db.First(&genre, id).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *GenreRepository) GetGenreBySlug(db *gorm.DB, slug string) (*models.Genre, error) {
+	var genre models.Genre
+	if err := db.Preload("Books").Where("slug = ?", slug).First(&genre).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *GenreRepository) FindGenreBySlug(db *gorm.DB, slug string) (*models.Genre, error) {
+	var genre models.Genre
+	if err := db.Where("slug = ?", slug).First(&genre).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+// GetGenreByNormalizedName finds a genre by its normalized (lowercased, trimmed) name
+func (r *GenreRepository) GetGenreByNormalizedName(db *gorm.DB, normalizedName string) (*models.Genre, error) {
+	var genre models.Genre
+	if err := db.Where("normalized_name = ?", normalizedName).First(&genre).Error; err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *GenreRepository) GetAllGenres(db *gorm.DB, limit, offset, afterID uint, include []string) (*[]models.Genre, error) {
 	var genres []models.Genre
-	if err := db.Preload("Books").Limit(int(limit)).Offset(int(offset)).Find(&genres).Error; err != nil {
+	query := preloadIncludes(db, include, GenreIncludes).Order("id ASC").Limit(int(limit))
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	} else {
+		query = query.Offset(int(offset))
+	}
+	if err := query.Find(&genres).Error; err != nil {
 		return nil, err
 	}
 	return &genres, nil
 }
 
+func (r *GenreRepository) CountGenres(db *gorm.DB) (int64, error) {
+	var total int64
+	if err := db.Model(&models.Genre{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
 func (r *GenreRepository) CreateGenre(db *gorm.DB, genre *models.Genre) (*models.Genre, error) {
 	if err := db.Create(genre).Error; err != nil {
 		return nil, err
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HoldStatusOpen is accepted by HoldFilter besides the hold statuses, it matches waiting and ready holds
+const HoldStatusOpen = "open"
+
+// ErrHoldLocked is returned by LockOpenHold when another transaction holds the lock on the hold
+var ErrHoldLocked = errors.New("hold is locked by another transaction")
+
+// HoldFilter narrows down GetHolds, zero values mean "no filter"
+type HoldFilter struct {
+	UserID *uint
+	BookID *uint
+	Status string // HoldStatusOpen or a models.HoldStatus
+}
+
+type IHoldRepository interface {
+	GetHoldByID(db *gorm.DB, holdID uint) (*models.Hold, error)
+	// LockHold loads a hold with SELECT ... FOR UPDATE, to be used inside a transaction
+	LockHold(db *gorm.DB, holdID uint) (*models.Hold, error)
+	GetHolds(db *gorm.DB, filter HoldFilter, limit, offset, afterID uint) ([]models.Hold, error) // afterID > 0 switches to keyset mode
+	CountHolds(db *gorm.DB, filter HoldFilter) (int64, error)
+	// GetOpenHoldsByBook lists the waiting and ready holds on a book in queue order
+	GetOpenHoldsByBook(db *gorm.DB, bookID uint) ([]models.Hold, error)
+	// LockOpenHoldsByBook locks the waiting and ready holds on a book in queue order
+	LockOpenHoldsByBook(db *gorm.DB, bookID uint) ([]models.Hold, error)
+	// LockOpenHold locks the waiting or ready hold of a user on a book without waiting: checkout locks the copy
+	// before the hold while expiry and cancellation lock the hold before the copy, so waiting could deadlock.
+	// It returns ErrHoldLocked when another transaction holds the lock.
+	LockOpenHold(db *gorm.DB, userID, bookID uint) (*models.Hold, error)
+	// LockNextWaitingHold locks the oldest waiting hold on a book
+	LockNextWaitingHold(db *gorm.DB, bookID uint) (*models.Hold, error)
+	// LockExpiredHolds locks the ready holds past their pickup deadline, skipping those locked by another sweep
+	LockExpiredHolds(db *gorm.DB, now time.Time) ([]models.Hold, error)
+	CountWaitingHolds(db *gorm.DB, bookID uint) (int64, error)
+	// GetQueuePositions maps the given waiting holds to their 1-based position in the queue of their book
+	GetQueuePositions(db *gorm.DB, holdIDs []uint) (map[uint]int, error)
+	CreateHold(db *gorm.DB, hold *models.Hold) (*models.Hold, error)
+	UpdateHold(db *gorm.DB, hold *models.Hold) (*models.Hold, error)
+}
+
+type holdRepository struct{}
+
+func (r *holdRepository) GetHoldByID(db *gorm.DB, holdID uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := db.Preload("Copy").Preload("Book").Preload("User").First(&hold, holdID).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) LockHold(db *gorm.DB, holdID uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, holdID).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) GetHolds(db *gorm.DB, filter HoldFilter, limit, offset, afterID uint) ([]models.Hold, error) {
+	var holds []models.Hold
+	query := applyHoldFilter(db.Model(&models.Hold{}), filter).
+		Preload("Copy").Preload("Book").Preload("User").
+		Order("holds.id ASC").
+		Limit(int(limit))
+	if afterID > 0 {
+		query = query.Where("holds.id > ?", afterID)
+	} else {
+		query = query.Offset(int(offset))
+	}
+	if err := query.Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *holdRepository) CountHolds(db *gorm.DB, filter HoldFilter) (int64, error) {
+	var total int64
+	if err := applyHoldFilter(db.Model(&models.Hold{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *holdRepository) GetOpenHoldsByBook(db *gorm.DB, bookID uint) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := db.Preload("Copy").Preload("Book").Preload("User").
+		Where("book_id = ? AND closed_at IS NULL", bookID).
+		Order("id ASC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *holdRepository) LockOpenHoldsByBook(db *gorm.DB, bookID uint) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND closed_at IS NULL", bookID).
+		Order("id ASC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *holdRepository) LockOpenHold(db *gorm.DB, userID, bookID uint) (*models.Hold, error) {
+	var hold models.Hold
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("user_id = ? AND book_id = ? AND closed_at IS NULL", userID, bookID).
+		First(&hold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Skipped rows look missing, tell a locked hold from no hold
+		var count int64
+		if err := db.Model(&models.Hold{}).
+			Where("user_id = ? AND book_id = ? AND closed_at IS NULL", userID, bookID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrHoldLocked
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) LockNextWaitingHold(db *gorm.DB, bookID uint) (*models.Hold, error) {
+	var hold models.Hold
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, models.HoldWaiting).
+		Order("id ASC").
+		First(&hold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) LockExpiredHolds(db *gorm.DB, now time.Time) ([]models.Hold, error) {
+	var holds []models.Hold
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND expires_at < ?", models.HoldReady, now).
+		Order("id ASC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *holdRepository) CountWaitingHolds(db *gorm.DB, bookID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Hold{}).
+		Where("book_id = ? AND status = ?", bookID, models.HoldWaiting).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *holdRepository) GetQueuePositions(db *gorm.DB, holdIDs []uint) (map[uint]int, error) {
+	positions := make(map[uint]int, len(holdIDs))
+	if len(holdIDs) == 0 {
+		return positions, nil
+	}
+
+	var rows []struct {
+		ID       uint
+		Position int
+	}
+	err := db.Model(&models.Hold{}).
+		Select(`holds.id, (
+			SELECT COUNT(*) FROM holds AS ahead
+			WHERE ahead.book_id = holds.book_id AND ahead.status = ? AND ahead.deleted_at IS NULL AND ahead.id <= holds.id
+		) AS position`, models.HoldWaiting).
+		Where("holds.id IN ? AND holds.status = ?", holdIDs, models.HoldWaiting).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		positions[row.ID] = row.Position
+	}
+	return positions, nil
+}
+
+func (r *holdRepository) CreateHold(db *gorm.DB, hold *models.Hold) (*models.Hold, error) {
+	if err := db.Omit(clause.Associations).Create(hold).Error; err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (r *holdRepository) UpdateHold(db *gorm.DB, hold *models.Hold) (*models.Hold, error) {
+	if err := db.Omit(clause.Associations).Save(hold).Error; err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// applyHoldFilter adds the WHERE conditions of a HoldFilter to a query on holds
+func applyHoldFilter(db *gorm.DB, filter HoldFilter) *gorm.DB {
+	if filter.UserID != nil {
+		db = db.Where("holds.user_id = ?", *filter.UserID)
+	}
+	if filter.BookID != nil {
+		db = db.Where("holds.book_id = ?", *filter.BookID)
+	}
+	switch filter.Status {
+	case "":
+	case HoldStatusOpen:
+		db = db.Where("holds.closed_at IS NULL")
+	default:
+		db = db.Where("holds.status = ?", filter.Status)
+	}
+	return db
+}
+
+func NewHoldRepository() IHoldRepository {
+	return &holdRepository{}
+}
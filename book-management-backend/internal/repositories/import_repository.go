@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IImportRepository interface {
+	CreateJob(db *gorm.DB, job *models.ImportJob) error
+	GetJobByID(db *gorm.DB, id uint) (*models.ImportJob, error)
+	UpdateJob(db *gorm.DB, job *models.ImportJob) error
+	// FailUnfinishedJobs marks every pending or running job as failed, returns how many were
+	FailUnfinishedJobs(db *gorm.DB, message string, finishedAt time.Time) (int64, error)
+
+	// Error report
+	AddRowErrors(db *gorm.DB, rowErrors []models.ImportRowError) error
+	GetRowErrors(db *gorm.DB, jobID uint) ([]models.ImportRowError, error)
+}
+
+type ImportRepository struct{}
+
+func (r *ImportRepository) CreateJob(db *gorm.DB, job *models.ImportJob) error {
+	return db.Create(job).Error
+}
+
+func (r *ImportRepository) GetJobByID(db *gorm.DB, id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ImportRepository) UpdateJob(db *gorm.DB, job *models.ImportJob) error {
+	return db.Save(job).Error
+}
+
+func (r *ImportRepository) FailUnfinishedJobs(db *gorm.DB, message string, finishedAt time.Time) (int64, error) {
+	result := db.Model(&models.ImportJob{}).
+		Where("status IN ?", []models.ImportStatus{models.ImportStatusPending, models.ImportStatusRunning}).
+		Updates(map[string]any{"status": models.ImportStatusFailed, "error": message, "finished_at": finishedAt})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ImportRepository) AddRowErrors(db *gorm.DB, rowErrors []models.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(rowErrors, 500).Error
+}
+
+// GetRowErrors returns the errors of a job in file order
+func (r *ImportRepository) GetRowErrors(db *gorm.DB, jobID uint) ([]models.ImportRowError, error) {
+	var rowErrors []models.ImportRowError
+	if err := db.Where("import_job_id = ?", jobID).Order("line ASC").Find(&rowErrors).Error; err != nil {
+		return nil, err
+	}
+	return rowErrors, nil
+}
+
+func NewImportRepository() IImportRepository {
+	return &ImportRepository{}
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Loan statuses accepted by LoanFilter
+const (
+	LoanStatusActive   = "active"   // not returned yet
+	LoanStatusOverdue  = "overdue"  // not returned and past the due date
+	LoanStatusReturned = "returned" // returned
+)
+
+// LoanFilter narrows down GetLoans, zero values mean "no filter"
+type LoanFilter struct {
+	UserID *uint
+	BookID *uint
+	CopyID *uint
+	Status string    // one of the LoanStatus constants
+	Now    time.Time // reference time for LoanStatusOverdue
+}
+
+type ILoanRepository interface {
+	GetLoanByID(db *gorm.DB, loanID uint) (*models.Loan, error)
+	// LockLoan loads a loan with SELECT ... FOR UPDATE, to be used inside a transaction
+	LockLoan(db *gorm.DB, loanID uint) (*models.Loan, error)
+	GetLoans(db *gorm.DB, filter LoanFilter, limit, offset, afterID uint) ([]models.Loan, error) // afterID > 0 switches to keyset mode
+	CountLoans(db *gorm.DB, filter LoanFilter) (int64, error)
+	HasOpenLoan(db *gorm.DB, copyID uint) (bool, error)
+	// CountOpenLoans counts the loans a user has not returned, only of books in format when it is set
+	CountOpenLoans(db *gorm.DB, userID uint, format models.BookFormat) (int64, error)
+	CreateLoan(db *gorm.DB, loan *models.Loan) (*models.Loan, error)
+	UpdateLoan(db *gorm.DB, loan *models.Loan) (*models.Loan, error)
+}
+
+type loanRepository struct{}
+
+func (r *loanRepository) GetLoanByID(db *gorm.DB, loanID uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := db.Preload("Copy").Preload("Book").Preload("User").First(&loan, loanID).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *loanRepository) LockLoan(db *gorm.DB, loanID uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, loanID).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *loanRepository) GetLoans(db *gorm.DB, filter LoanFilter, limit, offset, afterID uint) ([]models.Loan, error) {
+	var loans []models.Loan
+	query := applyLoanFilter(db.Model(&models.Loan{}), filter).
+		Preload("Copy").Preload("Book").Preload("User").
+		Order("loans.id ASC").
+		Limit(int(limit))
+	if afterID > 0 {
+		query = query.Where("loans.id > ?", afterID)
+	} else {
+		query = query.Offset(int(offset))
+	}
+	if err := query.Find(&loans).Error; err != nil {
+		return nil, err
+	}
+	return loans, nil
+}
+
+func (r *loanRepository) CountLoans(db *gorm.DB, filter LoanFilter) (int64, error) {
+	var total int64
+	if err := applyLoanFilter(db.Model(&models.Loan{}), filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *loanRepository) HasOpenLoan(db *gorm.DB, copyID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Loan{}).
+		Where("copy_id = ? AND returned_at IS NULL", copyID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *loanRepository) CountOpenLoans(db *gorm.DB, userID uint, format models.BookFormat) (int64, error) {
+	var count int64
+	query := db.Model(&models.Loan{}).Where("loans.user_id = ? AND loans.returned_at IS NULL", userID)
+	if format != "" {
+		query = query.Joins("JOIN books ON books.id = loans.book_id").Where("books.format = ?", format)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *loanRepository) CreateLoan(db *gorm.DB, loan *models.Loan) (*models.Loan, error) {
+	if err := db.Omit(clause.Associations).Create(loan).Error; err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+func (r *loanRepository) UpdateLoan(db *gorm.DB, loan *models.Loan) (*models.Loan, error) {
+	if err := db.Omit(clause.Associations).Save(loan).Error; err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// applyLoanFilter adds the WHERE conditions of a LoanFilter to a query on loans
+func applyLoanFilter(db *gorm.DB, filter LoanFilter) *gorm.DB {
+	if filter.UserID != nil {
+		db = db.Where("loans.user_id = ?", *filter.UserID)
+	}
+	if filter.BookID != nil {
+		db = db.Where("loans.book_id = ?", *filter.BookID)
+	}
+	if filter.CopyID != nil {
+		db = db.Where("loans.copy_id = ?", *filter.CopyID)
+	}
+	switch filter.Status {
+	case LoanStatusActive:
+		db = db.Where("loans.returned_at IS NULL")
+	case LoanStatusOverdue:
+		db = db.Where("loans.returned_at IS NULL AND loans.due_at < ?", filter.Now)
+	case LoanStatusReturned:
+		db = db.Where("loans.returned_at IS NOT NULL")
+	}
+	return db
+}
+
+func NewLoanRepository() ILoanRepository {
+	return &loanRepository{}
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+// Highlight markers returned inside snippets, the service turns them into HTML once the text is escaped
+const (
+	HighlightStart = "⟪"
+	HighlightStop  = "⟫"
+)
+
+// headlineOptions configures ts_headline for short, highlighted snippets
+const headlineOptions = "StartSel=" + HighlightStart + ", StopSel=" + HighlightStop +
+	", MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// BookSearchHit is one ranked book returned by full-text search
+type BookSearchHit struct {
+	ID         uint
+	Title      string
+	AuthorName string
+	Snippet    string
+	Rank       float64
+}
+
+// NamedSearchHit is one ranked author or genre returned by full-text search
+type NamedSearchHit struct {
+	ID      uint
+	Name    string
+	Snippet string
+	Rank    float64
+}
+
+// BookSuggestion is one fuzzy title match for type-ahead
+type BookSuggestion struct {
+	ID         uint
+	Title      string
+	AuthorName string
+	Score      float64
+}
+
+// AuthorSuggestion is one fuzzy name match for type-ahead
+type AuthorSuggestion struct {
+	ID    uint
+	Name  string
+	Score float64
+}
+
+// suggestThreshold is the minimum pg_trgm word similarity for a suggestion,
+// lower than the 0.6 default so common misspellings ("Tolkein") still match
+const suggestThreshold = "0.3"
+
+type ISearchRepository interface {
+	SearchBooks(db *gorm.DB, q string, limit int) ([]BookSearchHit, error)
+	SearchAuthors(db *gorm.DB, q string, limit int) ([]NamedSearchHit, error)
+	SearchGenres(db *gorm.DB, q string, limit int) ([]NamedSearchHit, error)
+
+	// Typo-tolerant, accent-insensitive type-ahead (pg_trgm + unaccent)
+	SuggestBooks(db *gorm.DB, q string, limit int) ([]BookSuggestion, error)
+	SuggestAuthors(db *gorm.DB, q string, limit int) ([]AuthorSuggestion, error)
+}
+
+type SearchRepository struct{}
+
+// SearchBooks ranks books by their weighted vector (title > author/genres > description)
+func (r *SearchRepository) SearchBooks(db *gorm.DB, q string, limit int) ([]BookSearchHit, error) {
+	var hits []BookSearchHit
+	err := db.Table("books").
+		Select(`books.id, books.title, authors.name AS author_name,
+			ts_headline('simple', coalesce(books.title, '') || ' — ' || coalesce(books.description, ''), query, ?) AS snippet,
+			ts_rank_cd(books.search_vector, query) AS rank`, headlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS query", q).
+		Joins("LEFT JOIN authors ON authors.id = books.author_id").
+		Where("books.deleted_at IS NULL").
+		Where("books.search_vector @@ query").
+		Order("rank DESC").
+		Order("books.id ASC").
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (r *SearchRepository) SearchAuthors(db *gorm.DB, q string, limit int) ([]NamedSearchHit, error) {
+	return searchNamed(db, "authors", q, limit)
+}
+
+func (r *SearchRepository) SearchGenres(db *gorm.DB, q string, limit int) ([]NamedSearchHit, error) {
+	return searchNamed(db, "genres", q, limit)
+}
+
+// searchNamed searches a table whose search_vector is built from its name column
+func searchNamed(db *gorm.DB, table string, q string, limit int) ([]NamedSearchHit, error) {
+	var hits []NamedSearchHit
+	err := db.Table(table).
+		Select(`id, name,
+			ts_headline('simple', coalesce(name, ''), query, ?) AS snippet,
+			ts_rank_cd(search_vector, query) AS rank`, headlineOptions).
+		Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS query", q).
+		Where("deleted_at IS NULL").
+		Where("search_vector @@ query").
+		Order("rank DESC").
+		Order("id ASC").
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// SuggestBooks returns the titles closest to q, the <% operator is served by idx_books_title_trgm
+func (r *SearchRepository) SuggestBooks(db *gorm.DB, q string, limit int) ([]BookSuggestion, error) {
+	var suggestions []BookSuggestion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := setWordSimilarityThreshold(tx); err != nil {
+			return err
+		}
+		return tx.Table("books").
+			Select(`books.id, books.title, authors.name AS author_name,
+				word_similarity(lower(f_unaccent(?)), lower(f_unaccent(books.title))) AS score`, q).
+			Joins("LEFT JOIN authors ON authors.id = books.author_id").
+			Where("books.deleted_at IS NULL").
+			Where("lower(f_unaccent(?)) <% lower(f_unaccent(books.title))", q).
+			Order("score DESC").
+			Order("books.id ASC").
+			Limit(limit).
+			Scan(&suggestions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// SuggestAuthors returns the author names closest to q, the <% operator is served by idx_authors_name_trgm
+func (r *SearchRepository) SuggestAuthors(db *gorm.DB, q string, limit int) ([]AuthorSuggestion, error) {
+	var suggestions []AuthorSuggestion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := setWordSimilarityThreshold(tx); err != nil {
+			return err
+		}
+		return tx.Table("authors").
+			Select("id, name, word_similarity(lower(f_unaccent(?)), lower(f_unaccent(name))) AS score", q).
+			Where("deleted_at IS NULL").
+			Where("lower(f_unaccent(?)) <% lower(f_unaccent(name))", q).
+			Order("score DESC").
+			Order("id ASC").
+			Limit(limit).
+			Scan(&suggestions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// setWordSimilarityThreshold lowers the <% threshold for the current transaction only
+func setWordSimilarityThreshold(tx *gorm.DB) error {
+	return tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", suggestThreshold).Error
+}
+
+// NewSearchRepository creates a new instance of SearchRepository
+func NewSearchRepository() ISearchRepository {
+	return &SearchRepository{}
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ISeriesRepository interface {
+	GetSeriesByID(db *gorm.DB, id uint) (*models.Series, error)
+	FindSeriesByID(db *gorm.DB, id uint) (*models.Series, error)                     // books are not loaded
+	GetAllSeries(db *gorm.DB, limit, offset, afterID uint) (*[]models.Series, error) // afterID > 0 switches to keyset mode
+	CountSeries(db *gorm.DB) (int64, error)
+	CreateSeries(db *gorm.DB, series *models.Series) (*models.Series, error)
+	UpdateSeries(db *gorm.DB, series *models.Series) (*models.Series, error)
+	DeleteSeries(db *gorm.DB, series *models.Series) error
+
+	// Books of a series in reading order
+	GetBooksInSeries(db *gorm.DB, seriesID uint) (*[]models.Book, error)
+	DetachBooksFromSeries(db *gorm.DB, seriesID uint) error
+}
+
+type SeriesRepository struct{}
+
+// readingOrder sorts books by position, books without a position go last
+func readingOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("series_position ASC NULLS LAST").Order("id ASC")
+}
+
+func (r *SeriesRepository) GetSeriesByID(db *gorm.DB, id uint) (*models.Series, error) {
+	var series models.Series
+	if err := db.Preload("Books", readingOrder).First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *SeriesRepository) FindSeriesByID(db *gorm.DB, id uint) (*models.Series, error) {
+	var series models.Series
+	if err := db.First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *SeriesRepository) GetAllSeries(db *gorm.DB, limit, offset, afterID uint) (*[]models.Series, error) {
+	var series []models.Series
+	query := db.Preload("Books", readingOrder).Order("id ASC").Limit(int(limit))
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	} else {
+		query = query.Offset(int(offset))
+	}
+	if err := query.Find(&series).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *SeriesRepository) CountSeries(db *gorm.DB) (int64, error) {
+	var total int64
+	if err := db.Model(&models.Series{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *SeriesRepository) CreateSeries(db *gorm.DB, series *models.Series) (*models.Series, error) {
+	if err := db.Create(series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (r *SeriesRepository) UpdateSeries(db *gorm.DB, series *models.Series) (*models.Series, error) {
+	if err := db.Save(series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (r *SeriesRepository) DeleteSeries(db *gorm.DB, series *models.Series) error {
+	return db.Delete(series).Error
+}
+
+// GetBooksInSeries returns all books of a series (with author and genres) in reading order
+func (r *SeriesRepository) GetBooksInSeries(db *gorm.DB, seriesID uint) (*[]models.Book, error) {
+	var books []models.Book
+	if err := readingOrder(db.Where("series_id = ?", seriesID)).
+		Preload("Author").
+		Preload("Genres").
+		Preload("Series").
+		Find(&books).Error; err != nil {
+		return nil, err
+	}
+	return &books, nil
+}
+
+// DetachBooksFromSeries clears series membership of every book in a series
+func (r *SeriesRepository) DetachBooksFromSeries(db *gorm.DB, seriesID uint) error {
+	return db.Model(&models.Book{}).
+		Where("series_id = ?", seriesID).
+		Updates(map[string]interface{}{"series_id": nil, "series_position": nil}).Error
+}
+
+// NewSeriesRepository creates a new instance of SeriesRepository
+func NewSeriesRepository() ISeriesRepository {
+	return &SeriesRepository{}
+}
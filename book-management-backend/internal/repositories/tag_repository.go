@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagSuggestion is a tag returned by autocomplete along with how many books use it
+type TagSuggestion struct {
+	ID        uint
+	Name      string
+	BookCount int64
+}
+
+type ITagRepository interface {
+	FindOrCreateTags(db *gorm.DB, tags []models.Tag) ([]models.Tag, error)
+	GetTagsByNormalizedNames(db *gorm.DB, normalizedNames []string) ([]models.Tag, error)
+	Autocomplete(db *gorm.DB, prefix string, limit int) ([]TagSuggestion, error)
+
+	// Manage relationships between Tags and Books
+	AddTagsToBook(db *gorm.DB, book *models.Book, tags []models.Tag) error
+	RemoveTagsFromBook(db *gorm.DB, book *models.Book, tags []models.Tag) error
+}
+
+type TagRepository struct{}
+
+// FindOrCreateTags inserts missing tags (by normalized name) and returns all of them
+func (r *TagRepository) FindOrCreateTags(db *gorm.DB, tags []models.Tag) ([]models.Tag, error) {
+	if len(tags) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "normalized_name"}},
+		DoNothing: true,
+	}).Create(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.NormalizedName
+	}
+	return r.GetTagsByNormalizedNames(db, names)
+}
+
+func (r *TagRepository) GetTagsByNormalizedNames(db *gorm.DB, normalizedNames []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := db.Where("normalized_name IN ?", normalizedNames).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Autocomplete returns tags starting with prefix, most used first.
+// Soft-deleted books are filtered in the join so their tags are still suggested, with a count of 0.
+func (r *TagRepository) Autocomplete(db *gorm.DB, prefix string, limit int) ([]TagSuggestion, error) {
+	var suggestions []TagSuggestion
+	err := db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(books.id) AS book_count").
+		Joins("LEFT JOIN book_tags ON book_tags.tag_id = tags.id").
+		Joins("LEFT JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Where("tags.normalized_name LIKE ?", escapeLike(prefix)+"%").
+		Group("tags.id, tags.name").
+		Order("book_count DESC").
+		Order("tags.name ASC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// AddTagsToBook appends tags to a book without removing existing ones
+func (r *TagRepository) AddTagsToBook(db *gorm.DB, book *models.Book, tags []models.Tag) error {
+	return db.Model(book).Association("Tags").Append(tags)
+}
+
+// RemoveTagsFromBook detaches specific tags from a book
+func (r *TagRepository) RemoveTagsFromBook(db *gorm.DB, book *models.Book, tags []models.Tag) error {
+	return db.Model(book).Association("Tags").Delete(tags)
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// NewTagRepository creates a new instance of TagRepository
+func NewTagRepository() ITagRepository {
+	return &TagRepository{}
+}
@@ -4,6 +4,7 @@ import (
 	"book-management/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // IUserRepository defines all user-related DB operations
@@ -13,6 +14,9 @@ type IUserRepository interface {
 	FindByUsername(db *gorm.DB, username string) (*models.User, error)
 	FindByID(db *gorm.DB, id uint) (*models.User, error)
 	FindByRefreshToken(db *gorm.DB, token string) (*models.User, error)
+	// LockByID loads a user with SELECT ... FOR UPDATE, to be used inside a transaction
+	LockByID(db *gorm.DB, id uint) (*models.User, error)
+	UpdatePatronCategory(db *gorm.DB, user *models.User, category string) error
 }
 
 // userRepository is the concrete implementation of IUserRepository
@@ -54,3 +58,15 @@ func (r *userRepository) FindByRefreshToken(db *gorm.DB, token string) (*models.
 	}
 	return &user, nil
 }
+
+func (r *userRepository) LockByID(db *gorm.DB, id uint) (*models.User, error) {
+	var user models.User
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdatePatronCategory(db *gorm.DB, user *models.User, category string) error {
+	return db.Model(user).Update("patron_category", category).Error
+}
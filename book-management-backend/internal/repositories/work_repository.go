@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"book-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type IWorkRepository interface {
+	GetWorkByID(db *gorm.DB, id uint) (*models.Work, error)
+	GetAllWorks(db *gorm.DB, limit, offset, afterID uint) (*[]models.Work, error) // afterID > 0 switches to keyset mode
+	CountWorks(db *gorm.DB) (int64, error)
+	CreateWork(db *gorm.DB, work *models.Work) (*models.Work, error)
+	UpdateWork(db *gorm.DB, work *models.Work) (*models.Work, error)
+	DeleteWork(db *gorm.DB, work *models.Work) error
+
+	// Manage editions (books) of a Work
+	AttachEditions(db *gorm.DB, workID uint, bookIDs []uint) error
+	DetachEditions(db *gorm.DB, workID uint, bookIDs []uint) error
+	DetachAllEditions(db *gorm.DB, workID uint) error
+
+	// Manage genres attached at work level
+	ReplaceGenres(db *gorm.DB, work *models.Work, genres []models.Genre) error
+}
+
+type WorkRepository struct{}
+
+func (r *WorkRepository) GetWorkByID(db *gorm.DB, id uint) (*models.Work, error) {
+	var work models.Work
+	if err := db.Preload("Genres").
+		Preload("Editions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("publication_year ASC NULLS LAST").Order("id ASC")
+		}).
+		Preload("Editions.Author").
+		First(&work, id).Error; err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *WorkRepository) GetAllWorks(db *gorm.DB, limit, offset, afterID uint) (*[]models.Work, error) {
+	var works []models.Work
+	query := db.Preload("Genres").
+		Preload("Editions").
+		Preload("Editions.Author").
+		Order("id ASC").
+		Limit(int(limit))
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	} else {
+		query = query.Offset(int(offset))
+	}
+	if err := query.Find(&works).Error; err != nil {
+		return nil, err
+	}
+	return &works, nil
+}
+
+func (r *WorkRepository) CountWorks(db *gorm.DB) (int64, error) {
+	var total int64
+	if err := db.Model(&models.Work{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *WorkRepository) CreateWork(db *gorm.DB, work *models.Work) (*models.Work, error) {
+	if err := db.Create(work).Error; err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+func (r *WorkRepository) UpdateWork(db *gorm.DB, work *models.Work) (*models.Work, error) {
+	if err := db.Omit("Genres", "Editions").Save(work).Error; err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+func (r *WorkRepository) DeleteWork(db *gorm.DB, work *models.Work) error {
+	return db.Delete(work).Error
+}
+
+// AttachEditions links books to a work, moving them away from any previous work
+func (r *WorkRepository) AttachEditions(db *gorm.DB, workID uint, bookIDs []uint) error {
+	return db.Model(&models.Book{}).Where("id IN ?", bookIDs).Update("work_id", workID).Error
+}
+
+// DetachEditions unlinks the given books from a work
+func (r *WorkRepository) DetachEditions(db *gorm.DB, workID uint, bookIDs []uint) error {
+	return db.Model(&models.Book{}).
+		Where("work_id = ? AND id IN ?", workID, bookIDs).
+		Update("work_id", nil).Error
+}
+
+// DetachAllEditions unlinks every book of a work
+func (r *WorkRepository) DetachAllEditions(db *gorm.DB, workID uint) error {
+	return db.Model(&models.Book{}).Where("work_id = ?", workID).Update("work_id", nil).Error
+}
+
+// ReplaceGenres replaces all work-level genres with a new set
+func (r *WorkRepository) ReplaceGenres(db *gorm.DB, work *models.Work, genres []models.Genre) error {
+	return db.Model(work).Association("Genres").Replace(genres)
+}
+
+// NewWorkRepository creates a new instance of WorkRepository
+func NewWorkRepository() IWorkRepository {
+	return &WorkRepository{}
+}
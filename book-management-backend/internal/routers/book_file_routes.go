@@ -0,0 +1,30 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBookFileRoutes(rg *gin.RouterGroup, handler *handlers.BookFileHandler, cfg *config.Config) {
+	files := rg.Group("/books/:id/files")
+	{
+		// GET /books/:id/files - both admin & user can access
+		files.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetBookFiles)
+
+		// POST /books/:id/files - only admin can upload
+		files.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UploadBookFile)
+
+		// DELETE /books/:id/files/:fileId - only admin can delete
+		files.DELETE("/:fileId", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteBookFile)
+
+		// GET /books/:id/files/:fileId/link - both admin & user can get a download link
+		files.GET("/:fileId/link", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetDownloadLink)
+	}
+
+	// GET /files/:id/download - no token, the signed link is the authorization
+	rg.GET("/files/:id/download", handler.DownloadFile)
+}
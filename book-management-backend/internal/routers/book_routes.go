@@ -32,10 +32,19 @@ func RegisterBookRoutes(rg *gin.RouterGroup, handler *handlers.BookHandler, cfg
 		// POST /books - both admin & user can create
 		books.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.CreateBook)
 
+		// POST /books/lookup - both admin & user can access
+		books.POST("/lookup", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.LookupBook)
+
 		// PATCH /books/:id - only admin can update
 		books.PATCH("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UpdateBook)
 
 		// DELETE /books/:id - only admin can delete
 		books.DELETE("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteBook)
+
+		// PUT /books/:id/image - only admin can replace a cover
+		books.PUT("/:id/image", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ReplaceBookImage)
+
+		// DELETE /books/:id/image - only admin can remove a cover
+		books.DELETE("/:id/image", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteBookImage)
 	}
 }
@@ -0,0 +1,33 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCirculationPolicyRoutes(rg *gin.RouterGroup, handler *handlers.CirculationPolicyHandler, cfg *config.Config) {
+	policies := rg.Group("/circulation-policies")
+	{
+		// GET /circulation-policies - only admin can list policies
+		policies.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.GetAllPolicies)
+
+		// GET /circulation-policies/:id - only admin can access
+		policies.GET("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.GetPolicyByID)
+
+		// POST /circulation-policies - only admin can create
+		policies.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.CreatePolicy)
+
+		// PATCH /circulation-policies/:id - only admin can update
+		policies.PATCH("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UpdatePolicy)
+
+		// DELETE /circulation-policies/:id - only admin can delete
+		policies.DELETE("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeletePolicy)
+	}
+
+	// PUT /users/:id/patron-category - only admin can assign patron categories
+	rg.PUT("/users/:id/patron-category", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.SetPatronCategory)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCitationRoutes(rg *gin.RouterGroup, handler *handlers.CitationHandler, cfg *config.Config) {
+	books := rg.Group("/books")
+	{
+		// GET /books/citations?ids= - both admin & user can access
+		books.GET("/citations", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetBookCitations)
+
+		// GET /books/:id/citation - both admin & user can access
+		books.GET("/:id/citation", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetBookCitation)
+	}
+}
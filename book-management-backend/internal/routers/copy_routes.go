@@ -0,0 +1,30 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCopyRoutes(rg *gin.RouterGroup, handler *handlers.CopyHandler, cfg *config.Config) {
+	copies := rg.Group("/books/:id/copies")
+	{
+		// GET /books/:id/copies - both admin & user can access
+		copies.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetCopies)
+
+		// GET /books/:id/copies/:copyId - both admin & user can access
+		copies.GET("/:copyId", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetCopy)
+
+		// POST /books/:id/copies - only admin can add copies
+		copies.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.CreateCopy)
+
+		// PATCH /books/:id/copies/:copyId - only admin can update
+		copies.PATCH("/:copyId", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UpdateCopy)
+
+		// DELETE /books/:id/copies/:copyId - only admin can delete
+		copies.DELETE("/:copyId", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteCopy)
+	}
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterExportRoutes(rg *gin.RouterGroup, handler *handlers.ExportHandler, cfg *config.Config) {
+	exports := rg.Group("/export")
+	{
+		// GET /export/books?format= - only admin can access
+		exports.GET("/books", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ExportBooks)
+
+		// GET /export/books/:id?format= - both admin & user can access
+		exports.GET("/books/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.ExportBook)
+
+		// GET /export/authors?format= - only admin can access
+		exports.GET("/authors", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ExportAuthors)
+
+		// GET /export/genres?format= - only admin can access
+		exports.GET("/genres", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ExportGenres)
+	}
+}
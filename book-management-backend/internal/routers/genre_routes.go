@@ -15,6 +15,9 @@ func RegisterGenreRoutes(rg *gin.RouterGroup, handler *handlers.GenreHandler, cf
 		// GET /genres/:id - both admin & user can access
 		genres.GET("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetGenreByID)
 
+		// GET /genres/slug/:slug - both admin & user can access
+		genres.GET("/slug/:slug", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetGenreBySlug)
+
 		// GET /genres - both admin & user can access
 		genres.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetAllGenres)
 
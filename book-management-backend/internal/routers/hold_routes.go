@@ -0,0 +1,24 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterHoldRoutes(rg *gin.RouterGroup, handler *handlers.HoldHandler, cfg *config.Config) {
+	// POST /books/:id/holds - admin holds for anyone, user for themselves
+	rg.POST("/books/:id/holds", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.PlaceHold)
+
+	// GET /books/:id/holds - only admin can see the queue of a book
+	rg.GET("/books/:id/holds", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.GetBookHolds)
+
+	// POST /holds/:id/cancel - admin, or the patron
+	rg.POST("/holds/:id/cancel", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.CancelHold)
+
+	// GET /me/holds - both admin & user can list their own holds
+	rg.GET("/me/holds", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetMyHolds)
+}
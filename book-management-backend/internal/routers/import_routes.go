@@ -0,0 +1,27 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterImportRoutes(rg *gin.RouterGroup, handler *handlers.ImportHandler, cfg *config.Config) {
+	imports := rg.Group("/import")
+	{
+		// POST /import/books - only admin can access
+		imports.POST("/books", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ImportBooks)
+
+		// POST /import/marc - only admin can access
+		imports.POST("/marc", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ImportMARC)
+
+		// GET /import/jobs/:id - only admin can access
+		imports.GET("/jobs/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.GetImportJob)
+
+		// GET /import/jobs/:id/errors - only admin can access
+		imports.GET("/jobs/:id/errors", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DownloadImportErrors)
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterLoanRoutes(rg *gin.RouterGroup, handler *handlers.LoanHandler, cfg *config.Config) {
+	loans := rg.Group("/loans")
+	{
+		// GET /loans - only admin can list all loans
+		loans.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.GetAllLoans)
+
+		// GET /loans/:id - admin, or the borrower
+		loans.GET("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetLoanByID)
+
+		// POST /loans - admin checks out for anyone, user for themselves
+		loans.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.Checkout)
+
+		// POST /loans/:id/return - only admin can check in
+		loans.POST("/:id/return", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.ReturnLoan)
+
+		// POST /loans/:id/renew - admin, or the borrower
+		loans.POST("/:id/renew", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.RenewLoan)
+	}
+
+	// GET /me/loans - both admin & user can list their own loans
+	rg.GET("/me/loans", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetMyLoans)
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"book-management/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerOPDSFeeds registers the catalog feeds on a version group, the handler picks the format from the path
+func registerOPDSFeeds(rg *gin.RouterGroup, handler *handlers.OPDSHandler) {
+	rg.GET("", handler.GetRoot)
+	rg.GET("/new", handler.GetNewBooks)
+	rg.GET("/search", handler.SearchBooks)
+	rg.GET("/genres", handler.GetGenres)
+	rg.GET("/genres/:id", handler.GetGenreBooks)
+	rg.GET("/authors", handler.GetAuthors)
+	rg.GET("/authors/:id", handler.GetAuthorBooks)
+}
+
+func RegisterOPDSRoutes(rg *gin.RouterGroup, handler *handlers.OPDSHandler) {
+	catalog := rg.Group("/opds")
+	{
+		// GET /opds/... - OPDS 1.2 (Atom) feeds, public so e-reader apps can browse the catalog
+		registerOPDSFeeds(catalog, handler)
+
+		// GET /opds/opensearch.xml - public
+		catalog.GET("/opensearch.xml", handler.GetOpenSearchDescription)
+
+		// GET /opds/v2/... - OPDS 2.0 (JSON) feeds, public
+		registerOPDSFeeds(catalog.Group("/v2"), handler)
+	}
+}
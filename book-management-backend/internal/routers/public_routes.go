@@ -0,0 +1,21 @@
+package router
+
+import (
+	"book-management/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPublicRoutes(rg *gin.RouterGroup, handler *handlers.PublicHandler) {
+	// GET /public/books/:id - public, HTML or JSON-LD by content negotiation
+	rg.GET("/public/books/:id", handler.GetBook)
+
+	// GET /sitemap.xml - public
+	rg.GET("/sitemap.xml", handler.GetSitemap)
+
+	// GET /sitemaps/:name - public, pages of a large sitemap (books-N.xml)
+	rg.GET("/sitemaps/:name", handler.GetBookSitemap)
+
+	// GET /robots.txt - public
+	rg.GET("/robots.txt", handler.GetRobots)
+}
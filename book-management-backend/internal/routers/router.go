@@ -13,6 +13,20 @@ func NewRouter(
 	bookHandler *handlers.BookHandler,
 	authHandler *handlers.AuthHandler,
 	genreHanlder *handlers.GenreHandler,
+	seriesHandler *handlers.SeriesHandler,
+	workHandler *handlers.WorkHandler,
+	tagHandler *handlers.TagHandler,
+	searchHandler *handlers.SearchHandler,
+	importHandler *handlers.ImportHandler,
+	exportHandler *handlers.ExportHandler,
+	citationHandler *handlers.CitationHandler,
+	opdsHandler *handlers.OPDSHandler,
+	publicHandler *handlers.PublicHandler,
+	bookFileHandler *handlers.BookFileHandler,
+	copyHandler *handlers.CopyHandler,
+	loanHandler *handlers.LoanHandler,
+	policyHandler *handlers.CirculationPolicyHandler,
+	holdHandler *handlers.HoldHandler,
 	cfg *config.Config,
 ) *gin.Engine {
 	r := gin.Default()
@@ -23,12 +37,30 @@ func NewRouter(
 		c.JSON(200, gin.H{"message": "Hello Book Management API By Tuan Tran!"})
 	})
 
+	// OPDS catalog lives outside /api, e-reader apps browse it without a token
+	RegisterOPDSRoutes(&r.RouterGroup, opdsHandler)
+
+	// Public book pages & sitemap for search engines
+	RegisterPublicRoutes(&r.RouterGroup, publicHandler)
+
 	api := r.Group("/api")
 
 	RegisterAuthRoutes(api, authHandler)
 	RegisterAuthorRoutes(api, authorHandler, cfg)
 	RegisterBookRoutes(api, bookHandler, cfg)
 	RegisterGenreRoutes(api, genreHanlder, cfg)
+	RegisterSeriesRoutes(api, seriesHandler, cfg)
+	RegisterWorkRoutes(api, workHandler, cfg)
+	RegisterTagRoutes(api, tagHandler, cfg)
+	RegisterSearchRoutes(api, searchHandler, cfg)
+	RegisterImportRoutes(api, importHandler, cfg)
+	RegisterExportRoutes(api, exportHandler, cfg)
+	RegisterCitationRoutes(api, citationHandler, cfg)
+	RegisterBookFileRoutes(api, bookFileHandler, cfg)
+	RegisterCopyRoutes(api, copyHandler, cfg)
+	RegisterLoanRoutes(api, loanHandler, cfg)
+	RegisterCirculationPolicyRoutes(api, policyHandler, cfg)
+	RegisterHoldRoutes(api, holdHandler, cfg)
 
 	return r
 }
@@ -0,0 +1,21 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSearchRoutes(rg *gin.RouterGroup, handler *handlers.SearchHandler, cfg *config.Config) {
+	// GET /search?q= - both admin & user can access
+	rg.GET("/search", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.Search)
+
+	// GET /books/suggest?q= - both admin & user can access, type-ahead on titles
+	rg.GET("/books/suggest", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.SuggestBooks)
+
+	// GET /authors/suggest?q= - both admin & user can access, type-ahead on author names
+	rg.GET("/authors/suggest", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.SuggestAuthors)
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSeriesRoutes(rg *gin.RouterGroup, handler *handlers.SeriesHandler, cfg *config.Config) {
+	series := rg.Group("/series")
+	{
+		// GET /series/:id - both admin & user can access
+		series.GET("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetSeriesByID)
+
+		// GET /series/:id/books - both admin & user can access, books in reading order
+		series.GET("/:id/books", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetBooksInSeries)
+
+		// GET /series - both admin & user can access
+		series.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetAllSeries)
+
+		// POST /series - only admin can create
+		series.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.CreateSeries)
+
+		// PATCH /series/:id - only admin can update
+		series.PATCH("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UpdateSeries)
+
+		// DELETE /series/:id - only admin can delete
+		series.DELETE("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteSeries)
+	}
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTagRoutes(rg *gin.RouterGroup, handler *handlers.TagHandler, cfg *config.Config) {
+	tags := rg.Group("/tags")
+	{
+		// GET /tags/autocomplete?q= - both admin & user can access
+		tags.GET("/autocomplete", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.Autocomplete)
+	}
+
+	bookTags := rg.Group("/books/:id/tags")
+	{
+		// POST /books/:id/tags - any authenticated user can tag a book
+		bookTags.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.AddTagsToBook)
+
+		// DELETE /books/:id/tags - any authenticated user can untag a book
+		bookTags.DELETE("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.RemoveTagsFromBook)
+	}
+}
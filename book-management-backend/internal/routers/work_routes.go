@@ -0,0 +1,36 @@
+package router
+
+import (
+	config "book-management/configs"
+	"book-management/internal/handlers"
+	"book-management/internal/middlewares"
+	"book-management/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWorkRoutes(rg *gin.RouterGroup, handler *handlers.WorkHandler, cfg *config.Config) {
+	works := rg.Group("/works")
+	{
+		// GET /works/:id - both admin & user can access, lists all editions
+		works.GET("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetWorkByID)
+
+		// GET /works - both admin & user can access
+		works.GET("", middlewares.AuthMiddleware(cfg, models.RoleAdmin, models.RoleUser), handler.GetAllWorks)
+
+		// POST /works - only admin can create
+		works.POST("", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.CreateWork)
+
+		// PATCH /works/:id - only admin can update
+		works.PATCH("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.UpdateWork)
+
+		// DELETE /works/:id - only admin can delete
+		works.DELETE("/:id", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DeleteWork)
+
+		// POST /works/:id/editions - only admin can attach editions to a work
+		works.POST("/:id/editions", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.AttachEditions)
+
+		// DELETE /works/:id/editions - only admin can detach editions from a work
+		works.DELETE("/:id/editions", middlewares.AuthMiddleware(cfg, models.RoleAdmin), handler.DetachEditions)
+	}
+}
@@ -3,13 +3,20 @@ package services
 import (
 	"book-management/internal/models"
 	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
 
 	"gorm.io/gorm"
 )
 
+// AuthorListResult is one page of authors ordered by id
+type AuthorListResult struct {
+	Authors []models.Author
+	pagination.PageInfo
+}
+
 type IAuthorService interface {
 	GetAuthorByID(authorID uint) (*models.Author, error)
-	GetAuthors(limit, offset int) ([]models.Author, error) // Pagination
+	GetAuthors(params pagination.Params, include []string) (*AuthorListResult, error) // Pagination, the cursor must come from an id-ordered listing
 	GetAuthorByEmail(email string) (*models.Author, error)
 	CreateAuthor(author *models.Author) error
 	UpdateAuthor(author *models.Author) error
@@ -33,8 +40,34 @@ func (s *AuthorService) GetAuthorByID(authorID uint) (*models.Author, error) {
 }
 
 // Pagination
-func (s *AuthorService) GetAuthors(limit, offset int) ([]models.Author, error) {
-	return s.repo.GetAuthors(s.db, limit, offset)
+func (s *AuthorService) GetAuthors(params pagination.Params, include []string) (*AuthorListResult, error) {
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether there is a next page
+	authors, err := s.repo.GetAuthors(s.db, params.Limit+1, params.Offset, afterID, include)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuthorListResult{Authors: authors}
+	if len(result.Authors) > params.Limit {
+		result.Authors = result.Authors[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Authors[params.Limit-1].ID)
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountAuthors(s.db)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	return result, nil
 }
 
 func (s *AuthorService) GetAuthorByEmail(email string) (*models.Author, error) {
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/pkg/imaging"
+	"book-management/pkg/utils"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+)
+
+// BookImageRequest is the multipart body of PUT /books/:id/image
+type BookImageRequest struct {
+	Image *multipart.FileHeader `form:"image" binding:"required"`
+}
+
+// uploadedCover is a processed cover stored on Cloudinary
+type uploadedCover struct {
+	URL      string
+	PublicID string
+	Variants *models.ImageVariants
+}
+
+// uploadCover validates and sanitizes an uploaded cover, then stores it with its variants
+func (s *BookService) uploadCover(fileHeader *multipart.FileHeader) (*uploadedCover, int, error) {
+	if s.coverLimits.MaxBytes > 0 && fileHeader.Size > s.coverLimits.MaxBytes {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("%w, the limit is %d bytes", imaging.ErrTooLarge, s.coverLimits.MaxBytes)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	img, err := imaging.Process(file, s.coverLimits)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrTooLarge):
+			return nil, http.StatusRequestEntityTooLarge, err
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			return nil, http.StatusUnsupportedMediaType, err
+		case errors.Is(err, imaging.ErrCorrupt):
+			return nil, http.StatusBadRequest, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	uploaded, err := s.CloudinaryUtil.UploadImage(img, "books", utils.CoverVariants)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("upload image failed: %w", err)
+	}
+
+	return &uploadedCover{
+		URL:      uploaded.URL,
+		PublicID: uploaded.PublicID,
+		Variants: &models.ImageVariants{
+			Thumbnail: uploaded.Variants["thumbnail"],
+			Medium:    uploaded.Variants["medium"],
+			Large:     uploaded.Variants["large"],
+		},
+	}, http.StatusOK, nil
+}
+
+// ReplaceBookImage uploads a new cover for a book, then deletes the previous one from storage
+func (s *BookService) ReplaceBookImage(bookIdStr string, req BookImageRequest) (*models.Book, int, error) {
+	book, httpStatus, err := s.GetBookByID(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	cover, httpStatus, err := s.uploadCover(req.Image)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	previous := *book
+	book.Image = cover.URL
+	book.ImagePublicID = cover.PublicID
+	book.ImageVariants = cover.Variants
+	if err := s.repo.UpdateBookImage(s.db, book); err != nil {
+		// The new object is orphaned unless another book already used the same file. Covers are named
+		// after their content, so re-uploading the current cover gives the object the unchanged row still uses.
+		if cover.PublicID != previous.ImagePublicID {
+			s.deleteCoverIfUnused(book, book.ID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if previous.ImagePublicID != cover.PublicID {
+		s.deleteCoverIfUnused(&previous, book.ID)
+	}
+	return book, http.StatusOK, nil
+}
+
+// DeleteBookImage clears the cover of a book and deletes it from storage
+func (s *BookService) DeleteBookImage(bookIdStr string) (int, error) {
+	book, httpStatus, err := s.GetBookByID(bookIdStr)
+	if err != nil {
+		return httpStatus, err
+	}
+	if book.Image == "" && book.ImagePublicID == "" {
+		return http.StatusNotFound, fmt.Errorf("book with ID [%d] has no image", book.ID)
+	}
+
+	previous := *book
+	book.Image = ""
+	book.ImagePublicID = ""
+	book.ImageVariants = nil
+	if err := s.repo.UpdateBookImage(s.db, book); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	s.deleteCoverIfUnused(&previous, book.ID)
+	return http.StatusNoContent, nil
+}
+
+// deleteCoverIfUnused removes the stored cover of book unless another book (other than excludeID) still uses it.
+// Covers are named after their content, so two books can share one object. Failures are only logged,
+// the database is already up to date and a leftover object is harmless.
+func (s *BookService) deleteCoverIfUnused(book *models.Book, excludeID uint) {
+	if s.CloudinaryUtil == nil {
+		return
+	}
+
+	publicID := book.ImagePublicID
+	if publicID == "" {
+		// Uploaded before public IDs were recorded, or an external URL which is not ours to delete
+		id, ok := s.CloudinaryUtil.PublicIDFromURL(book.Image)
+		if !ok {
+			return
+		}
+		publicID = id
+	}
+
+	count, err := s.repo.CountBooksByImage(s.db, publicID, book.Image, excludeID)
+	if err != nil {
+		log.Printf("❌ Failed to check usages of image %s: %v", publicID, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	if err := s.CloudinaryUtil.DeleteImage(publicID); err != nil {
+		log.Printf("❌ Failed to delete image %s: %v", publicID, err)
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/filetype"
+	"book-management/pkg/signedurl"
+	"book-management/pkg/utils"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookFileUploadRequest is the multipart body of POST /books/:id/files
+type BookFileUploadRequest struct {
+	File  *multipart.FileHeader `form:"file" binding:"required"`
+	Kind  string                `form:"kind" binding:"omitempty,oneof=full sample"` // defaults to full
+	Title string                `form:"title" binding:"max=255"`
+}
+
+// BookFileLink is a signed download URL, Path includes the query string
+type BookFileLink struct {
+	Path      string
+	ExpiresAt time.Time
+}
+
+// BookFileDownload streams a file, Partial is set when only the requested byte range is sent
+type BookFileDownload struct {
+	File          *models.BookFile
+	Body          io.ReadCloser
+	ContentLength int64 // -1 when unknown
+	ContentRange  string
+	Partial       bool
+}
+
+type IBookFileService interface {
+	GetBookFiles(bookIdStr string) ([]models.BookFile, int, error)
+	UploadBookFile(bookIdStr string, req BookFileUploadRequest, userID uint) (*models.BookFile, int, error)
+	DeleteBookFile(bookIdStr, fileIdStr string) (int, error)
+	// CreateDownloadLink signs a download URL valid for the configured TTL. The link is not bound to
+	// a user: the download route takes no token, so anyone holding the link can use it until it expires.
+	CreateDownloadLink(bookIdStr, fileIdStr string) (*BookFileLink, int, error)
+	// OpenDownload verifies a signed download URL and opens the file, byteRange is an optional HTTP Range header
+	OpenDownload(ctx context.Context, fileIdStr string, query url.Values, byteRange string) (*BookFileDownload, int, error)
+}
+
+type BookFileService struct {
+	repo           repositories.IBookFileRepository
+	bookRepo       repositories.IBookRepository
+	CloudinaryUtil *utils.CloudinaryUtil
+	signer         *signedurl.Signer
+	maxBytes       int64
+	linkTTL        time.Duration
+	db             *gorm.DB
+}
+
+// BookFileDownloadPath is the path signed download URLs point to
+func BookFileDownloadPath(fileID uint) string {
+	return fmt.Sprintf("/api/files/%d/download", fileID)
+}
+
+func (s *BookFileService) GetBookFiles(bookIdStr string) ([]models.BookFile, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	files, err := s.repo.GetFilesByBookID(s.db, book.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return files, http.StatusOK, nil
+}
+
+func (s *BookFileService) UploadBookFile(bookIdStr string, req BookFileUploadRequest, userID uint) (*models.BookFile, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if s.maxBytes > 0 && req.File.Size > s.maxBytes {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("file is too large, the limit is %d bytes", s.maxBytes)
+	}
+	if req.File.Size == 0 {
+		return nil, http.StatusBadRequest, errors.New("file is empty")
+	}
+
+	src, err := req.File.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	defer src.Close()
+
+	// Sniff the format from the first bytes, then upload them followed by the rest
+	header := make([]byte, filetype.HeaderSize)
+	n, err := io.ReadFull(src, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, http.StatusBadRequest, err
+	}
+	header = header[:n]
+	format, err := filetype.Sniff(header)
+	if err != nil {
+		return nil, http.StatusUnsupportedMediaType, err
+	}
+
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(header), src), hash)
+	uploaded, err := s.CloudinaryUtil.UploadFile(body, "book-files", format)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("upload file failed: %w", err)
+	}
+
+	kind := models.BookFileFull
+	if req.Kind != "" {
+		kind = models.BookFileKind(req.Kind)
+	}
+
+	file := &models.BookFile{
+		BookID:       book.ID,
+		Kind:         kind,
+		Title:        strings.TrimSpace(req.Title),
+		FileName:     bookFileName(req.File.Filename, book.Title, format),
+		Format:       string(format),
+		ContentType:  format.ContentType(),
+		Size:         req.File.Size,
+		Checksum:     hex.EncodeToString(hash.Sum(nil)),
+		CreatedBy:    userID,
+		PublicID:     uploaded.PublicID,
+		ResourceType: uploaded.ResourceType,
+	}
+	created, err := s.repo.CreateFile(s.db, file)
+	if err != nil {
+		s.deleteStoredFile(file)
+		return nil, http.StatusInternalServerError, err
+	}
+	return created, http.StatusCreated, nil
+}
+
+func (s *BookFileService) DeleteBookFile(bookIdStr, fileIdStr string) (int, error) {
+	file, httpStatus, err := s.getBookFile(bookIdStr, fileIdStr)
+	if err != nil {
+		return httpStatus, err
+	}
+
+	if err := s.repo.DeleteFile(s.db, file); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	s.deleteStoredFile(file)
+
+	return http.StatusNoContent, nil
+}
+
+func (s *BookFileService) CreateDownloadLink(bookIdStr, fileIdStr string) (*BookFileLink, int, error) {
+	file, httpStatus, err := s.getBookFile(bookIdStr, fileIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	link := signDownloadLink(s.signer, file.ID, s.linkTTL)
+	return &link, http.StatusOK, nil
+}
+
+// signDownloadLink signs the download URL of a file for ttl, shared with the OPDS catalog
+func signDownloadLink(signer *signedurl.Signer, fileID uint, ttl time.Duration) BookFileLink {
+	path := BookFileDownloadPath(fileID)
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	query := signer.Sign(path, "", expiresAt)
+	return BookFileLink{Path: path + "?" + query.Encode(), ExpiresAt: expiresAt}
+}
+
+func (s *BookFileService) OpenDownload(ctx context.Context, fileIdStr string, query url.Values, byteRange string) (*BookFileDownload, int, error) {
+	fileID, err := strconv.ParseUint(fileIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if _, err := s.signer.Verify(BookFileDownloadPath(uint(fileID)), query, time.Now()); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	file, err := s.repo.GetFileByID(s.db, uint(fileID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("file with ID [%d] does not exist", fileID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	// Files of a deleted book are no longer served
+	if _, err := s.bookRepo.GetBookById(s.db, file.BookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("file with ID [%d] does not exist", fileID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	resp, err := s.CloudinaryUtil.OpenFile(ctx, file.PublicID, file.ResourceType, filetype.Format(file.Format), byteRange)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+
+	// Players fetch audio in many ranges, only the request starting at the first byte counts as a download
+	if byteRange == "" || strings.HasPrefix(byteRange, "bytes=0-") {
+		if err := s.repo.IncrementDownloadCount(s.db, file.ID); err != nil {
+			log.Printf("❌ Failed to count download of file %d: %v", file.ID, err)
+		}
+	}
+
+	return &BookFileDownload{
+		File:          file,
+		Body:          resp.Body,
+		ContentLength: resp.ContentLength,
+		ContentRange:  resp.Header.Get("Content-Range"),
+		Partial:       resp.StatusCode == http.StatusPartialContent,
+	}, http.StatusOK, nil
+}
+
+func (s *BookFileService) getBook(bookIdStr string) (*models.Book, int, error) {
+	bookID, err := strconv.ParseUint(bookIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	book, err := s.bookRepo.GetBookById(s.db, uint(bookID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", bookID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return book, http.StatusOK, nil
+}
+
+// getBookFile loads a file, making sure it belongs to the book in the path
+func (s *BookFileService) getBookFile(bookIdStr, fileIdStr string) (*models.BookFile, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	fileID, err := strconv.ParseUint(fileIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	file, err := s.repo.GetFileByID(s.db, uint(fileID))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusInternalServerError, err
+	}
+	if file == nil || file.BookID != book.ID {
+		return nil, http.StatusNotFound, fmt.Errorf("file with ID [%d] does not exist for book [%d]", fileID, book.ID)
+	}
+	return file, http.StatusOK, nil
+}
+
+// deleteStoredFile removes a file from storage, failures are only logged as the row is already gone
+func (s *BookFileService) deleteStoredFile(file *models.BookFile) {
+	if err := s.CloudinaryUtil.DeleteFile(file.PublicID, file.ResourceType); err != nil {
+		log.Printf("❌ Failed to delete file %s: %v", file.PublicID, err)
+	}
+}
+
+// bookFileName is the name offered when downloading: the uploaded name with the extension of the
+// detected format, or the book title when the upload had no usable name
+func bookFileName(uploaded, bookTitle string, format filetype.Format) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(uploaded, "\\", "/")))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		name = utils.Slugify(bookTitle)
+	}
+	if name == "" {
+		name = "book"
+	}
+	if len(name) > 200 {
+		name = strings.ToValidUTF8(name[:200], "")
+	}
+	return name + format.Extension()
+}
+
+func NewBookFileService(repo repositories.IBookFileRepository, bookRepo repositories.IBookRepository, db *gorm.DB, cloudUtil *utils.CloudinaryUtil, signer *signedurl.Signer, maxBytes int64, linkTTL time.Duration) IBookFileService {
+	return &BookFileService{
+		repo:           repo,
+		bookRepo:       bookRepo,
+		CloudinaryUtil: cloudUtil,
+		signer:         signer,
+		maxBytes:       maxBytes,
+		linkTTL:        linkTTL,
+		db:             db,
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"book-management/pkg/metadata"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type BookLookupRequest struct {
+	ISBN string `json:"isbn" binding:"required"`
+}
+
+// BookLookupResult is the merged metadata of all providers, MatchedAuthorID is set when an
+// existing author has the name of the first listed author
+type BookLookupResult struct {
+	metadata.BookMetadata
+	MatchedAuthorID *uint
+}
+
+// lookupISBN validates the ISBN and asks every configured provider
+func (s *BookService) lookupISBN(isbnStr string) (*metadata.BookMetadata, int, error) {
+	isbn, err := normalizeOptionalISBN(isbnStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if isbn == "" {
+		return nil, http.StatusBadRequest, errors.New("isbn is required")
+	}
+	if len(s.providers) == 0 {
+		return nil, http.StatusServiceUnavailable, errors.New("no metadata provider is configured")
+	}
+
+	md, err := metadata.LookupAll(context.Background(), s.providers, isbn)
+	if err != nil {
+		if errors.Is(err, metadata.ErrNotFound) {
+			return nil, http.StatusNotFound, errors.New("no metadata found for ISBN [" + isbn + "]")
+		}
+		return nil, http.StatusBadGateway, err
+	}
+	md.ISBN = isbn
+	return md, http.StatusOK, nil
+}
+
+func (s *BookService) LookupMetadata(req BookLookupRequest) (*BookLookupResult, int, error) {
+	md, httpStatus, err := s.lookupISBN(req.ISBN)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	result := &BookLookupResult{BookMetadata: *md}
+	if len(md.Authors) > 0 {
+		author, err := s.authorRepo.GetAuthorByName(s.db, md.Authors[0])
+		switch {
+		case err == nil:
+			result.MatchedAuthorID = &author.ID
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	return result, http.StatusOK, nil
+}
+
+// enrichBook fills the empty fields of a create request from the ISBN lookup and returns the cover URL found.
+// Enrichment is best effort: a failed lookup leaves the request as is.
+func (s *BookService) enrichBook(req *BookCreateRequest) string {
+	if strings.TrimSpace(req.ISBN) == "" {
+		return ""
+	}
+	md, _, err := s.lookupISBN(req.ISBN)
+	if err != nil {
+		log.Printf("❌ Metadata lookup for ISBN %s failed: %v", req.ISBN, err)
+		return ""
+	}
+
+	if strings.TrimSpace(req.Title) == "" {
+		req.Title = md.Title
+	}
+	if req.Description == "" {
+		req.Description = md.Description
+	}
+	if req.Publisher == "" {
+		req.Publisher = md.Publisher
+	}
+	if req.Language == "" && len(md.Language) <= 10 {
+		req.Language = md.Language
+	}
+	if req.PublicationYear == nil {
+		req.PublicationYear = md.PublicationYear
+	}
+	return md.CoverURL
+}
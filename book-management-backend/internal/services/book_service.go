@@ -3,47 +3,324 @@ package services
 import (
 	"book-management/internal/models"
 	"book-management/internal/repositories"
+	"book-management/pkg/imaging"
+	"book-management/pkg/metadata"
+	"book-management/pkg/pagination"
 	"book-management/pkg/utils"
+	"errors"
 	"fmt"
+	"log"
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type BookCreateRequest struct {
-	Title    string                `form:"title" binding:"required"`
-	AuthorId uint                  `form:"authorId" binding:"required"`
-	Image    *multipart.FileHeader `form:"image"` // optional
+	// Title may be left to enrich=true when an ISBN is given
+	Title          string                `form:"title" binding:"required_without=ISBN"`
+	Description    string                `form:"description"` // optional
+	AuthorId       uint                  `form:"authorId" binding:"required"`
+	Image          *multipart.FileHeader `form:"image"`          // optional
+	SeriesId       *uint                 `form:"seriesId"`       // optional
+	SeriesPosition *float64              `form:"seriesPosition"` // optional, e.g. 2.5
+
+	// Edition details (optional)
+	ISBN            string `form:"isbn"`
+	Publisher       string `form:"publisher"`
+	Language        string `form:"language" binding:"omitempty,max=10"`
+	Format          string `form:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook other"`
+	PublicationYear *int   `form:"publicationYear" binding:"omitempty,min=0,max=9999"`
 }
 
 // Use pointer to check nil or empty for PATCH update api
 type BookUpdateRequest struct {
-	Title    *string `json:"title"`
-	AuthorId *uint   `json:"authorId"`
+	Title          *string  `json:"title"`
+	Description    *string  `json:"description"`
+	AuthorId       *uint    `json:"authorId"`
+	SeriesId       *uint    `json:"seriesId"`       // 0 removes the book from its series
+	SeriesPosition *float64 `json:"seriesPosition"` // cleared when the book moves to another series unless given
+
+	// Edition details
+	ISBN            *string `json:"isbn"` // empty string clears the ISBN
+	Publisher       *string `json:"publisher"`
+	Language        *string `json:"language" binding:"omitempty,max=10"`
+	Format          *string `json:"format" binding:"omitempty,oneof=hardcover paperback ebook audiobook other"`
+	PublicationYear *int    `json:"publicationYear" binding:"omitempty,min=0,max=9999"`
+}
+
+// BookListQuery holds the raw query parameters of GET /books
+type BookListQuery struct {
+	Title       string `form:"title"`        // substring, case-insensitive
+	AuthorID    string `form:"author_id"`    // exact author
+	GenreIDs    string `form:"genre_ids"`    // comma separated, e.g. "1,4,7"
+	GenreMatch  string `form:"genre_match"`  // "any" (default) or "all"
+	CreatedFrom string `form:"created_from"` // YYYY-MM-DD or RFC3339, inclusive
+	CreatedTo   string `form:"created_to"`   // YYYY-MM-DD (whole day included) or RFC3339, exclusive
+	Language    string `form:"language"`     // exact language code, e.g. "en"
+	Publisher   string `form:"publisher"`    // substring, case-insensitive
+	Tags        string `form:"tags"`         // comma separated, e.g. "signed copy,book-club-2026"
+	Sort        string `form:"sort"`         // e.g. "title,-created_at", "-" means descending
+	Facets      string `form:"facets"`       // comma separated: genre, author, language, decade
+}
+
+// BookListResult is one page of books, plus facet counts over all matching books when requested
+type BookListResult struct {
+	Books  []models.Book
+	Facets map[string][]repositories.FacetCount // keyed by facet name, nil when no facets were requested
+	pagination.PageInfo
+}
+
+// applyBookCursor switches the filter to keyset mode, the sort is taken from the cursor
+func applyBookCursor(filter *repositories.BookFilter, cursor *pagination.Cursor) error {
+	if !slices.Contains(repositories.BookKeysetSortKeys, cursor.Key) {
+		return pagination.ErrInvalidCursor
+	}
+
+	sort := repositories.SortField{Key: cursor.Key, Desc: cursor.Desc}
+	if len(filter.Sort) > 1 || (len(filter.Sort) == 1 && filter.Sort[0] != sort) {
+		return errors.New("cursor does not match the requested sort, cursors support a single sort field")
+	}
+
+	var value any
+	var err error
+	switch cursor.Key {
+	case "title":
+		value = cursor.Value
+	case "created_at", "updated_at":
+		if value, err = time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return pagination.ErrInvalidCursor
+		}
+	case "author_id":
+		if value, err = strconv.ParseUint(cursor.Value, 10, 32); err != nil {
+			return pagination.ErrInvalidCursor
+		}
+	}
+
+	filter.Sort = []repositories.SortField{sort}
+	filter.After = &repositories.Keyset{Value: value, ID: cursor.ID}
+	return nil
+}
+
+// bookNextCursor returns the cursor pointing after book, or "" when the sort has no keyset form
+func bookNextCursor(sort []repositories.SortField, book *models.Book) string {
+	cursor := pagination.Cursor{Key: "id", ID: book.ID}
+	if len(sort) > 1 {
+		return ""
+	}
+	if len(sort) == 1 {
+		if !slices.Contains(repositories.BookKeysetSortKeys, sort[0].Key) {
+			return ""
+		}
+		cursor.Key, cursor.Desc = sort[0].Key, sort[0].Desc
+	}
+
+	switch cursor.Key {
+	case "title":
+		cursor.Value = book.Title
+	case "created_at":
+		cursor.Value = book.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		cursor.Value = book.UpdatedAt.Format(time.RFC3339Nano)
+	case "author_id":
+		cursor.Value = strconv.FormatUint(uint64(book.AuthorID), 10)
+	}
+	return cursor.Encode()
+}
+
+// parseFacets validates a comma separated facet list against repositories.BookFacetNames
+func parseFacets(s string) ([]string, error) {
+	var facets []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" || slices.Contains(facets, part) {
+			continue
+		}
+		if !slices.Contains(repositories.BookFacetNames, part) {
+			return nil, fmt.Errorf("invalid facet [%s], allowed: %s", part, strings.Join(repositories.BookFacetNames, ", "))
+		}
+		facets = append(facets, part)
+	}
+	return facets, nil
+}
+
+// buildBookFilter validates the raw list query and converts it into a repository filter
+func buildBookFilter(query BookListQuery) (repositories.BookFilter, error) {
+	filter := repositories.BookFilter{
+		Title:     strings.TrimSpace(query.Title),
+		Language:  strings.ToLower(strings.TrimSpace(query.Language)),
+		Publisher: strings.TrimSpace(query.Publisher),
+		Tags:      ParseTagNames(query.Tags),
+	}
+
+	if query.AuthorID != "" {
+		id, err := strconv.ParseUint(query.AuthorID, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid author_id [%s]", query.AuthorID)
+		}
+		authorID := uint(id)
+		filter.AuthorID = &authorID
+	}
+
+	if query.GenreIDs != "" {
+		ids, err := parseIDList(query.GenreIDs)
+		if err != nil {
+			return filter, fmt.Errorf("invalid genre_ids: %w", err)
+		}
+		filter.GenreIDs = ids
+	}
+
+	switch query.GenreMatch {
+	case "", "any":
+	case "all":
+		filter.GenreMatchAll = true
+	default:
+		return filter, fmt.Errorf("invalid genre_match [%s], expected \"any\" or \"all\"", query.GenreMatch)
+	}
+
+	if query.CreatedFrom != "" {
+		from, _, err := parseDateParam(query.CreatedFrom)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_from: %w", err)
+		}
+		filter.CreatedFrom = &from
+	}
+
+	if query.CreatedTo != "" {
+		to, dateOnly, err := parseDateParam(query.CreatedTo)
+		if err != nil {
+			return filter, fmt.Errorf("invalid created_to: %w", err)
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1) // include the whole day
+		}
+		filter.CreatedTo = &to
+	}
+
+	sort, err := parseSort(query.Sort, repositories.BookSortColumns)
+	if err != nil {
+		return filter, err
+	}
+	filter.Sort = sort
+
+	return filter, nil
+}
+
+// parseIDList parses a comma separated list of positive IDs, duplicates are dropped and the
+// first occurrence keeps its place (filters such as genre_match=all count the IDs)
+func parseIDList(s string) ([]uint, error) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("[%s] is not a valid ID", part)
+		}
+		if seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// parseDateParam accepts YYYY-MM-DD or RFC3339, dateOnly tells which one was used
+func parseDateParam(s string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	if t, err = time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("[%s] is not a YYYY-MM-DD or RFC3339 date", s)
+}
+
+// parseSort parses "title,-created_at" into sort fields, rejecting keys outside the whitelist
+func parseSort(s string, allowed map[string]string) ([]repositories.SortField, error) {
+	var fields []repositories.SortField
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		field := repositories.SortField{Key: part}
+		if strings.HasPrefix(part, "-") {
+			field = repositories.SortField{Key: part[1:], Desc: true}
+		}
+
+		if _, ok := allowed[field.Key]; !ok {
+			keys := make([]string, 0, len(allowed))
+			for k := range allowed {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			return nil, fmt.Errorf("invalid sort field [%s], allowed: %s", field.Key, strings.Join(keys, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
 }
 
 func mapBook(bookCreateRequest BookCreateRequest, imageURL string) *models.Book {
 	return &models.Book{
-		Title:    bookCreateRequest.Title,
-		AuthorID: bookCreateRequest.AuthorId,
-		Image:    imageURL,
+		Title:           bookCreateRequest.Title,
+		Description:     bookCreateRequest.Description,
+		AuthorID:        bookCreateRequest.AuthorId,
+		Image:           imageURL,
+		SeriesID:        bookCreateRequest.SeriesId,
+		SeriesPosition:  bookCreateRequest.SeriesPosition,
+		Publisher:       bookCreateRequest.Publisher,
+		Language:        strings.ToLower(bookCreateRequest.Language),
+		Format:          models.BookFormat(bookCreateRequest.Format),
+		PublicationYear: bookCreateRequest.PublicationYear,
 	}
 }
 
+// normalizeOptionalISBN validates an ISBN and returns its ISBN-13 form, so both forms of an edition
+// hit the same unique index. An empty value means "no ISBN"
+func normalizeOptionalISBN(isbn string) (string, error) {
+	if strings.TrimSpace(isbn) == "" {
+		return "", nil
+	}
+	normalized, err := utils.NormalizeISBN(isbn)
+	if err != nil {
+		return "", fmt.Errorf("ISBN [%s] is not a valid ISBN-10 or ISBN-13", isbn)
+	}
+	return utils.ISBN10To13(normalized), nil
+}
+
 type IBookService interface {
 	GetBookByID(bookIdStr string) (*models.Book, int, error)
-	GetAllBooks(limitStr, offsetStr string) (*[]models.Book, int, error)
-	CreateBook(book BookCreateRequest) (*models.Book, error)
+	GetAllBooks(query BookListQuery, params pagination.Params, include []string) (*BookListResult, int, error)
+	CreateBook(book BookCreateRequest, enrich bool) (*models.Book, int, error)
+	LookupMetadata(req BookLookupRequest) (*BookLookupResult, int, error)
 	UpdateBook(bookIdStr string, book BookUpdateRequest) (*models.Book, int, error)
 	DeleteBook(bookIdStr string) (int, error)
+	ReplaceBookImage(bookIdStr string, req BookImageRequest) (*models.Book, int, error)
+	DeleteBookImage(bookIdStr string) (int, error)
 }
 
 type BookService struct {
 	repo           repositories.IBookRepository
 	authorRepo     repositories.IAuthorRepository
+	seriesRepo     repositories.ISeriesRepository
+	copyRepo       repositories.ICopyRepository
+	loanRepo       repositories.ILoanRepository
+	fileRepo       repositories.IBookFileRepository
+	holds          *holdQueue
 	CloudinaryUtil *utils.CloudinaryUtil
+	coverLimits    imaging.Limits
+	providers      []metadata.MetadataProvider
 	db             *gorm.DB
 }
 
@@ -60,56 +337,160 @@ func (s *BookService) GetBookByID(bookIdStr string) (*models.Book, int, error) {
 		return nil, http.StatusNotFound, err
 	}
 
+	if err := s.loadAvailability([]*models.Book{book}); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
 	return book, http.StatusOK, nil
 }
-func (s *BookService) GetAllBooks(limitStr, offsetStr string) (*[]models.Book, int, error) {
-	limit, _ := strconv.Atoi(limitStr)
-	offset, _ := strconv.Atoi(offsetStr)
 
-	if limit < 1 {
-		limit = 10
+// loadAvailability fills in the copy counts of books
+func (s *BookService) loadAvailability(books []*models.Book) error {
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+	availability, err := s.copyRepo.GetAvailability(s.db, ids)
+	if err != nil {
+		return err
 	}
-	if offset < 0 {
-		offset = 0
+	for _, book := range books {
+		counts := availability[book.ID]
+		book.Availability = &counts
+	}
+	return nil
+}
+func (s *BookService) GetAllBooks(query BookListQuery, params pagination.Params, include []string) (*BookListResult, int, error) {
+	filter, err := buildBookFilter(query)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if params.CursorMode() {
+		if err := applyBookCursor(&filter, params.Cursor); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
 	}
 
-	books, err := s.repo.GetAllBooks(s.db, uint(limit), uint(offset))
+	facets, err := parseFacets(query.Facets)
 	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// One extra row tells whether there is a next page
+	books, err := s.repo.GetAllBooks(s.db, uint(params.Limit+1), uint(params.Offset), filter, include)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	result := &BookListResult{Books: *books}
+	if len(result.Books) > params.Limit {
+		result.Books = result.Books[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = bookNextCursor(filter.Sort, &result.Books[params.Limit-1])
+	}
+
+	page := make([]*models.Book, len(result.Books))
+	for i := range result.Books {
+		page[i] = &result.Books[i]
+	}
+	if err := s.loadAvailability(page); err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 
-	return books, http.StatusOK, nil
+	if params.IncludeTotal {
+		total, err := s.repo.CountBooks(s.db, filter)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	if len(facets) > 0 {
+		counts, err := s.repo.GetBookFacets(s.db, filter, facets)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		// Every requested facet is present, even when no book matches
+		result.Facets = make(map[string][]repositories.FacetCount, len(facets))
+		for _, name := range facets {
+			result.Facets[name] = []repositories.FacetCount{}
+		}
+		for _, c := range counts {
+			result.Facets[c.Facet] = append(result.Facets[c.Facet], c)
+		}
+	}
+
+	return result, http.StatusOK, nil
 }
 
-func (s *BookService) CreateBook(bookCreateRequest BookCreateRequest) (*models.Book, error) {
-	_, err := s.authorRepo.GetAuthorByID(s.db, bookCreateRequest.AuthorId)
+func (s *BookService) CreateBook(bookCreateRequest BookCreateRequest, enrich bool) (*models.Book, int, error) {
+	// Everything that does not depend on the metadata is checked before any lookup or upload
+	_, err := s.authorRepo.FindAuthorByID(s.db, bookCreateRequest.AuthorId)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("author with ID [%d] does not exist", bookCreateRequest.AuthorId)
+			return nil, http.StatusNotFound, fmt.Errorf("author with ID [%d] does not exist", bookCreateRequest.AuthorId)
 		}
-		return nil, err
+		return nil, http.StatusInternalServerError, err
 	}
 
-	var imageURL string
-	if bookCreateRequest.Image != nil {
-		file, _ := bookCreateRequest.Image.Open()
-		defer file.Close()
+	if err := s.validateSeries(bookCreateRequest.SeriesId, bookCreateRequest.SeriesPosition); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
 
-		url, err := s.CloudinaryUtil.UploadImage(file, bookCreateRequest.Image, "books")
+	isbn, err := normalizeOptionalISBN(bookCreateRequest.ISBN)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// Refuse a duplicate before uploading, so no cover is left behind
+	if isbn != "" {
+		count, err := s.repo.CountBooksByISBN(s.db, isbn)
 		if err != nil {
-			return nil, fmt.Errorf("upload image failed: %w", err)
+			return nil, http.StatusInternalServerError, err
+		}
+		if count > 0 {
+			return nil, http.StatusConflict, fmt.Errorf("a book with ISBN [%s] already exists", isbn)
 		}
-		imageURL = url
 	}
 
-	bookModel := mapBook(bookCreateRequest, imageURL)
+	var coverURL string
+	if enrich {
+		coverURL = s.enrichBook(&bookCreateRequest)
+	}
+	if strings.TrimSpace(bookCreateRequest.Title) == "" {
+		return nil, http.StatusBadRequest, errors.New("title is required when it cannot be found by ISBN")
+	}
+
+	bookModel := mapBook(bookCreateRequest, coverURL)
+	bookModel.ISBN = isbn
+
+	// An uploaded image replaces the cover found by enrich
+	if bookCreateRequest.Image != nil {
+		cover, httpStatus, err := s.uploadCover(bookCreateRequest.Image)
+		if err != nil {
+			return nil, httpStatus, err
+		}
+		bookModel.Image = cover.URL
+		bookModel.ImagePublicID = cover.PublicID
+		bookModel.ImageVariants = cover.Variants
+	}
 
 	createdBook, err := s.repo.CreateBook(s.db, bookModel)
 	if err != nil {
-		return nil, err
+		// The uploaded object is orphaned unless another book already used the same file
+		if bookCreateRequest.Image != nil {
+			s.deleteCoverIfUnused(bookModel, 0)
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, http.StatusConflict, fmt.Errorf("a book with ISBN [%s] already exists", bookModel.ISBN)
+		}
+		return nil, http.StatusInternalServerError, err
 	}
-	s.db.Preload("Author").First(createdBook, createdBook.ID)
-	return createdBook, nil
+	s.db.Preload("Author").Preload("Series").First(createdBook, createdBook.ID)
+	createdBook.Availability = &models.CopyAvailability{} // a new book has no copies yet
+	return createdBook, http.StatusCreated, nil
 }
 
 func (s *BookService) UpdateBook(bookIdStr string, book BookUpdateRequest) (*models.Book, int, error) {
@@ -131,6 +512,10 @@ func (s *BookService) UpdateBook(bookIdStr string, book BookUpdateRequest) (*mod
 		bookObj.Title = *book.Title
 	}
 
+	if book.Description != nil {
+		bookObj.Description = *book.Description
+	}
+
 	if book.AuthorId != nil {
 		author, err := s.authorRepo.GetAuthorByID(s.db, *book.AuthorId)
 		if err != nil {
@@ -143,9 +528,68 @@ func (s *BookService) UpdateBook(bookIdStr string, book BookUpdateRequest) (*mod
 		bookObj.Author = *author
 	}
 
+	if book.SeriesId != nil {
+		if *book.SeriesId == 0 {
+			bookObj.SeriesID = nil
+			bookObj.Series = nil
+			bookObj.SeriesPosition = nil
+		} else {
+			series, err := s.seriesRepo.FindSeriesByID(s.db, *book.SeriesId)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil, http.StatusNotFound, fmt.Errorf("series with ID [%d] does not exist", *book.SeriesId)
+				}
+				return nil, http.StatusInternalServerError, err
+			}
+			// A position only means something within its series, a moved book needs a new one
+			if bookObj.SeriesID == nil || *bookObj.SeriesID != series.ID {
+				bookObj.SeriesPosition = nil
+			}
+			bookObj.SeriesID = &series.ID
+			bookObj.Series = series
+		}
+	}
+
+	if book.SeriesPosition != nil {
+		if bookObj.SeriesID == nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("seriesPosition requires the book to belong to a series")
+		}
+		if *book.SeriesPosition < 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("seriesPosition must not be negative")
+		}
+		bookObj.SeriesPosition = book.SeriesPosition
+	}
+
+	if book.ISBN != nil {
+		isbn, err := normalizeOptionalISBN(*book.ISBN)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		bookObj.ISBN = isbn
+	}
+	if book.Publisher != nil {
+		bookObj.Publisher = *book.Publisher
+	}
+	if book.Language != nil {
+		bookObj.Language = strings.ToLower(*book.Language)
+	}
+	if book.Format != nil {
+		bookObj.Format = models.BookFormat(*book.Format)
+	}
+	if book.PublicationYear != nil {
+		bookObj.PublicationYear = book.PublicationYear
+	}
+
 	savedBook, err := s.repo.UpdateBook(s.db, bookObj)
 
 	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, http.StatusConflict, fmt.Errorf("a book with ISBN [%s] already exists", bookObj.ISBN)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if err := s.loadAvailability([]*models.Book{savedBook}); err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
 
@@ -165,24 +609,126 @@ func (s *BookService) DeleteBook(bookIdStr string) (int, error) {
 		}
 		return http.StatusInternalServerError, err
 	}
-	deleteErr := s.repo.DeleteBook(s.db, bookObj)
+	var files []models.BookFile
+	httpStatus := http.StatusInternalServerError
+	deleteErr := s.db.Transaction(func(tx *gorm.DB) error {
+		// Holding the copies keeps a checkout from slipping in between the count and the delete
+		if _, err := s.copyRepo.LockCopiesByBookID(tx, bookObj.ID); err != nil {
+			return err
+		}
+		onLoan, err := s.loanRepo.CountLoans(tx, repositories.LoanFilter{BookID: &bookObj.ID, Status: repositories.LoanStatusActive})
+		if err != nil {
+			return err
+		}
+		if onLoan > 0 {
+			httpStatus = http.StatusConflict
+			return fmt.Errorf("book with ID [%d] has %d copies on loan, return them first", bookObj.ID, onLoan)
+		}
+
+		// Nobody can pick up a deleted book, its queue is cancelled
+		if err := s.cancelHolds(tx, bookObj.ID); err != nil {
+			return err
+		}
+
+		if files, err = s.fileRepo.GetFilesByBookID(tx, bookObj.ID); err != nil {
+			return err
+		}
+		if err := s.fileRepo.DeleteFilesByBookID(tx, bookObj.ID); err != nil {
+			return err
+		}
+		return s.repo.DeleteBook(tx, bookObj)
+	})
 	if deleteErr != nil {
-		return http.StatusInternalServerError, deleteErr
+		return httpStatus, deleteErr
 	}
 
+	s.deleteCoverIfUnused(bookObj, bookObj.ID)
+	s.deleteStoredFiles(files)
+
 	return http.StatusNoContent, nil
 }
 
+// deleteStoredFiles removes the attached files of a deleted book from storage. Each upload is its own
+// object, so nothing else uses them. Failures are only logged like for covers.
+func (s *BookService) deleteStoredFiles(files []models.BookFile) {
+	if s.CloudinaryUtil == nil {
+		return
+	}
+	for _, file := range files {
+		if err := s.CloudinaryUtil.DeleteFile(file.PublicID, file.ResourceType); err != nil {
+			log.Printf("❌ Failed to delete file %s: %v", file.PublicID, err)
+		}
+	}
+}
+
+// validateSeries checks the series exists and the position is usable for a new book
+func (s *BookService) validateSeries(seriesId *uint, position *float64) error {
+	if seriesId == nil {
+		if position != nil {
+			return fmt.Errorf("seriesPosition requires seriesId")
+		}
+		return nil
+	}
+
+	if _, err := s.seriesRepo.FindSeriesByID(s.db, *seriesId); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("series with ID [%d] does not exist", *seriesId)
+		}
+		return err
+	}
+
+	if position != nil && *position < 0 {
+		return fmt.Errorf("seriesPosition must not be negative")
+	}
+	return nil
+}
+
+// cancelHolds closes the open holds on a book. Waiting holds go first,
+// so the copies set aside for ready holds go back on the shelf rather than to the next hold.
+func (s *BookService) cancelHolds(tx *gorm.DB, bookID uint) error {
+	holds, err := s.holds.repo.LockOpenHoldsByBook(tx, bookID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, status := range []models.HoldStatus{models.HoldWaiting, models.HoldReady} {
+		for i := range holds {
+			if holds[i].Status != status {
+				continue
+			}
+			if err := s.holds.close(tx, &holds[i], models.HoldCancelled, now, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func NewBookService(
 	repo repositories.IBookRepository,
 	authorRepo repositories.IAuthorRepository,
+	seriesRepo repositories.ISeriesRepository,
+	copyRepo repositories.ICopyRepository,
+	loanRepo repositories.ILoanRepository,
+	holdRepo repositories.IHoldRepository,
+	fileRepo repositories.IBookFileRepository,
 	db *gorm.DB,
 	cloudUtil *utils.CloudinaryUtil,
+	coverLimits imaging.Limits,
+	providers []metadata.MetadataProvider,
+	pickupWindow time.Duration,
 ) IBookService {
 	return &BookService{
 		repo:           repo,
 		authorRepo:     authorRepo,
+		seriesRepo:     seriesRepo,
+		copyRepo:       copyRepo,
+		loanRepo:       loanRepo,
+		fileRepo:       fileRepo,
+		holds:          newHoldQueue(holdRepo, copyRepo, pickupWindow),
 		CloudinaryUtil: cloudUtil,
+		coverLimits:    coverLimits,
+		providers:      providers,
 		db:             db,
 	}
 }
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reasons a checkout, renewal or hold is refused, returned to clients as "reason"
+const (
+	ReasonCopyUnavailable     = "copy_unavailable"      // the copy is on loan, lost, damaged or in repair
+	ReasonCopyOnHold          = "copy_on_hold"          // the copy is set aside for another patron's hold
+	ReasonLoanLimitReached    = "loan_limit_reached"    // the patron has as many open loans as the policy allows
+	ReasonRenewalLimitReached = "renewal_limit_reached" // the loan was renewed as often as the policy allows
+	ReasonHoldPending         = "hold_pending"          // other patrons are waiting for the book, the loan cannot be renewed
+	ReasonLoanReturned        = "loan_returned"         // the loan is already closed
+	ReasonCopyAvailable       = "copy_available"        // a copy is on the shelf, check it out instead of holding
+	ReasonAlreadyBorrowed     = "already_borrowed"      // the patron has the book on loan
+	ReasonHoldExists          = "hold_exists"           // the patron already holds the book
+	ReasonHoldClosed          = "hold_closed"           // the hold was fulfilled, cancelled or expired
+	ReasonHoldBusy            = "hold_busy"             // another request is updating the patron's hold, try again
+)
+
+// CirculationRefusal is a checkout, renewal or hold refused by the circulation rules
+type CirculationRefusal struct {
+	Reason  string
+	Message string
+	Limit   *int // the limit that was hit, for the limit reasons
+}
+
+func (e *CirculationRefusal) Error() string {
+	return e.Message
+}
+
+func refuse(reason, message string) *CirculationRefusal {
+	return &CirculationRefusal{Reason: reason, Message: message}
+}
+
+func refuseLimit(reason, message string, limit int) *CirculationRefusal {
+	return &CirculationRefusal{Reason: reason, Message: message, Limit: &limit}
+}
+
+// resolvePolicy picks the most specific policy matching the patron and the item type,
+// the lowest id wins a tie whatever order policies come in. fallback applies when no policy matches.
+func resolvePolicy(policies []models.CirculationPolicy, user *models.User, itemType models.BookFormat, fallback models.CirculationPolicy) models.CirculationPolicy {
+	best := -1
+	for i := range policies {
+		if !policies[i].Matches(user, itemType) {
+			continue
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		specificity, bestSpecificity := policies[i].Specificity(), policies[best].Specificity()
+		if specificity > bestSpecificity || (specificity == bestSpecificity && policies[i].ID < policies[best].ID) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return fallback
+	}
+	return policies[best]
+}
+
+// loanPeriod is the length of a loan or a renewal under a policy
+func loanPeriod(policy models.CirculationPolicy) time.Duration {
+	return time.Duration(policy.LoanPeriodDays) * 24 * time.Hour
+}
+
+// holdQueue hands the copies coming back on the shelf to the patrons waiting for their book
+type holdQueue struct {
+	repo         repositories.IHoldRepository
+	copyRepo     repositories.ICopyRepository
+	pickupWindow time.Duration // how long a copy stays set aside for a ready hold
+}
+
+func newHoldQueue(repo repositories.IHoldRepository, copyRepo repositories.ICopyRepository, pickupWindow time.Duration) *holdQueue {
+	return &holdQueue{repo: repo, copyRepo: copyRepo, pickupWindow: pickupWindow}
+}
+
+// shelve makes a locked copy available, or sets it aside for the oldest waiting hold on its book.
+// It returns the hold that became ready, if any, and updates the status of bookCopy.
+func (q *holdQueue) shelve(tx *gorm.DB, bookCopy *models.Copy, now time.Time) (*models.Hold, error) {
+	hold, err := q.repo.LockNextWaitingHold(tx, bookCopy.BookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bookCopy.Status = models.CopyAvailable
+		return nil, q.copyRepo.UpdateCopyStatus(tx, bookCopy.ID, models.CopyAvailable)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(q.pickupWindow)
+	hold.Status = models.HoldReady
+	hold.CopyID = &bookCopy.ID
+	hold.ReadyAt = &now
+	hold.ExpiresAt = &expiresAt
+	if _, err := q.repo.UpdateHold(tx, hold); err != nil {
+		return nil, err
+	}
+	bookCopy.Status = models.CopyOnHold
+	return hold, q.copyRepo.UpdateCopyStatus(tx, bookCopy.ID, models.CopyOnHold)
+}
+
+// release puts back the copy a closed hold had set aside, it goes to the next hold in the queue
+func (q *holdQueue) release(tx *gorm.DB, copyID *uint, now time.Time) error {
+	if copyID == nil {
+		return nil
+	}
+	bookCopy, err := q.copyRepo.LockCopy(tx, *copyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // the copy was withdrawn meanwhile
+	}
+	if err != nil {
+		return err
+	}
+	// Staff may have marked the copy lost or damaged meanwhile
+	if bookCopy.Status != models.CopyOnHold {
+		return nil
+	}
+	_, err = q.shelve(tx, bookCopy, now)
+	return err
+}
+
+// close ends an open hold with the given status, a copy set aside for it is released unless keepCopy
+func (q *holdQueue) close(tx *gorm.DB, hold *models.Hold, status models.HoldStatus, now time.Time, keepCopy bool) error {
+	copyID := hold.CopyID
+	hold.Status = status
+	hold.ClosedAt = &now
+	if _, err := q.repo.UpdateHold(tx, hold); err != nil {
+		return err
+	}
+	if keepCopy {
+		return nil
+	}
+	return q.release(tx, copyID, now)
+}
+
+// expire closes the ready holds that were not picked up in time, their copies go to the next patrons.
+// Expiry is applied lazily, before the operations that depend on the state of the queue.
+func (q *holdQueue) expire(db *gorm.DB, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		holds, err := q.repo.LockExpiredHolds(tx, now)
+		if err != nil {
+			return err
+		}
+		for i := range holds {
+			if err := q.close(tx, &holds[i], models.HoldExpired, now, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type CirculationPolicyCreateRequest struct {
+	Name               string `json:"name" binding:"required,max=100"`
+	Role               string `json:"role" binding:"omitempty,oneof=admin user"`                                    // empty matches every role
+	PatronCategory     string `json:"patronCategory" binding:"max=30"`                                              // empty matches every category
+	ItemType           string `json:"itemType" binding:"omitempty,oneof=hardcover paperback ebook audiobook other"` // empty matches every format
+	LoanPeriodDays     int    `json:"loanPeriodDays" binding:"required,min=1,max=365"`
+	MaxRenewals        int    `json:"maxRenewals" binding:"min=0,max=100"`
+	MaxConcurrentLoans *int   `json:"maxConcurrentLoans" binding:"omitempty,min=0"` // omitted means no limit
+}
+
+type CirculationPolicyUpdateRequest struct {
+	Name               *string `json:"name" binding:"omitempty,max=100"`
+	Role               *string `json:"role" binding:"omitempty,oneof='' admin user"`
+	PatronCategory     *string `json:"patronCategory" binding:"omitempty,max=30"`
+	ItemType           *string `json:"itemType" binding:"omitempty,oneof='' hardcover paperback ebook audiobook other"`
+	LoanPeriodDays     *int    `json:"loanPeriodDays" binding:"omitempty,min=1,max=365"`
+	MaxRenewals        *int    `json:"maxRenewals" binding:"omitempty,min=0,max=100"`
+	MaxConcurrentLoans *int    `json:"maxConcurrentLoans" binding:"omitempty,min=-1"` // -1 removes the limit
+}
+
+// PatronCategoryRequest sets the patron category of a user, empty clears it
+type PatronCategoryRequest struct {
+	PatronCategory string `json:"patronCategory" binding:"max=30"`
+}
+
+type ICirculationPolicyService interface {
+	GetAllPolicies() ([]models.CirculationPolicy, int, error)
+	GetPolicyByID(policyIdStr string) (*models.CirculationPolicy, int, error)
+	CreatePolicy(req CirculationPolicyCreateRequest) (*models.CirculationPolicy, int, error)
+	UpdatePolicy(policyIdStr string, req CirculationPolicyUpdateRequest) (*models.CirculationPolicy, int, error)
+	DeletePolicy(policyIdStr string) (int, error)
+	SetPatronCategory(userIdStr string, req PatronCategoryRequest) (*models.User, int, error)
+}
+
+type CirculationPolicyService struct {
+	repo     repositories.ICirculationPolicyRepository
+	userRepo repositories.IUserRepository
+	db       *gorm.DB
+}
+
+func (s *CirculationPolicyService) GetAllPolicies() ([]models.CirculationPolicy, int, error) {
+	policies, err := s.repo.GetAllPolicies(s.db)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return policies, http.StatusOK, nil
+}
+
+func (s *CirculationPolicyService) GetPolicyByID(policyIdStr string) (*models.CirculationPolicy, int, error) {
+	id, err := strconv.ParseUint(policyIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	policy, err := s.repo.GetPolicyByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("circulation policy with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return policy, http.StatusOK, nil
+}
+
+func (s *CirculationPolicyService) CreatePolicy(req CirculationPolicyCreateRequest) (*models.CirculationPolicy, int, error) {
+	policy := &models.CirculationPolicy{
+		Name:               strings.TrimSpace(req.Name),
+		Role:               models.Role(req.Role),
+		PatronCategory:     normalizePatronCategory(req.PatronCategory),
+		ItemType:           models.BookFormat(req.ItemType),
+		LoanPeriodDays:     req.LoanPeriodDays,
+		MaxRenewals:        req.MaxRenewals,
+		MaxConcurrentLoans: req.MaxConcurrentLoans,
+	}
+	if policy.Name == "" {
+		return nil, http.StatusBadRequest, errors.New("policy name must not be empty")
+	}
+
+	created, err := s.repo.CreatePolicy(s.db, policy)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, http.StatusConflict, errors.New("a policy with the same role, patron category and item type already exists")
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return created, http.StatusCreated, nil
+}
+
+func (s *CirculationPolicyService) UpdatePolicy(policyIdStr string, req CirculationPolicyUpdateRequest) (*models.CirculationPolicy, int, error) {
+	policy, httpStatus, err := s.GetPolicyByID(policyIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, http.StatusBadRequest, errors.New("policy name must not be empty")
+		}
+		policy.Name = name
+	}
+	if req.Role != nil {
+		policy.Role = models.Role(*req.Role)
+	}
+	if req.PatronCategory != nil {
+		policy.PatronCategory = normalizePatronCategory(*req.PatronCategory)
+	}
+	if req.ItemType != nil {
+		policy.ItemType = models.BookFormat(*req.ItemType)
+	}
+	if req.LoanPeriodDays != nil {
+		policy.LoanPeriodDays = *req.LoanPeriodDays
+	}
+	if req.MaxRenewals != nil {
+		policy.MaxRenewals = *req.MaxRenewals
+	}
+	if req.MaxConcurrentLoans != nil {
+		if *req.MaxConcurrentLoans < 0 {
+			policy.MaxConcurrentLoans = nil
+		} else {
+			policy.MaxConcurrentLoans = req.MaxConcurrentLoans
+		}
+	}
+
+	updated, err := s.repo.UpdatePolicy(s.db, policy)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, http.StatusConflict, errors.New("a policy with the same role, patron category and item type already exists")
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return updated, http.StatusOK, nil
+}
+
+func (s *CirculationPolicyService) DeletePolicy(policyIdStr string) (int, error) {
+	policy, httpStatus, err := s.GetPolicyByID(policyIdStr)
+	if err != nil {
+		return httpStatus, err
+	}
+	if err := s.repo.DeletePolicy(s.db, policy); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func (s *CirculationPolicyService) SetPatronCategory(userIdStr string, req PatronCategoryRequest) (*models.User, int, error) {
+	id, err := strconv.ParseUint(userIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	user, err := s.userRepo.FindByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("user with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	category := normalizePatronCategory(req.PatronCategory)
+	if err := s.userRepo.UpdatePatronCategory(s.db, user, category); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	user.PatronCategory = category
+	return user, http.StatusOK, nil
+}
+
+// normalizePatronCategory lowercases and trims a category so policies and users compare equal
+func normalizePatronCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+func NewCirculationPolicyService(repo repositories.ICirculationPolicyRepository, userRepo repositories.IUserRepository, db *gorm.DB) ICirculationPolicyService {
+	return &CirculationPolicyService{repo: repo, userRepo: userRepo, db: db}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"book-management/internal/repositories"
+	"book-management/pkg/citation"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxCitationBooks = 100
+
+type ICitationService interface {
+	// GetCitationWorks loads the comma separated book IDs as citable works, in the requested order
+	GetCitationWorks(idsStr string) ([]citation.Work, int, error)
+}
+
+type CitationService struct {
+	bookRepo repositories.IBookRepository
+	db       *gorm.DB
+}
+
+func NewCitationService(bookRepo repositories.IBookRepository, db *gorm.DB) ICitationService {
+	return &CitationService{bookRepo: bookRepo, db: db}
+}
+
+func (s *CitationService) GetCitationWorks(idsStr string) ([]citation.Work, int, error) {
+	// parseIDList already drops repeated IDs anywhere in the list, keeping the first one in place
+	ids, err := parseIDList(idsStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(ids) == 0 {
+		return nil, http.StatusBadRequest, errors.New("at least one book ID is required")
+	}
+	if len(ids) > maxCitationBooks {
+		return nil, http.StatusBadRequest, fmt.Errorf("too many books, the limit is %d", maxCitationBooks)
+	}
+
+	books, err := s.bookRepo.GetAllBooks(s.db, uint(len(ids)), 0, repositories.BookFilter{IDs: ids}, []string{"author", "series"})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	works := make(map[uint]citation.Work, len(*books))
+	for _, b := range *books {
+		work := citation.Work{
+			ID:        b.ID,
+			Title:     b.Title,
+			Publisher: b.Publisher,
+			Year:      b.PublicationYear,
+			ISBN:      b.ISBN,
+			Language:  b.Language,
+		}
+		if b.Author.Name != "" {
+			work.Authors = []citation.Name{citation.ParseName(b.Author.Name)}
+		}
+		if b.Series != nil {
+			work.Series = b.Series.Name
+			work.SeriesNumber = b.SeriesPosition
+		}
+		works[b.ID] = work
+	}
+
+	result := make([]citation.Work, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		work, ok := works[id]
+		if !ok {
+			missing = append(missing, strconv.FormatUint(uint64(id), 10))
+			continue
+		}
+		result = append(result, work)
+	}
+	if len(missing) > 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("books with IDs [%s] do not exist", strings.Join(missing, ", "))
+	}
+
+	return result, http.StatusOK, nil
+}
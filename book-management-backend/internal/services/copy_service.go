@@ -0,0 +1,299 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CopyCreateRequest struct {
+	Barcode          string   `json:"barcode" binding:"required,max=50"`
+	ShelfLocation    string   `json:"shelfLocation" binding:"max=100"`
+	Condition        string   `json:"condition" binding:"omitempty,oneof=new good fair poor"`            // defaults to good
+	Status           string   `json:"status" binding:"omitempty,oneof=available lost damaged in_repair"` // defaults to available, on_loan and on_hold are set by circulation
+	AcquiredAt       string   `json:"acquiredAt"`                                                        // YYYY-MM-DD
+	AcquisitionPrice *float64 `json:"acquisitionPrice" binding:"omitempty,min=0"`
+	Notes            string   `json:"notes"`
+}
+
+type CopyUpdateRequest struct {
+	Barcode          *string  `json:"barcode" binding:"omitempty,max=50"`
+	ShelfLocation    *string  `json:"shelfLocation" binding:"omitempty,max=100"`
+	Condition        *string  `json:"condition" binding:"omitempty,oneof=new good fair poor"`
+	Status           *string  `json:"status" binding:"omitempty,oneof=available lost damaged in_repair"` // not while the copy is on loan or on hold
+	AcquiredAt       *string  `json:"acquiredAt"`                                                        // YYYY-MM-DD, empty string clears the date
+	AcquisitionPrice *float64 `json:"acquisitionPrice" binding:"omitempty,min=0"`
+	Notes            *string  `json:"notes"`
+}
+
+type ICopyService interface {
+	GetCopies(bookIdStr string) ([]models.Copy, int, error)
+	GetCopy(bookIdStr, copyIdStr string) (*models.Copy, int, error)
+	CreateCopy(bookIdStr string, req CopyCreateRequest) (*models.Copy, int, error)
+	UpdateCopy(bookIdStr, copyIdStr string, req CopyUpdateRequest) (*models.Copy, int, error)
+	DeleteCopy(bookIdStr, copyIdStr string) (int, error)
+}
+
+type CopyService struct {
+	repo     repositories.ICopyRepository
+	bookRepo repositories.IBookRepository
+	loanRepo repositories.ILoanRepository
+	holds    *holdQueue
+	db       *gorm.DB
+}
+
+func (s *CopyService) GetCopies(bookIdStr string) ([]models.Copy, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	copies, err := s.repo.GetCopiesByBookID(s.db, book.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return copies, http.StatusOK, nil
+}
+
+func (s *CopyService) GetCopy(bookIdStr, copyIdStr string) (*models.Copy, int, error) {
+	return s.getBookCopy(s.db, bookIdStr, copyIdStr, false)
+}
+
+func (s *CopyService) CreateCopy(bookIdStr string, req CopyCreateRequest) (*models.Copy, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	barcode := strings.TrimSpace(req.Barcode)
+	if barcode == "" {
+		return nil, http.StatusBadRequest, errors.New("barcode must not be empty")
+	}
+	acquiredAt, err := parseAcquiredAt(req.AcquiredAt)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	bookCopy := &models.Copy{
+		BookID:           book.ID,
+		Barcode:          barcode,
+		ShelfLocation:    strings.TrimSpace(req.ShelfLocation),
+		Condition:        models.ConditionGood,
+		Status:           models.CopyAvailable,
+		AcquiredAt:       acquiredAt,
+		AcquisitionPrice: req.AcquisitionPrice,
+		Notes:            req.Notes,
+	}
+	if req.Condition != "" {
+		bookCopy.Condition = models.CopyCondition(req.Condition)
+	}
+	if req.Status != "" {
+		bookCopy.Status = models.CopyStatus(req.Status)
+	}
+
+	httpStatus = http.StatusInternalServerError
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.repo.CreateCopy(tx, bookCopy); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				httpStatus = http.StatusConflict
+				return fmt.Errorf("a copy with barcode [%s] already exists", barcode)
+			}
+			return err
+		}
+		// A new copy goes to the next patron waiting for the book, if any
+		if bookCopy.Status == models.CopyAvailable {
+			_, err := s.holds.shelve(tx, bookCopy, time.Now())
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	return bookCopy, http.StatusCreated, nil
+}
+
+func (s *CopyService) UpdateCopy(bookIdStr, copyIdStr string, req CopyUpdateRequest) (*models.Copy, int, error) {
+	var barcode string
+	if req.Barcode != nil {
+		if barcode = strings.TrimSpace(*req.Barcode); barcode == "" {
+			return nil, http.StatusBadRequest, errors.New("barcode must not be empty")
+		}
+	}
+	var acquiredAt *time.Time
+	if req.AcquiredAt != nil {
+		var err error
+		if acquiredAt, err = parseAcquiredAt(*req.AcquiredAt); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	var bookCopy *models.Copy
+	httpStatus := http.StatusInternalServerError
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The row lock keeps checkout, return and holds from changing the copy meanwhile
+		var err error
+		if bookCopy, httpStatus, err = s.getBookCopy(tx, bookIdStr, copyIdStr, true); err != nil {
+			return err
+		}
+		httpStatus = http.StatusInternalServerError
+
+		if req.Barcode != nil {
+			bookCopy.Barcode = barcode
+		}
+		if req.ShelfLocation != nil {
+			bookCopy.ShelfLocation = strings.TrimSpace(*req.ShelfLocation)
+		}
+		if req.Condition != nil {
+			bookCopy.Condition = models.CopyCondition(*req.Condition)
+		}
+		if req.AcquiredAt != nil {
+			bookCopy.AcquiredAt = acquiredAt
+		}
+		if req.AcquisitionPrice != nil {
+			bookCopy.AcquisitionPrice = req.AcquisitionPrice
+		}
+		if req.Notes != nil {
+			bookCopy.Notes = *req.Notes
+		}
+
+		status := bookCopy.Status
+		if req.Status != nil && models.CopyStatus(*req.Status) != bookCopy.Status {
+			if code, err := s.checkNotInCirculation(tx, bookCopy); err != nil {
+				httpStatus = code
+				return err
+			}
+			status = models.CopyStatus(*req.Status)
+		}
+
+		if _, err := s.repo.UpdateCopy(tx, bookCopy); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				httpStatus = http.StatusConflict
+				return fmt.Errorf("a copy with barcode [%s] already exists", bookCopy.Barcode)
+			}
+			return err
+		}
+
+		switch {
+		case status == bookCopy.Status:
+			return nil
+		case status == models.CopyAvailable:
+			// A copy back on the shelf goes to the next patron waiting for the book, if any
+			_, err := s.holds.shelve(tx, bookCopy, time.Now())
+			return err
+		default:
+			bookCopy.Status = status
+			return s.repo.UpdateCopyStatus(tx, bookCopy.ID, status)
+		}
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	return bookCopy, http.StatusOK, nil
+}
+
+func (s *CopyService) DeleteCopy(bookIdStr, copyIdStr string) (int, error) {
+	httpStatus := http.StatusInternalServerError
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		bookCopy, code, err := s.getBookCopy(tx, bookIdStr, copyIdStr, true)
+		if err != nil {
+			httpStatus = code
+			return err
+		}
+		if code, err := s.checkNotInCirculation(tx, bookCopy); err != nil {
+			httpStatus = code
+			return err
+		}
+		return s.repo.DeleteCopy(tx, bookCopy)
+	})
+	if err != nil {
+		return httpStatus, err
+	}
+	return http.StatusNoContent, nil
+}
+
+func (s *CopyService) getBook(bookIdStr string) (*models.Book, int, error) {
+	bookID, err := strconv.ParseUint(bookIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	book, err := s.bookRepo.GetBookById(s.db, uint(bookID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", bookID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return book, http.StatusOK, nil
+}
+
+// getBookCopy loads a copy, making sure it belongs to the book in the path.
+// lock loads it with SELECT ... FOR UPDATE, db must then be a transaction.
+func (s *CopyService) getBookCopy(db *gorm.DB, bookIdStr, copyIdStr string, lock bool) (*models.Copy, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	copyID, err := strconv.ParseUint(copyIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	var bookCopy *models.Copy
+	if lock {
+		bookCopy, err = s.repo.LockCopy(db, uint(copyID))
+	} else {
+		bookCopy, err = s.repo.GetCopyByID(db, uint(copyID))
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusInternalServerError, err
+	}
+	if bookCopy == nil || bookCopy.BookID != book.ID {
+		return nil, http.StatusNotFound, fmt.Errorf("copy with ID [%d] does not exist for book [%d]", copyID, book.ID)
+	}
+	return bookCopy, http.StatusOK, nil
+}
+
+// checkNotInCirculation refuses changes to a copy that is lent or set aside for a hold,
+// its status is driven by checkout, return and holds
+func (s *CopyService) checkNotInCirculation(db *gorm.DB, bookCopy *models.Copy) (int, error) {
+	if bookCopy.Status == models.CopyOnHold {
+		return http.StatusConflict, fmt.Errorf("copy [%s] is set aside for a hold, cancel the hold first", bookCopy.Barcode)
+	}
+
+	onLoan := bookCopy.Status == models.CopyOnLoan
+	if !onLoan {
+		var err error
+		if onLoan, err = s.loanRepo.HasOpenLoan(db, bookCopy.ID); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	if onLoan {
+		return http.StatusConflict, fmt.Errorf("copy [%s] is on loan, return it first", bookCopy.Barcode)
+	}
+	return http.StatusOK, nil
+}
+
+// parseAcquiredAt reads an optional YYYY-MM-DD date
+func parseAcquiredAt(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, fmt.Errorf("acquiredAt must be a date as YYYY-MM-DD")
+	}
+	return &t, nil
+}
+
+func NewCopyService(repo repositories.ICopyRepository, bookRepo repositories.IBookRepository, loanRepo repositories.ILoanRepository,
+	holdRepo repositories.IHoldRepository, db *gorm.DB, pickupWindow time.Duration) ICopyService {
+	return &CopyService{repo: repo, bookRepo: bookRepo, loanRepo: loanRepo, holds: newHoldQueue(holdRepo, repo, pickupWindow), db: db}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"book-management/internal/repositories"
+	"book-management/pkg/export"
+	"book-management/pkg/marc"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Book-only export formats, in addition to the tabular formats of pkg/export
+const (
+	exportFormatMARC21  = "marc21"
+	exportFormatMARCXML = "marcxml"
+)
+
+var (
+	bookExportColumns = []string{
+		"id", "title", "description", "isbn", "author_id", "author_name", "genres", "tags",
+		"series", "series_position", "publisher", "language", "format", "publication_year", "image",
+		"created_at", "updated_at",
+	}
+	authorExportColumns = []string{"id", "name", "email", "book_count", "created_at", "updated_at"}
+	genreExportColumns  = []string{"id", "name", "slug", "book_count", "created_at", "updated_at"}
+)
+
+// Export is a validated export, the database is only read once Write is called
+type Export struct {
+	ContentType string
+	FileName    string
+	write       func(w io.Writer) error
+}
+
+// Write streams the whole export to w
+func (e *Export) Write(w io.Writer) error {
+	return e.write(w)
+}
+
+// bookSource calls fn for every exported book
+type bookSource func(fn func(*repositories.BookExportRow) error) error
+
+type IExportService interface {
+	// ExportBooks accepts the same filters and sort as the book list, pagination does not apply
+	ExportBooks(formatStr string, query BookListQuery) (*Export, int, error)
+	ExportBook(bookIdStr string, formatStr string) (*Export, int, error)
+	ExportAuthors(formatStr string) (*Export, int, error)
+	ExportGenres(formatStr string) (*Export, int, error)
+}
+
+type ExportService struct {
+	repo repositories.IExportRepository
+	db   *gorm.DB
+}
+
+func NewExportService(repo repositories.IExportRepository, db *gorm.DB) IExportService {
+	return &ExportService{repo: repo, db: db}
+}
+
+func (s *ExportService) ExportBooks(formatStr string, query BookListQuery) (*Export, int, error) {
+	filter, err := buildBookFilter(query)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	exp, err := bookExport(formatStr, "books", func(fn func(*repositories.BookExportRow) error) error {
+		return s.repo.StreamBooks(s.db, filter, fn)
+	})
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return exp, http.StatusOK, nil
+}
+
+func (s *ExportService) ExportBook(bookIdStr string, formatStr string) (*Export, int, error) {
+	id, err := strconv.ParseUint(bookIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	row, err := s.repo.GetBookRow(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	exp, err := bookExport(formatStr, fmt.Sprintf("book-%d", id), func(fn func(*repositories.BookExportRow) error) error {
+		return fn(row)
+	})
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return exp, http.StatusOK, nil
+}
+
+func (s *ExportService) ExportAuthors(formatStr string) (*Export, int, error) {
+	format, err := export.ParseFormat(formatStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return tabularExport(format, "authors", authorExportColumns, "Authors", func(w export.Writer) error {
+		return s.repo.StreamAuthors(s.db, func(a *repositories.NamedExportRow) error {
+			return w.Write([]any{a.ID, a.Name, a.Email, a.BookCount, a.CreatedAt, a.UpdatedAt})
+		})
+	}), http.StatusOK, nil
+}
+
+func (s *ExportService) ExportGenres(formatStr string) (*Export, int, error) {
+	format, err := export.ParseFormat(formatStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return tabularExport(format, "genres", genreExportColumns, "Genres", func(w export.Writer) error {
+		return s.repo.StreamGenres(s.db, func(g *repositories.NamedExportRow) error {
+			return w.Write([]any{g.ID, g.Name, g.Slug, g.BookCount, g.CreatedAt, g.UpdatedAt})
+		})
+	}), http.StatusOK, nil
+}
+
+// bookExport writes books as MARC or in one of the tabular formats
+func bookExport(formatStr, baseName string, source bookSource) (*Export, error) {
+	switch strings.ToLower(strings.TrimSpace(formatStr)) {
+	case exportFormatMARC21:
+		return &Export{
+			ContentType: "application/marc",
+			FileName:    exportFileName(baseName, "mrc"),
+			write: func(w io.Writer) error {
+				return source(func(b *repositories.BookExportRow) error {
+					data, err := marc.Marshal(bookToMARC(b))
+					if err != nil {
+						return fmt.Errorf("book [%d]: %w", b.ID, err)
+					}
+					_, err = w.Write(data)
+					return err
+				})
+			},
+		}, nil
+	case exportFormatMARCXML:
+		return &Export{
+			ContentType: "application/marcxml+xml",
+			FileName:    exportFileName(baseName, "xml"),
+			write: func(w io.Writer) error {
+				xw, err := marc.NewXMLWriter(w)
+				if err != nil {
+					return err
+				}
+				if err := source(func(b *repositories.BookExportRow) error {
+					return xw.Write(bookToMARC(b))
+				}); err != nil {
+					return err
+				}
+				return xw.Close()
+			},
+		}, nil
+	}
+
+	format, err := export.ParseFormat(formatStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format [%s], allowed: csv, jsonl, xlsx, marc21, marcxml", formatStr)
+	}
+
+	return tabularExport(format, baseName, bookExportColumns, "Books", func(w export.Writer) error {
+		return source(func(b *repositories.BookExportRow) error {
+			return w.Write([]any{
+				b.ID, b.Title, b.Description, b.ISBN, b.AuthorID, b.AuthorName, b.GenreNames, b.TagNames,
+				b.SeriesName, b.SeriesPosition, b.Publisher, b.Language, b.Format, b.PublicationYear, b.Image,
+				b.CreatedAt, b.UpdatedAt,
+			})
+		})
+	}), nil
+}
+
+// tabularExport writes rows with a pkg/export writer
+func tabularExport(format export.Format, baseName string, columns []string, sheet string, stream func(w export.Writer) error) *Export {
+	return &Export{
+		ContentType: format.ContentType(),
+		FileName:    exportFileName(baseName, string(format)),
+		write: func(w io.Writer) error {
+			ew, err := export.NewWriter(format, w, columns, sheet)
+			if err != nil {
+				return err
+			}
+			if err := stream(ew); err != nil {
+				return err
+			}
+			return ew.Close()
+		},
+	}
+}
+
+// exportFileName builds a download name such as "books-20260131.csv"
+func exportFileName(baseName, extension string) string {
+	return fmt.Sprintf("%s-%s.%s", baseName, time.Now().Format("20060102"), extension)
+}
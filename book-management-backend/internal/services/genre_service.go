@@ -3,19 +3,23 @@ package services
 import (
 	"book-management/internal/models"
 	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"book-management/pkg/utils"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
 type GenreCreateRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name string `json:"name" binding:"required,max=100"`
 }
 
 type GenreUpdateRequest struct {
-	Name *string `json:"name"`
+	Name *string `json:"name" binding:"omitempty,max=100"`
 }
 
 // ManageBooksRequest is used for adding/removing/replacing books in a genre
@@ -23,10 +27,18 @@ type ManageBooksRequest struct {
 	BookIDs []uint `json:"book_ids" binding:"required"`
 }
 
+// GenreListResult is one page of genres ordered by id
+type GenreListResult struct {
+	Genres []models.Genre
+	pagination.PageInfo
+}
+
 type IGenreService interface {
 	GetGenreByID(genreIdStr string) (*models.Genre, int, error)
-	GetAllGenres(limitStr, offsetStr string) (*[]models.Genre, int, error)
-	CreateGenre(req GenreCreateRequest) (*models.Genre, error)
+	GetGenreBySlug(slug string) (*models.Genre, int, error)
+	GetAllGenres(params pagination.Params, include []string) (*GenreListResult, int, error)
+	// CreateGenre returns the existing genre with http.StatusConflict when the name is already taken
+	CreateGenre(req GenreCreateRequest) (*models.Genre, int, error)
 	UpdateGenre(genreIdStr string, req GenreUpdateRequest) (*models.Genre, int, error)
 	DeleteGenre(genreIdStr string) (int, error)
 
@@ -56,27 +68,84 @@ func (s *GenreService) GetGenreByID(genreIdStr string) (*models.Genre, int, erro
 	return genre, http.StatusOK, nil
 }
 
-func (s *GenreService) GetAllGenres(limitStr, offsetStr string) (*[]models.Genre, int, error) {
-	limit, _ := strconv.Atoi(limitStr)
-	offset, _ := strconv.Atoi(offsetStr)
-
-	if limit < 1 {
-		limit = 10
+func (s *GenreService) GetGenreBySlug(slug string) (*models.Genre, int, error) {
+	genre, err := s.repo.GetGenreBySlug(s.db, slug)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, http.StatusNotFound, fmt.Errorf("genre with slug [%s] does not exist", slug)
+		}
+		return nil, http.StatusInternalServerError, err
 	}
-	if offset < 0 {
-		offset = 0
+	return genre, http.StatusOK, nil
+}
+
+func (s *GenreService) GetAllGenres(params pagination.Params, include []string) (*GenreListResult, int, error) {
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
-	genres, err := s.repo.GetAllGenres(s.db, uint(limit), uint(offset))
+	// One extra row tells whether there is a next page
+	genres, err := s.repo.GetAllGenres(s.db, uint(params.Limit+1), uint(params.Offset), afterID, include)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
 	}
-	return genres, http.StatusOK, nil
+
+	result := &GenreListResult{Genres: *genres}
+	if len(result.Genres) > params.Limit {
+		result.Genres = result.Genres[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Genres[params.Limit-1].ID)
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountGenres(s.db)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	return result, http.StatusOK, nil
 }
 
-func (s *GenreService) CreateGenre(req GenreCreateRequest) (*models.Genre, error) {
-	genre := &models.Genre{Name: req.Name}
-	return s.repo.CreateGenre(s.db, genre)
+func (s *GenreService) CreateGenre(req GenreCreateRequest) (*models.Genre, int, error) {
+	normalizedName := utils.NormalizeName(req.Name)
+	if normalizedName == "" {
+		return nil, http.StatusBadRequest, errors.New("genre name must not be empty")
+	}
+
+	existing, err := s.findGenreByName(normalizedName, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if existing != nil {
+		return existing, http.StatusConflict, fmt.Errorf("genre [%s] already exists", existing.Name)
+	}
+
+	slug, err := s.generateSlug(req.Name, 0)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	genre := &models.Genre{
+		Name:           strings.Join(strings.Fields(req.Name), " "),
+		NormalizedName: normalizedName,
+		Slug:           slug,
+	}
+
+	created, err := s.repo.CreateGenre(s.db, genre)
+	if err != nil {
+		// Lost a race against a concurrent insert of the same name
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if existing, _ := s.findGenreByName(normalizedName, 0); existing != nil {
+				return existing, http.StatusConflict, fmt.Errorf("genre [%s] already exists", existing.Name)
+			}
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return created, http.StatusCreated, nil
 }
 
 func (s *GenreService) UpdateGenre(genreIdStr string, req GenreUpdateRequest) (*models.Genre, int, error) {
@@ -94,11 +163,35 @@ func (s *GenreService) UpdateGenre(genreIdStr string, req GenreUpdateRequest) (*
 	}
 
 	if req.Name != nil {
-		genre.Name = *req.Name
+		normalizedName := utils.NormalizeName(*req.Name)
+		if normalizedName == "" {
+			return nil, http.StatusBadRequest, errors.New("genre name must not be empty")
+		}
+
+		existing, err := s.findGenreByName(normalizedName, genre.ID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if existing != nil {
+			return existing, http.StatusConflict, fmt.Errorf("genre [%s] already exists", existing.Name)
+		}
+
+		if normalizedName != genre.NormalizedName {
+			slug, err := s.generateSlug(*req.Name, genre.ID)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			genre.Slug = slug
+		}
+		genre.Name = strings.Join(strings.Fields(*req.Name), " ")
+		genre.NormalizedName = normalizedName
 	}
 
 	updated, err := s.repo.UpdateGenre(s.db, genre)
 	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, http.StatusConflict, err
+		}
 		return nil, http.StatusInternalServerError, err
 	}
 	return updated, http.StatusOK, nil
@@ -125,6 +218,49 @@ func (s *GenreService) DeleteGenre(genreIdStr string) (int, error) {
 	return http.StatusNoContent, nil
 }
 
+// findGenreByName returns the genre using normalizedName (other than excludeID), or nil if the name is free
+func (s *GenreService) findGenreByName(normalizedName string, excludeID uint) (*models.Genre, error) {
+	genre, err := s.repo.GetGenreByNormalizedName(s.db, normalizedName)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if genre.ID == excludeID {
+		return nil, nil
+	}
+	return genre, nil
+}
+
+// generateSlug builds a slug from name, appending -2, -3, ... until it is not used by another genre
+func (s *GenreService) generateSlug(name string, excludeID uint) (string, error) {
+	return generateGenreSlug(s.db, s.repo, name, excludeID)
+}
+
+// generateGenreSlug slugifies name and appends -2, -3... until no other genre uses it
+func generateGenreSlug(db *gorm.DB, repo repositories.IGenreRepository, name string, excludeID uint) (string, error) {
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "genre"
+	}
+
+	slug := base
+	for i := 2; ; i++ {
+		genre, err := repo.FindGenreBySlug(db, slug)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return slug, nil
+			}
+			return "", err
+		}
+		if genre.ID == excludeID {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
 // =============================
 // Relationship Management
 // =============================
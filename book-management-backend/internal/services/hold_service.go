@@ -0,0 +1,289 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HoldCreateRequest places a hold on a book, the body may be omitted
+type HoldCreateRequest struct {
+	UserID *uint `json:"userId"` // patron, admin only, defaults to the caller
+}
+
+// HoldListQuery holds the raw query parameters of the hold listing
+type HoldListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open waiting ready fulfilled cancelled expired"`
+}
+
+// HoldListResult is one page of holds ordered by id
+type HoldListResult struct {
+	Holds []models.Hold
+	pagination.PageInfo
+}
+
+type IHoldService interface {
+	// PlaceHold queues a patron for a book, actorID is the caller and isAdmin tells whether it may hold for someone else
+	PlaceHold(bookIdStr string, req HoldCreateRequest, actorID uint, isAdmin bool) (*models.Hold, int, error)
+	// GetBookHolds lists the open holds on a book in queue order
+	GetBookHolds(bookIdStr string) ([]models.Hold, int, error)
+	// GetMyHolds lists the holds of one user (GET /me/holds)
+	GetMyHolds(query HoldListQuery, params pagination.Params, userID uint) (*HoldListResult, int, error)
+	// CancelHold closes an open hold, patrons can only cancel their own holds
+	CancelHold(holdIdStr string, actorID uint, isAdmin bool) (*models.Hold, int, error)
+}
+
+type HoldService struct {
+	repo     repositories.IHoldRepository
+	bookRepo repositories.IBookRepository
+	copyRepo repositories.ICopyRepository
+	loanRepo repositories.ILoanRepository
+	userRepo repositories.IUserRepository
+	holds    *holdQueue
+	db       *gorm.DB
+}
+
+func (s *HoldService) PlaceHold(bookIdStr string, req HoldCreateRequest, actorID uint, isAdmin bool) (*models.Hold, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	patronID := actorID
+	if req.UserID != nil && *req.UserID != actorID {
+		if !isAdmin {
+			return nil, http.StatusForbidden, errors.New("only admins can place a hold for another user")
+		}
+		patronID = *req.UserID
+	}
+	if _, err := s.userRepo.FindByID(s.db, patronID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("user with ID [%d] does not exist", patronID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// A pickup that expired may put a copy back on the shelf
+	if err := s.holds.expire(s.db, time.Now()); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	borrowed, err := s.loanRepo.CountLoans(s.db, repositories.LoanFilter{
+		UserID: &patronID,
+		BookID: &book.ID,
+		Status: repositories.LoanStatusActive,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if borrowed > 0 {
+		return nil, http.StatusConflict, refuse(ReasonAlreadyBorrowed, fmt.Sprintf("user [%d] has book [%d] on loan", patronID, book.ID))
+	}
+
+	var hold *models.Hold
+	httpStatus = http.StatusInternalServerError
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// A return of the last copy has to either be seen here or see the new hold when it shelves the copy
+		copies, err := s.copyRepo.LockCopiesByBookID(tx, book.ID)
+		if err != nil {
+			return err
+		}
+		available := 0
+		for _, bookCopy := range copies {
+			if bookCopy.Status == models.CopyAvailable {
+				available++
+			}
+		}
+		if available > 0 {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonCopyAvailable, fmt.Sprintf("%d copies of book [%d] are available, check one out instead", available, book.ID))
+		}
+
+		hold, err = s.repo.CreateHold(tx, &models.Hold{
+			BookID:    book.ID,
+			UserID:    patronID,
+			Status:    models.HoldWaiting,
+			CreatedBy: actorID,
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonHoldExists, fmt.Sprintf("user [%d] already holds book [%d]", patronID, book.ID))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	hold, httpStatus, err = s.getHold(hold.ID)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	return hold, http.StatusCreated, nil
+}
+
+func (s *HoldService) GetBookHolds(bookIdStr string) ([]models.Hold, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	if err := s.holds.expire(s.db, time.Now()); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	holds, err := s.repo.GetOpenHoldsByBook(s.db, book.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err := s.loadQueuePositions(holds); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return holds, http.StatusOK, nil
+}
+
+func (s *HoldService) GetMyHolds(query HoldListQuery, params pagination.Params, userID uint) (*HoldListResult, int, error) {
+	filter := repositories.HoldFilter{UserID: &userID, Status: query.Status}
+
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	if err := s.holds.expire(s.db, time.Now()); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// One extra row tells whether there is a next page
+	holds, err := s.repo.GetHolds(s.db, filter, uint(params.Limit+1), uint(params.Offset), afterID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	result := &HoldListResult{Holds: holds}
+	if len(result.Holds) > params.Limit {
+		result.Holds = result.Holds[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Holds[params.Limit-1].ID)
+	}
+	if err := s.loadQueuePositions(result.Holds); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountHolds(s.db, filter)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (s *HoldService) CancelHold(holdIdStr string, actorID uint, isAdmin bool) (*models.Hold, int, error) {
+	id, err := strconv.ParseUint(holdIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	httpStatus := http.StatusInternalServerError
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		hold, err := s.repo.LockHold(tx, uint(id))
+		// Someone else's hold is reported as missing rather than forbidden
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !isAdmin && hold.UserID != actorID) {
+			httpStatus = http.StatusNotFound
+			return fmt.Errorf("hold with ID [%d] does not exist", id)
+		}
+		if err != nil {
+			return err
+		}
+		if hold.ClosedAt != nil {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonHoldClosed, fmt.Sprintf("hold with ID [%d] is already %s", id, hold.Status))
+		}
+		// A copy set aside for the hold goes to the next patron in the queue
+		return s.holds.close(tx, hold, models.HoldCancelled, time.Now(), false)
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	return s.getHold(uint(id))
+}
+
+func (s *HoldService) getHold(holdID uint) (*models.Hold, int, error) {
+	hold, err := s.repo.GetHoldByID(s.db, holdID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("hold with ID [%d] does not exist", holdID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	holds := []models.Hold{*hold}
+	if err := s.loadQueuePositions(holds); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return &holds[0], http.StatusOK, nil
+}
+
+func (s *HoldService) getBook(bookIdStr string) (*models.Book, int, error) {
+	bookID, err := strconv.ParseUint(bookIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	book, err := s.bookRepo.GetBookById(s.db, uint(bookID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", bookID)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return book, http.StatusOK, nil
+}
+
+// loadQueuePositions fills QueuePosition on the waiting holds
+func (s *HoldService) loadQueuePositions(holds []models.Hold) error {
+	var waiting []uint
+	for i := range holds {
+		if holds[i].Status == models.HoldWaiting {
+			waiting = append(waiting, holds[i].ID)
+		}
+	}
+	positions, err := s.repo.GetQueuePositions(s.db, waiting)
+	if err != nil {
+		return err
+	}
+	for i := range holds {
+		if position, ok := positions[holds[i].ID]; ok {
+			holds[i].QueuePosition = &position
+		}
+	}
+	return nil
+}
+
+func NewHoldService(
+	repo repositories.IHoldRepository,
+	bookRepo repositories.IBookRepository,
+	copyRepo repositories.ICopyRepository,
+	loanRepo repositories.ILoanRepository,
+	userRepo repositories.IUserRepository,
+	db *gorm.DB,
+	pickupWindow time.Duration,
+) IHoldService {
+	return &HoldService{
+		repo:     repo,
+		bookRepo: bookRepo,
+		copyRepo: copyRepo,
+		loanRepo: loanRepo,
+		userRepo: userRepo,
+		holds:    newHoldQueue(repo, copyRepo, pickupWindow),
+		db:       db,
+	}
+}
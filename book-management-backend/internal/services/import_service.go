@@ -0,0 +1,567 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/marc"
+	"book-management/pkg/utils"
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+/*
+Bulk import of books from CSV or MARC (MARC21 binary / MARCXML):
+- The upload is parsed and validated synchronously, rows (CSV lines or MARC records) are then
+  processed by a background goroutine while the job row in the database reports progress.
+- Each row is imported in its own transaction: a bad row is recorded in the error report and
+  never leaves a half-created author, genre or book behind.
+- Missing authors (matched by email, else by name) and genres (matched by normalized name) are created.
+- MARC imports also get a mapping report: which fields were found and what they were imported as.
+*/
+
+const (
+	maxImportFileSize     = 20 << 20 // 20 MB
+	maxImportRows         = 50000
+	importProgressEvery   = 100 // rows between two progress updates
+	importGenreSeparators = "|;"
+)
+
+// Columns of the books CSV, matched case-insensitively, unknown columns are ignored
+const (
+	importColTitle       = "title"
+	importColDescription = "description"
+	importColAuthorName  = "author_name"
+	importColAuthorEmail = "author_email"
+	importColGenres      = "genres" // separated by | or ;
+	importColISBN        = "isbn"
+	importColImageURL    = "image_url"
+)
+
+// Import formats, stored on the job
+const (
+	importFormatCSV     = "csv"
+	importFormatMARC21  = "marc21"
+	importFormatMARCXML = "marcxml"
+)
+
+// marcImportHeader names the values of a MARC record shown in the error report
+var marcImportHeader = []string{"control_number", "title", "isbn"}
+
+// importedBook is a book read from a CSV line or a MARC record, ready to be saved
+type importedBook struct {
+	Title           string
+	Description     string
+	AuthorName      string
+	AuthorEmail     string
+	GenreNames      []string
+	ISBN            string // normalized
+	ImageURL        string
+	Publisher       string
+	Language        string
+	PublicationYear *int
+}
+
+// importRow is one CSV line or MARC record of the uploaded file
+type importRow struct {
+	Line   int
+	Values []string                      // source values, repeated in the error report
+	parse  func() (*importedBook, error) // validates and maps the row
+}
+
+type IImportService interface {
+	// ImportBooks validates the CSV header and starts a background job, returns http.StatusAccepted
+	ImportBooks(file *multipart.FileHeader, userID uint) (*models.ImportJob, int, error)
+	// ImportMARC reads a MARC21 binary or MARCXML file (detected from its content) and starts a background job
+	ImportMARC(file *multipart.FileHeader, userID uint) (*models.ImportJob, int, error)
+	GetJob(jobIdStr string) (*models.ImportJob, int, error)
+	GetErrorReport(jobIdStr string) (*models.ImportJob, []models.ImportRowError, int, error)
+	// FailInterruptedJobs marks the jobs a previous process left pending or running as failed,
+	// their rows only lived in its memory so they cannot be resumed. Call it once at startup.
+	FailInterruptedJobs() (int64, error)
+}
+
+type ImportService struct {
+	repo       repositories.IImportRepository
+	bookRepo   repositories.IBookRepository
+	authorRepo repositories.IAuthorRepository
+	genreRepo  repositories.IGenreRepository
+	db         *gorm.DB
+}
+
+func NewImportService(
+	repo repositories.IImportRepository,
+	bookRepo repositories.IBookRepository,
+	authorRepo repositories.IAuthorRepository,
+	genreRepo repositories.IGenreRepository,
+	db *gorm.DB,
+) IImportService {
+	return &ImportService{
+		repo:       repo,
+		bookRepo:   bookRepo,
+		authorRepo: authorRepo,
+		genreRepo:  genreRepo,
+		db:         db,
+	}
+}
+
+func (s *ImportService) ImportBooks(fileHeader *multipart.FileHeader, userID uint) (*models.ImportJob, int, error) {
+	file, httpStatus, err := openImportFile(fileHeader)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	defer file.Close()
+
+	header, rows, err := readImportCSV(file)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns[importColTitle]; !ok {
+		return nil, http.StatusBadRequest, fmt.Errorf("missing required column [%s]", importColTitle)
+	}
+	_, hasName := columns[importColAuthorName]
+	_, hasEmail := columns[importColAuthorEmail]
+	if !hasName && !hasEmail {
+		return nil, http.StatusBadRequest, fmt.Errorf("missing column [%s] or [%s]", importColAuthorName, importColAuthorEmail)
+	}
+
+	for i := range rows {
+		values := rows[i].Values
+		rows[i].parse = func() (*importedBook, error) {
+			return parseCSVBook(columns, values)
+		}
+	}
+
+	return s.startImport(&models.ImportJob{
+		Kind:      "books",
+		Format:    importFormatCSV,
+		FileName:  fileHeader.Filename,
+		Header:    header,
+		CreatedBy: userID,
+	}, rows)
+}
+
+func (s *ImportService) ImportMARC(fileHeader *multipart.FileHeader, userID uint) (*models.ImportJob, int, error) {
+	file, httpStatus, err := openImportFile(fileHeader)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+	defer file.Close()
+
+	format, records, err := readImportMARC(file)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	rows := make([]importRow, len(records))
+	for i, rec := range records {
+		rows[i] = importRow{
+			Line:   i + 1,
+			Values: marcRowValues(rec),
+			parse: func() (*importedBook, error) {
+				return mapMARCBook(rec)
+			},
+		}
+	}
+
+	return s.startImport(&models.ImportJob{
+		Kind:      "books",
+		Format:    format,
+		FileName:  fileHeader.Filename,
+		Header:    marcImportHeader,
+		Mapping:   marcMappingReport(records),
+		CreatedBy: userID,
+	}, rows)
+}
+
+func (s *ImportService) GetJob(jobIdStr string) (*models.ImportJob, int, error) {
+	id, err := strconv.ParseUint(jobIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	job, err := s.repo.GetJobByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("import job with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return job, http.StatusOK, nil
+}
+
+func (s *ImportService) GetErrorReport(jobIdStr string) (*models.ImportJob, []models.ImportRowError, int, error) {
+	job, httpStatus, err := s.GetJob(jobIdStr)
+	if err != nil {
+		return nil, nil, httpStatus, err
+	}
+
+	rowErrors, err := s.repo.GetRowErrors(s.db, job.ID)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, err
+	}
+	return job, rowErrors, http.StatusOK, nil
+}
+
+// openImportFile enforces the upload size limit before opening the file
+func openImportFile(fileHeader *multipart.FileHeader) (multipart.File, int, error) {
+	if fileHeader.Size > maxImportFileSize {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("file is too large, the limit is %d MB", maxImportFileSize>>20)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return file, http.StatusOK, nil
+}
+
+// readImportCSV reads the header and all data rows, keeping the line number of each row
+func readImportCSV(r io.Reader) ([]string, []importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // short rows are reported per row instead of failing the whole file
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, errors.New("the file is empty")
+		}
+		return nil, nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // UTF-8 BOM written by Excel
+
+	var rows []importRow
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, fmt.Errorf("too many rows, the limit is %d per file", maxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow{Line: line, Values: values})
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, errors.New("the file has no data rows")
+	}
+	return header, rows, nil
+}
+
+// readImportMARC reads every record of a MARCXML (starts with "<") or MARC21 binary file
+func readImportMARC(r io.Reader) (string, []*marc.Record, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\ufeff" {
+		br.Discard(3)
+	}
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return "", nil, errors.New("the file is empty")
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			break
+		}
+		br.ReadByte()
+	}
+
+	format := importFormatMARC21
+	if b, _ := br.Peek(1); b[0] == '<' {
+		format = importFormatMARCXML
+	}
+
+	var read func() (*marc.Record, error)
+	if format == importFormatMARCXML {
+		read = marc.NewXMLReader(br).Read
+	} else {
+		read = marc.NewReader(br).Read
+	}
+
+	var records []*marc.Record
+	for {
+		rec, err := read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if len(records) == maxImportRows {
+			return "", nil, fmt.Errorf("too many records, the limit is %d per file", maxImportRows)
+		}
+		records = append(records, rec)
+	}
+
+	if len(records) == 0 {
+		return "", nil, errors.New("the file has no MARC records")
+	}
+	return format, records, nil
+}
+
+// startImport saves a pending job and processes its rows in the background
+func (s *ImportService) startImport(job *models.ImportJob, rows []importRow) (*models.ImportJob, int, error) {
+	job.Status = models.ImportStatusPending
+	job.TotalRows = len(rows)
+	if err := s.repo.CreateJob(s.db, job); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	go s.runImport(*job, rows)
+
+	return job, http.StatusAccepted, nil
+}
+
+// runImport processes the rows of a job, it runs in its own goroutine
+func (s *ImportService) runImport(job models.ImportJob, rows []importRow) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ Import job %d crashed: %v", job.ID, r)
+			s.finishJob(&job, models.ImportStatusFailed, fmt.Sprintf("internal error: %v", r))
+		}
+	}()
+
+	now := time.Now()
+	job.Status = models.ImportStatusRunning
+	job.StartedAt = &now
+	if err := s.repo.UpdateJob(s.db, &job); err != nil {
+		log.Printf("❌ Import job %d could not start: %v", job.ID, err)
+		return
+	}
+
+	var pending []models.ImportRowError
+	for _, row := range rows {
+		if err := s.importRow(row); err != nil {
+			job.FailedRows++
+			pending = append(pending, models.ImportRowError{
+				ImportJobID: job.ID,
+				Line:        row.Line,
+				Message:     err.Error(),
+				Values:      row.Values,
+			})
+		} else {
+			job.CreatedRows++
+		}
+		job.ProcessedRows++
+
+		if job.ProcessedRows%importProgressEvery == 0 {
+			if err := s.flushProgress(&job, pending); err != nil {
+				s.finishJob(&job, models.ImportStatusFailed, err.Error())
+				return
+			}
+			pending = nil
+		}
+	}
+
+	if err := s.repo.AddRowErrors(s.db, pending); err != nil {
+		s.finishJob(&job, models.ImportStatusFailed, err.Error())
+		return
+	}
+	s.finishJob(&job, models.ImportStatusCompleted, "")
+}
+
+func (s *ImportService) FailInterruptedJobs() (int64, error) {
+	return s.repo.FailUnfinishedJobs(s.db, "interrupted by a server restart, upload the file again", time.Now())
+}
+
+func (s *ImportService) flushProgress(job *models.ImportJob, rowErrors []models.ImportRowError) error {
+	if err := s.repo.AddRowErrors(s.db, rowErrors); err != nil {
+		return err
+	}
+	return s.repo.UpdateJob(s.db, job)
+}
+
+func (s *ImportService) finishJob(job *models.ImportJob, status models.ImportStatus, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Error = message
+	job.FinishedAt = &now
+	if err := s.repo.UpdateJob(s.db, job); err != nil {
+		log.Printf("❌ Import job %d could not be finished: %v", job.ID, err)
+	}
+}
+
+func (s *ImportService) importRow(row importRow) error {
+	book, err := row.parse()
+	if err != nil {
+		return err
+	}
+	return s.saveImportedBook(book)
+}
+
+// parseCSVBook validates one CSV row
+func parseCSVBook(columns map[string]int, values []string) (*importedBook, error) {
+	get := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(values) {
+			return ""
+		}
+		return strings.TrimSpace(values[i])
+	}
+
+	book := &importedBook{
+		Title:       get(importColTitle),
+		Description: get(importColDescription),
+		AuthorName:  strings.Join(strings.Fields(get(importColAuthorName)), " "),
+		AuthorEmail: strings.ToLower(get(importColAuthorEmail)),
+		ImageURL:    get(importColImageURL),
+		GenreNames: strings.FieldsFunc(get(importColGenres), func(r rune) bool {
+			return strings.ContainsRune(importGenreSeparators, r)
+		}),
+	}
+
+	if book.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if book.AuthorName == "" && book.AuthorEmail == "" {
+		return nil, fmt.Errorf("%s or %s is required", importColAuthorName, importColAuthorEmail)
+	}
+	if book.AuthorEmail != "" {
+		if _, err := mail.ParseAddress(book.AuthorEmail); err != nil {
+			return nil, fmt.Errorf("author_email [%s] is not a valid email", book.AuthorEmail)
+		}
+	}
+
+	isbn, err := normalizeOptionalISBN(get(importColISBN))
+	if err != nil {
+		return nil, err
+	}
+	book.ISBN = isbn
+
+	if book.ImageURL != "" && !isHTTPURL(book.ImageURL) {
+		return nil, fmt.Errorf("image_url [%s] is not a valid http(s) URL", book.ImageURL)
+	}
+	return book, nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// saveImportedBook creates the book, its author and genres in a single transaction
+func (s *ImportService) saveImportedBook(b *importedBook) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		author, err := s.findOrCreateAuthor(tx, b.AuthorName, b.AuthorEmail)
+		if err != nil {
+			return err
+		}
+
+		genres, err := s.findOrCreateGenres(tx, b.GenreNames)
+		if err != nil {
+			return err
+		}
+
+		book := &models.Book{
+			Title:           b.Title,
+			Description:     b.Description,
+			AuthorID:        author.ID,
+			Image:           b.ImageURL,
+			ISBN:            b.ISBN,
+			Publisher:       b.Publisher,
+			Language:        b.Language,
+			PublicationYear: b.PublicationYear,
+			Genres:          genres,
+		}
+		if _, err := s.bookRepo.CreateBook(tx, book); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("a book with ISBN [%s] already exists", b.ISBN)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// findOrCreateAuthor matches by email first, then by name. A missing author needs a name to be created,
+// the email is optional.
+func (s *ImportService) findOrCreateAuthor(tx *gorm.DB, name, email string) (*models.Author, error) {
+	var (
+		author *models.Author
+		err    error
+	)
+	if email != "" {
+		author, err = s.authorRepo.GetAuthorByEmail(tx, email)
+	} else {
+		author, err = s.authorRepo.GetAuthorByName(tx, name)
+	}
+	if err == nil {
+		return author, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("author with email [%s] does not exist, author_name is required to create it", email)
+	}
+
+	author = &models.Author{Name: name, Email: email}
+	if err := s.authorRepo.CreateAuthor(tx, author); err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// findOrCreateGenres matches genres by normalized name and creates the missing ones
+func (s *ImportService) findOrCreateGenres(tx *gorm.DB, names []string) ([]models.Genre, error) {
+	genres := []models.Genre{}
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		normalizedName := utils.NormalizeName(name)
+		if normalizedName == "" || seen[normalizedName] {
+			continue
+		}
+		seen[normalizedName] = true
+		// Same limit as the genre API, the normalized name column holds 100 characters
+		if utf8.RuneCountInString(name) > 100 {
+			return nil, fmt.Errorf("genre [%s] is longer than 100 characters", name)
+		}
+
+		genre, err := s.genreRepo.GetGenreByNormalizedName(tx, normalizedName)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+
+			slug, err := generateGenreSlug(tx, s.genreRepo, name, 0)
+			if err != nil {
+				return nil, err
+			}
+			genre, err = s.genreRepo.CreateGenre(tx, &models.Genre{
+				Name:           name,
+				NormalizedName: normalizedName,
+				Slug:           slug,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		genres = append(genres, *genre)
+	}
+	return genres, nil
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoanCreateRequest checks out the copy with the given barcode
+type LoanCreateRequest struct {
+	Barcode string `json:"barcode" binding:"required"`
+	UserID  *uint  `json:"userId"` // borrower, admin only, defaults to the caller (self-checkout)
+}
+
+// LoanListQuery holds the raw query parameters of the loan listings
+type LoanListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=active overdue returned"`
+	UserID string `form:"user_id"` // admin listing only
+	BookID string `form:"book_id"`
+	CopyID string `form:"copy_id"`
+}
+
+// LoanListResult is one page of loans ordered by id
+type LoanListResult struct {
+	Loans []models.Loan
+	pagination.PageInfo
+}
+
+type ILoanService interface {
+	GetLoanByID(loanIdStr string) (*models.Loan, int, error)
+	// GetLoans lists loans, userID limits the listing to the loans of one user (GET /me/loans)
+	GetLoans(query LoanListQuery, params pagination.Params, userID *uint) (*LoanListResult, int, error)
+	// Checkout lends a copy, actorID is the caller and isAdmin tells whether it may lend to someone else
+	Checkout(req LoanCreateRequest, actorID uint, isAdmin bool) (*models.Loan, int, error)
+	ReturnLoan(loanIdStr string, actorID uint) (*models.Loan, int, error)
+	// RenewLoan extends an open loan, borrowers can only renew their own loans
+	RenewLoan(loanIdStr string, actorID uint, isAdmin bool) (*models.Loan, int, error)
+}
+
+type LoanService struct {
+	repo          repositories.ILoanRepository
+	copyRepo      repositories.ICopyRepository
+	userRepo      repositories.IUserRepository
+	bookRepo      repositories.IBookRepository
+	policyRepo    repositories.ICirculationPolicyRepository
+	defaultPolicy models.CirculationPolicy // applies when no policy matches
+	holds         *holdQueue
+	db            *gorm.DB
+}
+
+func (s *LoanService) GetLoanByID(loanIdStr string) (*models.Loan, int, error) {
+	id, err := strconv.ParseUint(loanIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	loan, err := s.repo.GetLoanByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusNotFound, fmt.Errorf("loan with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return loan, http.StatusOK, nil
+}
+
+func (s *LoanService) GetLoans(query LoanListQuery, params pagination.Params, userID *uint) (*LoanListResult, int, error) {
+	filter := repositories.LoanFilter{Status: query.Status, Now: time.Now(), UserID: userID}
+	var err error
+	if userID == nil {
+		if filter.UserID, err = parseOptionalID("user_id", query.UserID); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+	if filter.BookID, err = parseOptionalID("book_id", query.BookID); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if filter.CopyID, err = parseOptionalID("copy_id", query.CopyID); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// One extra row tells whether there is a next page
+	loans, err := s.repo.GetLoans(s.db, filter, uint(params.Limit+1), uint(params.Offset), afterID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	result := &LoanListResult{Loans: loans}
+	if len(result.Loans) > params.Limit {
+		result.Loans = result.Loans[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Loans[params.Limit-1].ID)
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountLoans(s.db, filter)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (s *LoanService) Checkout(req LoanCreateRequest, actorID uint, isAdmin bool) (*models.Loan, int, error) {
+	barcode := strings.TrimSpace(req.Barcode)
+	if barcode == "" {
+		return nil, http.StatusBadRequest, errors.New("barcode must not be empty")
+	}
+
+	borrowerID := actorID
+	if req.UserID != nil && *req.UserID != actorID {
+		if !isAdmin {
+			return nil, http.StatusForbidden, errors.New("only admins can check out a copy for another user")
+		}
+		borrowerID = *req.UserID
+	}
+
+	if err := s.holds.expire(s.db, time.Now()); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	var loan *models.Loan
+	httpStatus := http.StatusInternalServerError
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Row locks serialize concurrent checkouts by the same borrower (loan limit) and of the same copy
+		borrower, err := s.userRepo.LockByID(tx, borrowerID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				httpStatus = http.StatusNotFound
+				return fmt.Errorf("user with ID [%d] does not exist", borrowerID)
+			}
+			return err
+		}
+
+		bookCopy, err := s.copyRepo.LockCopyByBarcode(tx, barcode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				httpStatus = http.StatusNotFound
+				return fmt.Errorf("copy with barcode [%s] does not exist", barcode)
+			}
+			return err
+		}
+		switch bookCopy.Status {
+		case models.CopyAvailable:
+		case models.CopyOnHold:
+			// Only the patron the copy is set aside for can check it out
+			hold, err := s.holds.repo.LockOpenHold(tx, borrower.ID, bookCopy.BookID)
+			if errors.Is(err, repositories.ErrHoldLocked) {
+				httpStatus = http.StatusConflict
+				return refuse(ReasonHoldBusy, fmt.Sprintf("the hold of user [%d] on this book is being updated, try again", borrower.ID))
+			}
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if hold == nil || hold.CopyID == nil || *hold.CopyID != bookCopy.ID {
+				httpStatus = http.StatusConflict
+				return refuse(ReasonCopyOnHold, fmt.Sprintf("copy [%s] is set aside for another patron's hold", barcode))
+			}
+		default:
+			httpStatus = http.StatusConflict
+			return refuse(ReasonCopyUnavailable, fmt.Sprintf("copy [%s] is not available (status: %s)", barcode, bookCopy.Status))
+		}
+
+		policy, err := s.policyFor(tx, borrower, bookCopy.BookID)
+		if err != nil {
+			return err
+		}
+		if policy.MaxConcurrentLoans != nil {
+			open, err := s.repo.CountOpenLoans(tx, borrower.ID, policy.ItemType)
+			if err != nil {
+				return err
+			}
+			if open >= int64(*policy.MaxConcurrentLoans) {
+				httpStatus = http.StatusConflict
+				return refuseLimit(ReasonLoanLimitReached,
+					fmt.Sprintf("user [%d] already has %d open loans, the limit is %d", borrower.ID, open, *policy.MaxConcurrentLoans),
+					*policy.MaxConcurrentLoans)
+			}
+		}
+
+		now := time.Now()
+		loan, err = s.repo.CreateLoan(tx, &models.Loan{
+			CopyID:       bookCopy.ID,
+			BookID:       bookCopy.BookID,
+			UserID:       borrower.ID,
+			CheckedOutAt: now,
+			DueAt:        now.Add(loanPeriod(policy)),
+			CheckedOutBy: actorID,
+			PolicyID:     policyID(policy),
+		})
+		if err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				httpStatus = http.StatusConflict
+				return refuse(ReasonCopyUnavailable, fmt.Sprintf("copy [%s] is already on loan", barcode))
+			}
+			return err
+		}
+		if err := s.copyRepo.UpdateCopyStatus(tx, bookCopy.ID, models.CopyOnLoan); err != nil {
+			return err
+		}
+
+		// The checkout fulfils the borrower's hold on the book, another copy set aside for them goes back to the queue
+		hold, err := s.holds.repo.LockOpenHold(tx, borrower.ID, bookCopy.BookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if errors.Is(err, repositories.ErrHoldLocked) {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonHoldBusy, fmt.Sprintf("the hold of user [%d] on this book is being updated, try again", borrower.ID))
+		}
+		if err != nil {
+			return err
+		}
+		keepCopy := hold.CopyID != nil && *hold.CopyID == bookCopy.ID
+		return s.holds.close(tx, hold, models.HoldFulfilled, now, keepCopy)
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	loan, err = s.repo.GetLoanByID(s.db, loan.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return loan, http.StatusCreated, nil
+}
+
+func (s *LoanService) ReturnLoan(loanIdStr string, actorID uint) (*models.Loan, int, error) {
+	id, err := strconv.ParseUint(loanIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	httpStatus := http.StatusInternalServerError
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		loan, err := s.repo.LockLoan(tx, uint(id))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				httpStatus = http.StatusNotFound
+				return fmt.Errorf("loan with ID [%d] does not exist", id)
+			}
+			return err
+		}
+		if loan.ReturnedAt != nil {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonLoanReturned, fmt.Sprintf("loan with ID [%d] was already returned", id))
+		}
+
+		bookCopy, err := s.copyRepo.LockCopy(tx, loan.CopyID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		loan.ReturnedAt = &now
+		loan.ReturnedBy = &actorID
+		if _, err := s.repo.UpdateLoan(tx, loan); err != nil {
+			return err
+		}
+
+		// Only a copy still marked on loan goes back on the shelf, or to the next patron waiting for the book
+		if bookCopy != nil && bookCopy.Status == models.CopyOnLoan {
+			_, err := s.holds.shelve(tx, bookCopy, now)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	return s.GetLoanByID(loanIdStr)
+}
+
+func (s *LoanService) RenewLoan(loanIdStr string, actorID uint, isAdmin bool) (*models.Loan, int, error) {
+	id, err := strconv.ParseUint(loanIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	httpStatus := http.StatusInternalServerError
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		loan, err := s.repo.LockLoan(tx, uint(id))
+		// Someone else's loan is reported as missing rather than forbidden
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !isAdmin && loan.UserID != actorID) {
+			httpStatus = http.StatusNotFound
+			return fmt.Errorf("loan with ID [%d] does not exist", id)
+		}
+		if err != nil {
+			return err
+		}
+		if loan.ReturnedAt != nil {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonLoanReturned, fmt.Sprintf("loan with ID [%d] was already returned", id))
+		}
+
+		borrower, err := s.userRepo.FindByID(tx, loan.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			borrower = &models.User{} // deleted patron, only criteria-free policies match
+		} else if err != nil {
+			return err
+		}
+		policy, err := s.policyFor(tx, borrower, loan.BookID)
+		if err != nil {
+			return err
+		}
+		if loan.RenewalCount >= policy.MaxRenewals {
+			httpStatus = http.StatusConflict
+			return refuseLimit(ReasonRenewalLimitReached,
+				fmt.Sprintf("loan with ID [%d] was renewed %d times, the limit is %d", id, loan.RenewalCount, policy.MaxRenewals),
+				policy.MaxRenewals)
+		}
+		waiting, err := s.holds.repo.CountWaitingHolds(tx, loan.BookID)
+		if err != nil {
+			return err
+		}
+		if waiting > 0 {
+			httpStatus = http.StatusConflict
+			return refuse(ReasonHoldPending, fmt.Sprintf("%d patrons are waiting for book [%d], the loan cannot be renewed", waiting, loan.BookID))
+		}
+
+		// A renewal adds a loan period to the due date, or to today when the loan is overdue
+		start := time.Now()
+		if loan.DueAt.After(start) {
+			start = loan.DueAt
+		}
+		loan.DueAt = start.Add(loanPeriod(policy))
+		loan.RenewalCount++
+		loan.PolicyID = policyID(policy)
+		_, err = s.repo.UpdateLoan(tx, loan)
+		return err
+	})
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	return s.GetLoanByID(loanIdStr)
+}
+
+// policyFor resolves the circulation policy for a borrower and a book
+func (s *LoanService) policyFor(db *gorm.DB, borrower *models.User, bookID uint) (models.CirculationPolicy, error) {
+	var itemType models.BookFormat
+	book, err := s.bookRepo.GetBookById(db, bookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.CirculationPolicy{}, err
+	}
+	if book != nil {
+		itemType = book.Format
+	}
+
+	policies, err := s.policyRepo.GetAllPolicies(db)
+	if err != nil {
+		return models.CirculationPolicy{}, err
+	}
+	return resolvePolicy(policies, borrower, itemType, s.defaultPolicy), nil
+}
+
+// policyID is the ID recorded on a loan, nil for the default rules
+func policyID(policy models.CirculationPolicy) *uint {
+	if policy.ID == 0 {
+		return nil
+	}
+	id := policy.ID
+	return &id
+}
+
+// parseOptionalID reads an optional numeric ID from a query parameter
+func parseOptionalID(name, value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a positive integer", name)
+	}
+	v := uint(id)
+	return &v, nil
+}
+
+func NewLoanService(
+	repo repositories.ILoanRepository,
+	copyRepo repositories.ICopyRepository,
+	userRepo repositories.IUserRepository,
+	bookRepo repositories.IBookRepository,
+	policyRepo repositories.ICirculationPolicyRepository,
+	holdRepo repositories.IHoldRepository,
+	db *gorm.DB,
+	defaultPolicy models.CirculationPolicy,
+	pickupWindow time.Duration,
+) ILoanService {
+	return &LoanService{
+		repo:          repo,
+		copyRepo:      copyRepo,
+		userRepo:      userRepo,
+		bookRepo:      bookRepo,
+		policyRepo:    policyRepo,
+		defaultPolicy: defaultPolicy,
+		holds:         newHoldQueue(holdRepo, copyRepo, pickupWindow),
+		db:            db,
+	}
+}
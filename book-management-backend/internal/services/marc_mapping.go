@@ -0,0 +1,284 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/marc"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+Mapping between MARC 21 bibliographic records and books, used by the MARC import and export:
+  001        control number (book ID on export)
+  008/07-10  publication year, 008/35-37 language (fallbacks on import)
+  020 $a     ISBN (the first valid one)
+  041 $a     language
+  100 $a     author, 110/700/710 $a when there is no 100 (the catalogue keeps one author per book)
+  245 $a $b  title : subtitle
+  260/264 $b $c  publisher, publication year
+  490 $a $v  series and position (export only)
+  520 $a     description
+  650/655 $a genres (subjects), 653 $a tags (export only)
+  856 $u     cover image when $3 mentions "cover"
+*/
+
+// marcFieldTargets is what the import does with each field, anything else ends up as ignored in the mapping report
+var marcFieldTargets = map[string]string{
+	"001":   "control number (error report)",
+	"008":   "publication_year, language (when missing elsewhere)",
+	"020$a": "isbn",
+	"041$a": "language",
+	"100$a": "author",
+	"110$a": "author (when no 100)",
+	"700$a": "author (when no 100/110)",
+	"710$a": "author (when no 100/110/700)",
+	"245$a": "title",
+	"245$b": "title (subtitle)",
+	"260$b": "publisher",
+	"260$c": "publication_year",
+	"264$b": "publisher",
+	"264$c": "publication_year",
+	"520$a": "description",
+	"650$a": "genres",
+	"655$a": "genres",
+	"856$3": "image (cover marker)",
+	"856$u": "image (when $3 mentions cover)",
+}
+
+// marcAuthorTags are tried in order to find the author of a record
+var marcAuthorTags = []string{"100", "110", "700", "710"}
+
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// mapMARCBook maps a bibliographic record to a book, only title and author are required
+func mapMARCBook(rec *marc.Record) (*importedBook, error) {
+	book := &importedBook{
+		Title:      marcTitle(rec),
+		AuthorName: marcAuthor(rec),
+	}
+	if book.Title == "" {
+		return nil, errors.New("title (245 $a) is missing")
+	}
+	if book.AuthorName == "" {
+		return nil, errors.New("author (100, 110, 700 or 710 $a) is missing")
+	}
+
+	for _, f := range rec.DataFields("020") {
+		for _, v := range f.SubfieldValues('a') {
+			// "9780261103573 (pbk.)" -> "9780261103573"
+			if fields := strings.Fields(v); len(fields) > 0 && book.ISBN == "" {
+				if isbn, err := normalizeOptionalISBN(fields[0]); err == nil {
+					book.ISBN = isbn
+				}
+			}
+		}
+	}
+
+	fixed := rec.ControlField("008")
+	for _, f := range append(rec.DataFields("264"), rec.DataFields("260")...) {
+		if f.Tag == "264" && f.Ind2 != '1' { // 264 _1 is the publication statement
+			continue
+		}
+		if book.Publisher == "" {
+			book.Publisher = marc.TrimPunctuation(f.Subfield('b'))
+		}
+		if book.PublicationYear == nil {
+			book.PublicationYear = parseYear(f.Subfield('c'))
+		}
+	}
+	if book.PublicationYear == nil && len(fixed) >= 11 {
+		book.PublicationYear = parseYear(fixed[7:11])
+	}
+
+	for _, f := range rec.DataFields("041") {
+		if book.Language == "" {
+			book.Language = marc.LanguageFromMARC(f.Subfield('a'))
+		}
+	}
+	if book.Language == "" && len(fixed) >= 38 {
+		book.Language = marc.LanguageFromMARC(fixed[35:38])
+	}
+
+	var summaries []string
+	for _, f := range rec.DataFields("520") {
+		if v := strings.TrimSpace(f.Subfield('a')); v != "" {
+			summaries = append(summaries, v)
+		}
+	}
+	book.Description = strings.Join(summaries, "\n\n")
+
+	for _, tag := range []string{"650", "655"} {
+		for _, f := range rec.DataFields(tag) {
+			if v := marc.TrimPunctuation(f.Subfield('a')); v != "" {
+				book.GenreNames = append(book.GenreNames, v)
+			}
+		}
+	}
+
+	for _, f := range rec.DataFields("856") {
+		u := strings.TrimSpace(f.Subfield('u'))
+		if book.ImageURL == "" && strings.Contains(strings.ToLower(f.Subfield('3')), "cover") && isHTTPURL(u) {
+			book.ImageURL = u
+		}
+	}
+
+	return book, nil
+}
+
+// marcTitle joins 245 $a and $b as "title: subtitle"
+func marcTitle(rec *marc.Record) string {
+	for _, f := range rec.DataFields("245") {
+		title := marc.TrimPunctuation(f.Subfield('a'))
+		if subtitle := marc.TrimPunctuation(f.Subfield('b')); subtitle != "" && title != "" {
+			title += ": " + subtitle
+		}
+		return title
+	}
+	return ""
+}
+
+// marcAuthor returns the first author name, "Surname, Forename" headings are turned into "Forename Surname"
+func marcAuthor(rec *marc.Record) string {
+	for _, tag := range marcAuthorTags {
+		for _, f := range rec.DataFields(tag) {
+			name := marc.TrimPunctuation(f.Subfield('a'))
+			if name == "" {
+				continue
+			}
+			if (tag == "100" || tag == "700") && f.Ind1 == '1' {
+				if surname, forename, ok := strings.Cut(name, ","); ok {
+					name = strings.TrimSpace(forename) + " " + strings.TrimSpace(surname)
+				}
+			}
+			return strings.Join(strings.Fields(name), " ")
+		}
+	}
+	return ""
+}
+
+func parseYear(s string) *int {
+	m := yearPattern.FindString(s)
+	if m == "" {
+		return nil
+	}
+	year, _ := strconv.Atoi(m)
+	return &year
+}
+
+// marcRowValues are the values of a record shown in the error report, see marcImportHeader
+func marcRowValues(rec *marc.Record) []string {
+	var isbn string
+	for _, f := range rec.DataFields("020") {
+		if isbn = f.Subfield('a'); isbn != "" {
+			break
+		}
+	}
+	return []string{rec.ControlField("001"), marcTitle(rec), isbn}
+}
+
+// marcMappingReport counts every field and subfield of the records and tells what each was imported as
+func marcMappingReport(records []*marc.Record) []models.ImportFieldUsage {
+	counts := map[string]int{}
+	for _, rec := range records {
+		for _, f := range rec.Fields {
+			if marc.IsControl(f.Tag) {
+				counts[f.Tag]++
+				continue
+			}
+			for _, sf := range f.Subfields {
+				counts[fmt.Sprintf("%s$%c", f.Tag, sf.Code)]++
+			}
+		}
+	}
+
+	report := make([]models.ImportFieldUsage, 0, len(counts))
+	for field, count := range counts {
+		report = append(report, models.ImportFieldUsage{
+			Field:  field,
+			Target: marcFieldTargets[field],
+			Count:  count,
+		})
+	}
+	slices.SortFunc(report, func(a, b models.ImportFieldUsage) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return report
+}
+
+// bookToMARC builds the bibliographic record of a book
+func bookToMARC(b *repositories.BookExportRow) *marc.Record {
+	rec := &marc.Record{Leader: marc.DefaultLeader}
+
+	rec.Add(marc.NewControlField("001", strconv.FormatUint(uint64(b.ID), 10)))
+	rec.Add(marc.NewControlField("005", b.UpdatedAt.UTC().Format("20060102150405")+".0"))
+
+	// 008: date entered, publication date, place (unknown), language, cataloging source
+	dateType, year := "n", "uuuu"
+	if b.PublicationYear != nil && *b.PublicationYear >= 0 && *b.PublicationYear <= 9999 {
+		dateType, year = "s", fmt.Sprintf("%04d", *b.PublicationYear)
+	}
+	rec.Add(marc.NewControlField("008",
+		b.CreatedAt.UTC().Format("060102")+dateType+year+"    "+"xx "+strings.Repeat("|", 17)+marc.LanguageToMARC(b.Language)+" d"))
+
+	rec.Add(marc.NewDataField("020", ' ', ' ', "a", b.ISBN))
+	if b.Language != "" {
+		rec.Add(marc.NewDataField("041", '0', ' ', "a", marc.LanguageToMARC(b.Language)))
+	}
+
+	hasAuthor := b.AuthorName != ""
+	rec.Add(marc.NewDataField("100", authorIndicator(b.AuthorName), ' ', "a", invertName(b.AuthorName)))
+
+	titleInd1 := byte('0')
+	if hasAuthor {
+		titleInd1 = '1' // added entry, a 1XX is present
+	}
+	rec.Add(marc.NewDataField("245", titleInd1, '0', "a", b.Title))
+
+	var yearStr string
+	if dateType == "s" {
+		yearStr = year
+	}
+	rec.Add(marc.NewDataField("264", ' ', '1', "b", b.Publisher, "c", yearStr))
+
+	if b.SeriesName != "" {
+		var position string
+		if b.SeriesPosition != nil {
+			position = strconv.FormatFloat(*b.SeriesPosition, 'f', -1, 64)
+		}
+		rec.Add(marc.NewDataField("490", '0', ' ', "a", b.SeriesName, "v", position))
+	}
+
+	rec.Add(marc.NewDataField("520", ' ', ' ', "a", b.Description))
+	for _, genre := range b.GenreNames {
+		rec.Add(marc.NewDataField("650", ' ', '4', "a", genre)) // ind2 4: source not specified
+	}
+	for _, tag := range b.TagNames {
+		rec.Add(marc.NewDataField("653", ' ', ' ', "a", tag))
+	}
+	if b.Image != "" {
+		rec.Add(marc.NewDataField("856", '4', '2', "3", "Cover image", "u", b.Image))
+	}
+	return rec
+}
+
+// invertName turns "Forename Surname" into the "Surname, Forename" form of MARC headings
+func invertName(name string) string {
+	i := strings.LastIndex(name, " ")
+	if i < 0 || strings.Contains(name, ",") {
+		return name
+	}
+	return name[i+1:] + ", " + name[:i]
+}
+
+// authorIndicator is 1 (surname first) for inverted names, 0 (forename only) otherwise
+func authorIndicator(name string) byte {
+	if strings.Contains(invertName(name), ",") {
+		return '1'
+	}
+	return '0'
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"book-management/pkg/signedurl"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// opdsBookIncludes are the relations shown in acquisition feed entries
+var opdsBookIncludes = []string{"author", "genres", "series"}
+
+// OPDSBookPage is one page of an acquisition feed, Total is always counted for OpenSearch
+type OPDSBookPage struct {
+	Books        []models.Book
+	Acquisitions map[uint][]OPDSAcquisition // by book ID, books without files are left out
+	Total        int64
+	pagination.PageInfo
+}
+
+// OPDSAcquisition is a file of a book offered by the catalog, with a signed download link
+type OPDSAcquisition struct {
+	File models.BookFile
+	Link BookFileLink
+}
+
+type IOPDSService interface {
+	GetNewBooks(params pagination.Params) (*OPDSBookPage, int, error)
+	SearchBooks(q string, params pagination.Params) (*OPDSBookPage, int, error)
+	GetGenreBooks(genreIdStr string, params pagination.Params) (*models.Genre, *OPDSBookPage, int, error)
+	GetAuthorBooks(authorIdStr string, params pagination.Params) (*models.Author, *OPDSBookPage, int, error)
+	GetGenres(params pagination.Params) (*GenreListResult, int64, int, error)
+	GetAuthors(params pagination.Params) (*AuthorListResult, int64, int, error)
+}
+
+type OPDSService struct {
+	bookRepo   repositories.IBookRepository
+	authorRepo repositories.IAuthorRepository
+	genreRepo  repositories.IGenreRepository
+	fileRepo   repositories.IBookFileRepository
+	signer     *signedurl.Signer
+	linkTTL    time.Duration
+	db         *gorm.DB
+}
+
+func NewOPDSService(bookRepo repositories.IBookRepository, authorRepo repositories.IAuthorRepository, genreRepo repositories.IGenreRepository,
+	fileRepo repositories.IBookFileRepository, signer *signedurl.Signer, linkTTL time.Duration, db *gorm.DB) IOPDSService {
+	return &OPDSService{bookRepo: bookRepo, authorRepo: authorRepo, genreRepo: genreRepo, fileRepo: fileRepo, signer: signer, linkTTL: linkTTL, db: db}
+}
+
+// checkOPDSParams rejects cursors, catalog feeds are paged by offset so that OpenSearch startIndex works
+func checkOPDSParams(params pagination.Params) error {
+	if params.CursorMode() {
+		return errors.New("cursor pagination is not supported by the catalog, use offset")
+	}
+	return nil
+}
+
+// bookPage loads one page of books plus the total number of matches
+func (s *OPDSService) bookPage(filter repositories.BookFilter, params pagination.Params) (*OPDSBookPage, int, error) {
+	if err := checkOPDSParams(params); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	books, err := s.bookRepo.GetAllBooks(s.db, uint(params.Limit+1), uint(params.Offset), filter, opdsBookIncludes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	total, err := s.bookRepo.CountBooks(s.db, filter)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	page := &OPDSBookPage{Books: *books, Total: total}
+	if len(page.Books) > params.Limit {
+		page.Books = page.Books[:params.Limit]
+		page.HasMore = true
+	}
+	page.PageInfo.Total = &page.Total
+
+	if page.Acquisitions, err = s.acquisitions(page.Books); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return page, http.StatusOK, nil
+}
+
+// acquisitions signs a download link for every file of the books, the catalog takes no token
+// so the links are what lets e-readers fetch the files
+func (s *OPDSService) acquisitions(books []models.Book) (map[uint][]OPDSAcquisition, error) {
+	ids := make([]uint, len(books))
+	for i, b := range books {
+		ids[i] = b.ID
+	}
+	files, err := s.fileRepo.GetFilesByBookIDs(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint][]OPDSAcquisition)
+	for _, f := range files {
+		result[f.BookID] = append(result[f.BookID], OPDSAcquisition{File: f, Link: signDownloadLink(s.signer, f.ID, s.linkTTL)})
+	}
+	return result, nil
+}
+
+func (s *OPDSService) GetNewBooks(params pagination.Params) (*OPDSBookPage, int, error) {
+	filter := repositories.BookFilter{
+		Sort: []repositories.SortField{{Key: "created_at", Desc: true}, {Key: "id", Desc: true}},
+	}
+	return s.bookPage(filter, params)
+}
+
+func (s *OPDSService) SearchBooks(q string, params pagination.Params) (*OPDSBookPage, int, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, http.StatusBadRequest, errors.New("search query is required")
+	}
+	filter := repositories.BookFilter{
+		Title: q,
+		Sort:  []repositories.SortField{{Key: "title"}, {Key: "id"}},
+	}
+	return s.bookPage(filter, params)
+}
+
+func (s *OPDSService) GetGenreBooks(genreIdStr string, params pagination.Params) (*models.Genre, *OPDSBookPage, int, error) {
+	id, err := strconv.ParseUint(genreIdStr, 10, 32)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+	genre, err := s.genreRepo.FindGenreByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, http.StatusNotFound, fmt.Errorf("genre with ID [%d] does not exist", id)
+		}
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	filter := repositories.BookFilter{
+		GenreIDs: []uint{genre.ID},
+		Sort:     []repositories.SortField{{Key: "title"}, {Key: "id"}},
+	}
+	page, httpStatus, err := s.bookPage(filter, params)
+	if err != nil {
+		return nil, nil, httpStatus, err
+	}
+	return genre, page, httpStatus, nil
+}
+
+func (s *OPDSService) GetAuthorBooks(authorIdStr string, params pagination.Params) (*models.Author, *OPDSBookPage, int, error) {
+	id, err := strconv.ParseUint(authorIdStr, 10, 32)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+	author, err := s.authorRepo.FindAuthorByID(s.db, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, http.StatusNotFound, fmt.Errorf("author with ID [%d] does not exist", id)
+		}
+		return nil, nil, http.StatusInternalServerError, err
+	}
+
+	authorID := author.ID
+	filter := repositories.BookFilter{
+		AuthorID: &authorID,
+		Sort:     []repositories.SortField{{Key: "publication_year"}, {Key: "title"}, {Key: "id"}},
+	}
+	page, httpStatus, err := s.bookPage(filter, params)
+	if err != nil {
+		return nil, nil, httpStatus, err
+	}
+	return author, page, httpStatus, nil
+}
+
+// GetGenres lists genres by name for the navigation feed, with the total number of genres
+func (s *OPDSService) GetGenres(params pagination.Params) (*GenreListResult, int64, int, error) {
+	if err := checkOPDSParams(params); err != nil {
+		return nil, 0, http.StatusBadRequest, err
+	}
+
+	genres, err := s.genreRepo.GetAllGenres(s.db, uint(params.Limit+1), uint(params.Offset), 0, nil)
+	if err != nil {
+		return nil, 0, http.StatusInternalServerError, err
+	}
+	total, err := s.genreRepo.CountGenres(s.db)
+	if err != nil {
+		return nil, 0, http.StatusInternalServerError, err
+	}
+
+	result := &GenreListResult{Genres: *genres}
+	if len(result.Genres) > params.Limit {
+		result.Genres = result.Genres[:params.Limit]
+		result.HasMore = true
+	}
+	return result, total, http.StatusOK, nil
+}
+
+// GetAuthors lists authors for the navigation feed, with the total number of authors
+func (s *OPDSService) GetAuthors(params pagination.Params) (*AuthorListResult, int64, int, error) {
+	if err := checkOPDSParams(params); err != nil {
+		return nil, 0, http.StatusBadRequest, err
+	}
+
+	authors, err := s.authorRepo.GetAuthors(s.db, params.Limit+1, params.Offset, 0, nil)
+	if err != nil {
+		return nil, 0, http.StatusInternalServerError, err
+	}
+	total, err := s.authorRepo.CountAuthors(s.db)
+	if err != nil {
+		return nil, 0, http.StatusInternalServerError, err
+	}
+
+	result := &AuthorListResult{Authors: authors}
+	if len(result.Authors) > params.Limit {
+		result.Authors = result.Authors[:params.Limit]
+		result.HasMore = true
+	}
+	return result, total, http.StatusOK, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/sitemap"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type IPublicService interface {
+	// GetBook loads a book with everything its public page shows
+	GetBook(bookIdStr string) (*models.Book, int, error)
+	// CountSitemaps is the number of book sitemaps needed to list every book
+	CountSitemaps() (int, int, error)
+	// GetSitemapEntries returns the books of the 1-based sitemap page
+	GetSitemapEntries(pageStr string) ([]repositories.BookSitemapEntry, int, error)
+}
+
+type PublicService struct {
+	bookRepo repositories.IBookRepository
+	db       *gorm.DB
+}
+
+func NewPublicService(bookRepo repositories.IBookRepository, db *gorm.DB) IPublicService {
+	return &PublicService{bookRepo: bookRepo, db: db}
+}
+
+func (s *PublicService) GetBook(bookIdStr string) (*models.Book, int, error) {
+	id, err := strconv.ParseUint(bookIdStr, 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	filter := repositories.BookFilter{IDs: []uint{uint(id)}}
+	books, err := s.bookRepo.GetAllBooks(s.db, 1, 0, filter, []string{"author", "genres", "series", "tags"})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if len(*books) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", id)
+	}
+	return &(*books)[0], http.StatusOK, nil
+}
+
+func (s *PublicService) CountSitemaps() (int, int, error) {
+	total, err := s.bookRepo.CountBooks(s.db, repositories.BookFilter{})
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	return max(int((total+sitemap.MaxURLs-1)/sitemap.MaxURLs), 1), http.StatusOK, nil
+}
+
+func (s *PublicService) GetSitemapEntries(pageStr string) ([]repositories.BookSitemapEntry, int, error) {
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		return nil, http.StatusNotFound, errors.New("sitemap does not exist")
+	}
+
+	entries, err := s.bookRepo.GetSitemapEntries(s.db, sitemap.MaxURLs, (page-1)*sitemap.MaxURLs)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if len(entries) == 0 && page > 1 {
+		return nil, http.StatusNotFound, errors.New("sitemap does not exist")
+	}
+	return entries, http.StatusOK, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Entity types that can be searched
+const (
+	SearchTypeBooks   = "books"
+	SearchTypeAuthors = "authors"
+	SearchTypeGenres  = "genres"
+)
+
+// SearchResult groups full-text search hits by entity type, a nil group was not requested
+type SearchResult struct {
+	Books   []repositories.BookSearchHit
+	Authors []repositories.NamedSearchHit
+	Genres  []repositories.NamedSearchHit
+}
+
+// minSuggestLength is the shortest input worth a trigram lookup
+const minSuggestLength = 2
+
+type ISearchService interface {
+	// Search runs q against the requested entity types (comma separated, empty means all)
+	Search(q, typesStr, limitStr string) (*SearchResult, int, error)
+
+	// Type-ahead suggestions, inputs shorter than minSuggestLength return no suggestions
+	SuggestBooks(q, limitStr string) ([]repositories.BookSuggestion, int, error)
+	SuggestAuthors(q, limitStr string) ([]repositories.AuthorSuggestion, int, error)
+}
+
+type SearchService struct {
+	repo repositories.ISearchRepository
+	db   *gorm.DB
+}
+
+func (s *SearchService) Search(q, typesStr, limitStr string) (*SearchResult, int, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, http.StatusBadRequest, errors.New("query parameter q is required")
+	}
+
+	limit, err := pagination.ParseLimit(limitStr, 10, 50)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	types, err := parseSearchTypes(typesStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	result := &SearchResult{}
+	if types[SearchTypeBooks] {
+		if result.Books, err = s.repo.SearchBooks(s.db, q, limit); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		for i := range result.Books {
+			result.Books[i].Snippet = renderHighlight(result.Books[i].Snippet)
+		}
+	}
+	if types[SearchTypeAuthors] {
+		if result.Authors, err = s.repo.SearchAuthors(s.db, q, limit); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		renderNamedHighlights(result.Authors)
+	}
+	if types[SearchTypeGenres] {
+		if result.Genres, err = s.repo.SearchGenres(s.db, q, limit); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		renderNamedHighlights(result.Genres)
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (s *SearchService) SuggestBooks(q, limitStr string) ([]repositories.BookSuggestion, int, error) {
+	q, limit, err := parseSuggestParams(q, limitStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len([]rune(q)) < minSuggestLength {
+		return []repositories.BookSuggestion{}, http.StatusOK, nil
+	}
+
+	suggestions, err := s.repo.SuggestBooks(s.db, q, limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return suggestions, http.StatusOK, nil
+}
+
+func (s *SearchService) SuggestAuthors(q, limitStr string) ([]repositories.AuthorSuggestion, int, error) {
+	q, limit, err := parseSuggestParams(q, limitStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len([]rune(q)) < minSuggestLength {
+		return []repositories.AuthorSuggestion{}, http.StatusOK, nil
+	}
+
+	suggestions, err := s.repo.SuggestAuthors(s.db, q, limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return suggestions, http.StatusOK, nil
+}
+
+// parseSuggestParams trims the input and validates limit, 1-20 (default 5)
+func parseSuggestParams(q, limitStr string) (string, int, error) {
+	limit, err := pagination.ParseLimit(limitStr, 5, 20)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSpace(q), limit, nil
+}
+
+// parseSearchTypes turns "books,authors" into a set, empty means every type
+func parseSearchTypes(typesStr string) (map[string]bool, error) {
+	all := map[string]bool{SearchTypeBooks: true, SearchTypeAuthors: true, SearchTypeGenres: true}
+	if strings.TrimSpace(typesStr) == "" {
+		return all, nil
+	}
+
+	types := make(map[string]bool)
+	for _, t := range strings.Split(typesStr, ",") {
+		t = strings.TrimSpace(t)
+		if !all[t] {
+			return nil, fmt.Errorf("invalid type [%s], allowed: books, authors, genres", t)
+		}
+		types[t] = true
+	}
+	return types, nil
+}
+
+// renderHighlight escapes the snippet text then turns the highlight markers into <mark> tags
+func renderHighlight(snippet string) string {
+	return strings.NewReplacer(
+		repositories.HighlightStart, "<mark>",
+		repositories.HighlightStop, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
+
+func renderNamedHighlights(hits []repositories.NamedSearchHit) {
+	for i := range hits {
+		hits[i].Snippet = renderHighlight(hits[i].Snippet)
+	}
+}
+
+func NewSearchService(repo repositories.ISearchRepository, db *gorm.DB) ISearchService {
+	return &SearchService{repo: repo, db: db}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type SeriesCreateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type SeriesUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// SeriesListResult is one page of series ordered by id
+type SeriesListResult struct {
+	Series []models.Series
+	pagination.PageInfo
+}
+
+type ISeriesService interface {
+	GetSeriesByID(seriesIdStr string) (*models.Series, int, error)
+	GetAllSeries(params pagination.Params) (*SeriesListResult, int, error)
+	CreateSeries(req SeriesCreateRequest) (*models.Series, error)
+	UpdateSeries(seriesIdStr string, req SeriesUpdateRequest) (*models.Series, int, error)
+	DeleteSeries(seriesIdStr string) (int, error)
+	GetBooksInSeries(seriesIdStr string) (*[]models.Book, int, error)
+}
+
+type SeriesService struct {
+	repo repositories.ISeriesRepository
+	db   *gorm.DB
+}
+
+func (s *SeriesService) GetSeriesByID(seriesIdStr string) (*models.Series, int, error) {
+	id, err := strconv.Atoi(seriesIdStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	series, err := s.repo.GetSeriesByID(s.db, uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, http.StatusNotFound, fmt.Errorf("series with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return series, http.StatusOK, nil
+}
+
+func (s *SeriesService) GetAllSeries(params pagination.Params) (*SeriesListResult, int, error) {
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// One extra row tells whether there is a next page
+	series, err := s.repo.GetAllSeries(s.db, uint(params.Limit+1), uint(params.Offset), afterID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	result := &SeriesListResult{Series: *series}
+	if len(result.Series) > params.Limit {
+		result.Series = result.Series[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Series[params.Limit-1].ID)
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountSeries(s.db)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (s *SeriesService) CreateSeries(req SeriesCreateRequest) (*models.Series, error) {
+	series := &models.Series{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	return s.repo.CreateSeries(s.db, series)
+}
+
+func (s *SeriesService) UpdateSeries(seriesIdStr string, req SeriesUpdateRequest) (*models.Series, int, error) {
+	series, httpStatus, err := s.GetSeriesByID(seriesIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if req.Name != nil {
+		series.Name = *req.Name
+	}
+	if req.Description != nil {
+		series.Description = *req.Description
+	}
+
+	updated, err := s.repo.UpdateSeries(s.db, series)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return updated, http.StatusOK, nil
+}
+
+// DeleteSeries removes a series and detaches its books (the books themselves are kept)
+func (s *SeriesService) DeleteSeries(seriesIdStr string) (int, error) {
+	series, httpStatus, err := s.GetSeriesByID(seriesIdStr)
+	if err != nil {
+		return httpStatus, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.DetachBooksFromSeries(tx, series.ID); err != nil {
+			return err
+		}
+		return s.repo.DeleteSeries(tx, series)
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// GetBooksInSeries returns the books of a series in reading order
+func (s *SeriesService) GetBooksInSeries(seriesIdStr string) (*[]models.Book, int, error) {
+	series, httpStatus, err := s.GetSeriesByID(seriesIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	books, err := s.repo.GetBooksInSeries(s.db, series.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return books, http.StatusOK, nil
+}
+
+func NewSeriesService(repo repositories.ISeriesRepository, db *gorm.DB) ISeriesService {
+	return &SeriesService{repo: repo, db: db}
+}
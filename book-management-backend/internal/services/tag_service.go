@@ -0,0 +1,148 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"book-management/pkg/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TagBookRequest is used for tagging/untagging a book
+type TagBookRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1,dive,required,max=50"`
+}
+
+type ITagService interface {
+	AddTagsToBook(bookIdStr string, req TagBookRequest) (*models.Book, int, error)
+	RemoveTagsFromBook(bookIdStr string, req TagBookRequest) (*models.Book, int, error)
+	Autocomplete(q, limitStr string) ([]repositories.TagSuggestion, int, error)
+}
+
+type TagService struct {
+	repo     repositories.ITagRepository
+	bookRepo repositories.IBookRepository
+	db       *gorm.DB
+}
+
+// ParseTagNames splits a comma separated list into unique normalized tag names
+func ParseTagNames(tagsStr string) []string {
+	if strings.TrimSpace(tagsStr) == "" {
+		return nil
+	}
+	return uniqueNormalizedTags(strings.Split(tagsStr, ","))
+}
+
+func uniqueNormalizedTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized := utils.NormalizeName(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	return result
+}
+
+func (s *TagService) getBook(bookIdStr string) (*models.Book, int, error) {
+	bookId, err := strconv.Atoi(bookIdStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	book, err := s.bookRepo.GetBookById(s.db, uint(bookId))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", bookId)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return book, http.StatusOK, nil
+}
+
+// AddTagsToBook creates missing tags and attaches them to the book
+func (s *TagService) AddTagsToBook(bookIdStr string, req TagBookRequest) (*models.Book, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	// Keep the first spelling a user typed as display name
+	tags := make([]models.Tag, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, name := range req.Tags {
+		normalized := utils.NormalizeName(name)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		tags = append(tags, models.Tag{
+			Name:           strings.Join(strings.Fields(name), " "),
+			NormalizedName: normalized,
+		})
+	}
+	if len(tags) == 0 {
+		return nil, http.StatusBadRequest, errors.New("tags must not be empty")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		saved, err := s.repo.FindOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+		return s.repo.AddTagsToBook(tx, book, saved)
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return s.getBook(bookIdStr)
+}
+
+// RemoveTagsFromBook detaches tags from the book, unknown tags are ignored
+func (s *TagService) RemoveTagsFromBook(bookIdStr string, req TagBookRequest) (*models.Book, int, error) {
+	book, httpStatus, err := s.getBook(bookIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	tags, err := s.repo.GetTagsByNormalizedNames(s.db, uniqueNormalizedTags(req.Tags))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if len(tags) > 0 {
+		if err := s.repo.RemoveTagsFromBook(s.db, book, tags); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	return s.getBook(bookIdStr)
+}
+
+// Autocomplete suggests tags starting with q, most used first
+func (s *TagService) Autocomplete(q, limitStr string) ([]repositories.TagSuggestion, int, error) {
+	limit, err := pagination.ParseLimit(limitStr, 10, 50)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	suggestions, err := s.repo.Autocomplete(s.db, utils.NormalizeName(q), limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return suggestions, http.StatusOK, nil
+}
+
+func NewTagService(repo repositories.ITagRepository, bookRepo repositories.IBookRepository, db *gorm.DB) ITagService {
+	return &TagService{repo: repo, bookRepo: bookRepo, db: db}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"book-management/internal/models"
+	"book-management/internal/repositories"
+	"book-management/pkg/pagination"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type WorkCreateRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	GenreIDs    []uint `json:"genre_ids"`
+}
+
+// Use pointer to check nil or empty for PATCH update api
+type WorkUpdateRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	GenreIDs    *[]uint `json:"genre_ids"` // replaces all work-level genres when present
+}
+
+// WorkListResult is one page of works ordered by id
+type WorkListResult struct {
+	Works []models.Work
+	pagination.PageInfo
+}
+
+type IWorkService interface {
+	GetWorkByID(workIdStr string) (*models.Work, int, error)
+	GetAllWorks(params pagination.Params) (*WorkListResult, int, error)
+	CreateWork(req WorkCreateRequest) (*models.Work, int, error)
+	UpdateWork(workIdStr string, req WorkUpdateRequest) (*models.Work, int, error)
+	DeleteWork(workIdStr string) (int, error)
+
+	// Edition management
+	AttachEditions(workIdStr string, req ManageBooksRequest) (*models.Work, int, error)
+	DetachEditions(workIdStr string, req ManageBooksRequest) (*models.Work, int, error)
+}
+
+type WorkService struct {
+	repo      repositories.IWorkRepository
+	bookRepo  repositories.IBookRepository
+	genreRepo repositories.IGenreRepository
+	db        *gorm.DB
+}
+
+func (s *WorkService) GetWorkByID(workIdStr string) (*models.Work, int, error) {
+	id, err := strconv.Atoi(workIdStr)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	work, err := s.repo.GetWorkByID(s.db, uint(id))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, http.StatusNotFound, fmt.Errorf("work with ID [%d] does not exist", id)
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	return work, http.StatusOK, nil
+}
+
+func (s *WorkService) GetAllWorks(params pagination.Params) (*WorkListResult, int, error) {
+	afterID, err := params.AfterID()
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// One extra row tells whether there is a next page
+	works, err := s.repo.GetAllWorks(s.db, uint(params.Limit+1), uint(params.Offset), afterID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	result := &WorkListResult{Works: *works}
+	if len(result.Works) > params.Limit {
+		result.Works = result.Works[:params.Limit]
+		result.HasMore = true
+		result.NextCursor = pagination.IDCursor(result.Works[params.Limit-1].ID)
+	}
+
+	if params.IncludeTotal {
+		total, err := s.repo.CountWorks(s.db)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		result.Total = &total
+	}
+
+	return result, http.StatusOK, nil
+}
+
+func (s *WorkService) CreateWork(req WorkCreateRequest) (*models.Work, int, error) {
+	genres, httpStatus, err := s.findGenres(req.GenreIDs)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	work := &models.Work{
+		Title:       req.Title,
+		Description: req.Description,
+		Genres:      genres,
+	}
+
+	if _, err := s.repo.CreateWork(s.db, work); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return s.GetWorkByID(strconv.Itoa(int(work.ID)))
+}
+
+func (s *WorkService) UpdateWork(workIdStr string, req WorkUpdateRequest) (*models.Work, int, error) {
+	work, httpStatus, err := s.GetWorkByID(workIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if req.Title != nil {
+		work.Title = *req.Title
+	}
+	if req.Description != nil {
+		work.Description = *req.Description
+	}
+
+	var genres []models.Genre
+	if req.GenreIDs != nil {
+		genres, httpStatus, err = s.findGenres(*req.GenreIDs)
+		if err != nil {
+			return nil, httpStatus, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.repo.UpdateWork(tx, work); err != nil {
+			return err
+		}
+		if req.GenreIDs != nil {
+			return s.repo.ReplaceGenres(tx, work, genres)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return s.GetWorkByID(workIdStr)
+}
+
+// DeleteWork removes a work and detaches its editions (the books themselves are kept)
+func (s *WorkService) DeleteWork(workIdStr string) (int, error) {
+	work, httpStatus, err := s.GetWorkByID(workIdStr)
+	if err != nil {
+		return httpStatus, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.DetachAllEditions(tx, work.ID); err != nil {
+			return err
+		}
+		return s.repo.DeleteWork(tx, work)
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusNoContent, nil
+}
+
+// =============================
+// Edition Management
+// =============================
+
+// AttachEditions links books to a work, a book already linked to another work is moved
+func (s *WorkService) AttachEditions(workIdStr string, req ManageBooksRequest) (*models.Work, int, error) {
+	work, httpStatus, err := s.GetWorkByID(workIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if httpStatus, err := s.checkBooksExist(req.BookIDs); err != nil {
+		return nil, httpStatus, err
+	}
+
+	if err := s.repo.AttachEditions(s.db, work.ID, req.BookIDs); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return s.GetWorkByID(workIdStr)
+}
+
+// DetachEditions unlinks books from a work, books of other works are ignored
+func (s *WorkService) DetachEditions(workIdStr string, req ManageBooksRequest) (*models.Work, int, error) {
+	work, httpStatus, err := s.GetWorkByID(workIdStr)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	if err := s.repo.DetachEditions(s.db, work.ID, req.BookIDs); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return s.GetWorkByID(workIdStr)
+}
+
+// checkBooksExist returns 404 listing the first book ID that does not exist
+func (s *WorkService) checkBooksExist(bookIDs []uint) (int, error) {
+	books, err := s.bookRepo.GetBooksByIds(s.db, bookIDs)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	found := make(map[uint]bool, len(books))
+	for _, b := range books {
+		found[b.ID] = true
+	}
+	for _, id := range bookIDs {
+		if !found[id] {
+			return http.StatusNotFound, fmt.Errorf("book with ID [%d] does not exist", id)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// findGenres loads genres by IDs, returning 404 if any of them does not exist
+func (s *WorkService) findGenres(genreIDs []uint) ([]models.Genre, int, error) {
+	if len(genreIDs) == 0 {
+		return []models.Genre{}, http.StatusOK, nil
+	}
+
+	genres, err := s.genreRepo.GetGenresByIds(s.db, genreIDs)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	found := make(map[uint]bool, len(genres))
+	for _, g := range genres {
+		found[g.ID] = true
+	}
+	for _, id := range genreIDs {
+		if !found[id] {
+			return nil, http.StatusNotFound, fmt.Errorf("genre with ID [%d] does not exist", id)
+		}
+	}
+	return genres, http.StatusOK, nil
+}
+
+func NewWorkService(
+	repo repositories.IWorkRepository,
+	bookRepo repositories.IBookRepository,
+	genreRepo repositories.IGenreRepository,
+	db *gorm.DB,
+) IWorkService {
+	return &WorkService{
+		repo:      repo,
+		bookRepo:  bookRepo,
+		genreRepo: genreRepo,
+		db:        db,
+	}
+}
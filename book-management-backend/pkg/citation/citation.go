@@ -0,0 +1,211 @@
+package citation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Citations of books in machine-readable formats (BibTeX, RIS, CSL-JSON) and as rendered
+reference-list strings (APA 7, MLA 9, Chicago 17 bibliography).
+Rendered strings are plain text: titles are not italicised and are kept in the catalogue's casing.
+*/
+
+type Format string
+
+const (
+	FormatBibTeX  Format = "bibtex"
+	FormatRIS     Format = "ris"
+	FormatCSLJSON Format = "csl-json"
+	FormatAPA     Format = "apa"
+	FormatMLA     Format = "mla"
+	FormatChicago Format = "chicago"
+)
+
+// ParseFormat validates a format name, an empty name is returned as is
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", FormatBibTeX, FormatRIS, FormatCSLJSON, FormatAPA, FormatMLA, FormatChicago:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format [%s], allowed: bibtex, ris, csl-json, apa, mla, chicago", s)
+}
+
+// ContentType is the MIME type of a document in this format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatBibTeX:
+		return "application/x-bibtex; charset=utf-8"
+	case FormatRIS:
+		return "application/x-research-info-systems; charset=utf-8"
+	case FormatCSLJSON:
+		return "application/vnd.citationstyles.csl+json"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Work is the bibliographic data of one book
+type Work struct {
+	ID           uint
+	Title        string
+	Authors      []Name
+	Publisher    string
+	Year         *int
+	ISBN         string
+	Language     string
+	Series       string
+	SeriesNumber *float64
+}
+
+// Name is a person split into family and given names, Literal is used for single-part names
+type Name struct {
+	Family  string
+	Given   string
+	Literal string
+}
+
+// ParseName splits "J. R. R. Tolkien" or "Tolkien, J. R. R." into family and given names
+func ParseName(name string) Name {
+	name = strings.Join(strings.Fields(name), " ")
+	if family, given, ok := strings.Cut(name, ","); ok {
+		return Name{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)}
+	}
+	if i := strings.LastIndex(name, " "); i > 0 {
+		return Name{Family: name[i+1:], Given: name[:i]}
+	}
+	return Name{Literal: name}
+}
+
+// Inverted is "Family, Given", the form used by reference lists and BibTeX/RIS
+func (n Name) Inverted() string {
+	if n.Literal != "" {
+		return n.Literal
+	}
+	return n.Family + ", " + n.Given
+}
+
+// Direct is "Given Family"
+func (n Name) Direct() string {
+	if n.Literal != "" {
+		return n.Literal
+	}
+	return n.Given + " " + n.Family
+}
+
+// Initials turns the given names into initials: "John Ronald Reuel" -> "J. R. R."
+func (n Name) Initials() string {
+	var initials []string
+	for _, part := range strings.Fields(strings.ReplaceAll(n.Given, ".", ". ")) {
+		for i, sub := range strings.Split(part, "-") {
+			r := []rune(strings.TrimSuffix(sub, "."))
+			if len(r) == 0 {
+				continue
+			}
+			initial := string(unicode.ToUpper(r[0])) + "."
+			if i > 0 {
+				initials[len(initials)-1] += "-" + initial
+				continue
+			}
+			initials = append(initials, initial)
+		}
+	}
+	return strings.Join(initials, " ")
+}
+
+// Render writes the works in a machine-readable format or as one rendered string per line
+func Render(format Format, works []Work) ([]byte, error) {
+	switch format {
+	case FormatBibTeX:
+		var b strings.Builder
+		used := map[string]int{}
+		for i, w := range works {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			// Keys must be unique within a file: tolkien1954fellowship, tolkien1954fellowship-2, ...
+			key := Key(w)
+			if used[key]++; used[key] > 1 {
+				key += "-" + strconv.Itoa(used[key])
+			}
+			b.WriteString(bibtexEntry(w, key))
+		}
+		return []byte(b.String()), nil
+	case FormatRIS:
+		var b strings.Builder
+		for _, w := range works {
+			b.WriteString(RIS(w))
+		}
+		return []byte(b.String()), nil
+	case FormatCSLJSON:
+		items := make([]CSLItem, len(works))
+		for i, w := range works {
+			items[i] = CSL(w)
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(items); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	style := map[Format]func(Work) string{FormatAPA: APA, FormatMLA: MLA, FormatChicago: Chicago}[format]
+	if style == nil {
+		return nil, fmt.Errorf("unsupported format [%s]", format)
+	}
+	var b strings.Builder
+	for _, w := range works {
+		b.WriteString(style(w))
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// Key is a BibTeX citation key such as "tolkien1954fellowship"
+func Key(w Work) string {
+	var b strings.Builder
+	if len(w.Authors) > 0 {
+		n := w.Authors[0]
+		b.WriteString(keyPart(n.Family + n.Literal))
+	}
+	if w.Year != nil {
+		b.WriteString(strconv.Itoa(*w.Year))
+	}
+	for _, word := range strings.Fields(w.Title) {
+		word = keyPart(word)
+		if word != "" && !stopWords[word] {
+			b.WriteString(word)
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "book" + strconv.FormatUint(uint64(w.ID), 10)
+	}
+	return b.String()
+}
+
+var stopWords = map[string]bool{"a": true, "an": true, "the": true, "of": true, "on": true, "in": true}
+
+// keyPart keeps only ASCII letters and digits, lowercased
+func keyPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func seriesNumber(n *float64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*n, 'f', -1, 64)
+}
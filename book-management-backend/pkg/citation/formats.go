@@ -0,0 +1,232 @@
+package citation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ===== BibTeX =====
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `&`, `\&`, `%`, `\%`, `$`, `\$`,
+	`#`, `\#`, `_`, `\_`, `~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+)
+
+// BibTeX renders one @book entry
+func BibTeX(w Work) string {
+	return bibtexEntry(w, Key(w))
+}
+
+func bibtexEntry(w Work, key string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@book{%s,\n", key)
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "  %-9s = {%s},\n", name, bibtexEscaper.Replace(value))
+		}
+	}
+
+	authors := make([]string, len(w.Authors))
+	for i, n := range w.Authors {
+		authors[i] = bibtexEscaper.Replace(n.Inverted())
+		if n.Literal != "" {
+			authors[i] = "{" + authors[i] + "}" // keep single-part names from being split
+		}
+	}
+	if len(authors) > 0 {
+		fmt.Fprintf(&b, "  %-9s = {%s},\n", "author", strings.Join(authors, " and "))
+	}
+	field("title", w.Title)
+	field("publisher", w.Publisher)
+	if w.Year != nil {
+		field("year", strconv.Itoa(*w.Year))
+	}
+	field("series", w.Series)
+	field("number", seriesNumber(w.SeriesNumber))
+	field("isbn", w.ISBN)
+	field("language", w.Language)
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ===== RIS =====
+
+// RIS renders one BOOK reference, lines end with CRLF as the format requires
+func RIS(w Work) string {
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s  - %s\r\n", name, strings.Join(strings.Fields(value), " "))
+		}
+	}
+
+	tag("TY", "BOOK")
+	tag("ID", strconv.FormatUint(uint64(w.ID), 10))
+	for _, n := range w.Authors {
+		tag("AU", n.Inverted())
+	}
+	tag("TI", w.Title)
+	tag("T3", w.Series)
+	if w.Year != nil {
+		tag("PY", strconv.Itoa(*w.Year))
+	}
+	tag("PB", w.Publisher)
+	tag("SN", w.ISBN)
+	tag("LA", w.Language)
+	b.WriteString("ER  - \r\n\r\n")
+	return b.String()
+}
+
+// ===== CSL-JSON =====
+
+// CSLItem is a Citation Style Language item, see https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+type CSLItem struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	Title            string    `json:"title"`
+	Author           []CSLName `json:"author,omitempty"`
+	Issued           *CSLDate  `json:"issued,omitempty"`
+	Publisher        string    `json:"publisher,omitempty"`
+	ISBN             string    `json:"ISBN,omitempty"`
+	Language         string    `json:"language,omitempty"`
+	CollectionTitle  string    `json:"collection-title,omitempty"`
+	CollectionNumber string    `json:"collection-number,omitempty"`
+	CitationKey      string    `json:"citation-key,omitempty"`
+}
+
+type CSLName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type CSLDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// CSL converts a work into a CSL-JSON item
+func CSL(w Work) CSLItem {
+	item := CSLItem{
+		ID:               strconv.FormatUint(uint64(w.ID), 10),
+		Type:             "book",
+		Title:            w.Title,
+		Publisher:        w.Publisher,
+		ISBN:             w.ISBN,
+		Language:         w.Language,
+		CollectionTitle:  w.Series,
+		CollectionNumber: seriesNumber(w.SeriesNumber),
+		CitationKey:      Key(w),
+	}
+	for _, n := range w.Authors {
+		item.Author = append(item.Author, CSLName{Family: n.Family, Given: n.Given, Literal: n.Literal})
+	}
+	if w.Year != nil {
+		item.Issued = &CSLDate{DateParts: [][]int{{*w.Year}}}
+	}
+	return item
+}
+
+// ===== Rendered styles =====
+
+// APA renders an APA 7 reference: Tolkien, J. R. R. (1954). The Fellowship of the Ring. Allen & Unwin.
+func APA(w Work) string {
+	names := make([]string, len(w.Authors))
+	for i, n := range w.Authors {
+		names[i] = n.Literal
+		if n.Literal == "" {
+			names[i] = n.Family + ", " + n.Initials()
+		}
+	}
+
+	year := "n.d."
+	if w.Year != nil {
+		year = strconv.Itoa(*w.Year)
+	}
+
+	parts := []string{}
+	if len(names) > 0 {
+		parts = append(parts, endWith(joinNames(names, ", ", ", & ", ", & "), "."))
+	}
+	parts = append(parts, "("+year+").", endWith(w.Title, "."))
+	if w.Publisher != "" {
+		parts = append(parts, endWith(w.Publisher, "."))
+	}
+	return strings.Join(parts, " ")
+}
+
+// MLA renders an MLA 9 works-cited entry: Tolkien, J. R. R. The Fellowship of the Ring. Allen & Unwin, 1954.
+func MLA(w Work) string {
+	var parts []string
+	switch len(w.Authors) {
+	case 0:
+	case 1:
+		parts = append(parts, endWith(w.Authors[0].Inverted(), "."))
+	case 2:
+		parts = append(parts, endWith(w.Authors[0].Inverted()+", and "+w.Authors[1].Direct(), "."))
+	default:
+		parts = append(parts, endWith(w.Authors[0].Inverted()+", et al", "."))
+	}
+	parts = append(parts, endWith(w.Title, "."))
+	if pub := publication(w, ", "); pub != "" {
+		parts = append(parts, pub+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Chicago renders a Chicago 17 bibliography entry: Tolkien, J. R. R. The Fellowship of the Ring. Allen & Unwin, 1954.
+func Chicago(w Work) string {
+	var parts []string
+	if len(w.Authors) > 0 {
+		names := make([]string, len(w.Authors))
+		for i, n := range w.Authors {
+			names[i] = n.Direct()
+		}
+		names[0] = w.Authors[0].Inverted() // only the first author is inverted
+		parts = append(parts, endWith(joinNames(names, ", ", ", and ", " and "), "."))
+	}
+	parts = append(parts, endWith(w.Title, "."))
+	if w.Series != "" {
+		parts = append(parts, endWith(strings.TrimSpace(w.Series+" "+seriesNumber(w.SeriesNumber)), "."))
+	}
+	if pub := publication(w, ", "); pub != "" {
+		parts = append(parts, pub+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// publication joins publisher and year, e.g. "Allen & Unwin, 1954"
+func publication(w Work, sep string) string {
+	var parts []string
+	if w.Publisher != "" {
+		parts = append(parts, w.Publisher)
+	}
+	if w.Year != nil {
+		parts = append(parts, strconv.Itoa(*w.Year))
+	}
+	return strings.Join(parts, sep)
+}
+
+// joinNames joins a list as "A", "A and B" (pair) or "A, B, and C" (last)
+func joinNames(names []string, sep, last, pair string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + pair + names[1]
+	}
+	return strings.Join(names[:len(names)-1], sep) + last + names[len(names)-1]
+}
+
+// endWith adds the punctuation unless the text already ends with a sentence mark
+func endWith(s, punct string) string {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasSuffix(s, ".") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "!") {
+		return s
+	}
+	return s + punct
+}
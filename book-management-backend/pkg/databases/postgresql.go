@@ -7,6 +7,7 @@ import (
 
 	config "book-management/configs"
 	"book-management/internal/models"
+	"book-management/pkg/utils"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -31,7 +32,8 @@ func ConnectPostgres(cfg *config.Config) (*gorm.DB, error) {
 	)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+		Logger:         gormLogger,
+		TranslateError: true, // map unique violations to gorm.ErrDuplicatedKey
 	})
 	if err != nil {
 		return nil, err
@@ -42,11 +44,80 @@ func ConnectPostgres(cfg *config.Config) (*gorm.DB, error) {
 	// Auto migrate models
 	if err := db.AutoMigrate(
 		&models.Author{},
+		&models.Series{},
+		&models.Work{},
 		&models.Book{},
 		&models.User{},
 		&models.Genre{},
+		&models.Tag{},
+		&models.ImportJob{},
+		&models.ImportRowError{},
+		&models.BookFile{},
+		&models.Copy{},
+		&models.CirculationPolicy{},
+		&models.Loan{},
+		&models.Hold{},
 	); err != nil {
 		return nil, err
 	}
+
+	// The email index became partial so authors may be imported without an email, drop the old full index
+	if err := db.Exec("DROP INDEX IF EXISTS idx_authors_email").Error; err != nil {
+		return nil, err
+	}
+
+	backfillGenreSlugs(db)
+	backfillISBN13(db)
+
+	if err := setupFullTextSearch(db); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
+
+// backfillISBN13 converts the ISBN-10 stored before books were saved with their ISBN-13.
+// Books that would duplicate another edition's ISBN are skipped and logged so an admin can merge them.
+func backfillISBN13(db *gorm.DB) {
+	var books []models.Book
+	if err := db.Select("id", "isbn").Where("LENGTH(isbn) = 10").Find(&books).Error; err != nil {
+		log.Printf("⚠️ Failed to load books for ISBN-13 backfill: %v", err)
+		return
+	}
+
+	for _, book := range books {
+		isbn := utils.ISBN10To13(book.ISBN)
+		if err := db.Model(&models.Book{}).Where("id = ?", book.ID).UpdateColumn("isbn", isbn).Error; err != nil {
+			log.Printf("⚠️ Skipped ISBN-13 backfill for book [%d] %s: %v", book.ID, book.ISBN, err)
+		}
+	}
+}
+
+// backfillGenreSlugs fills normalized_name and slug for genres created before these columns existed.
+// Genres whose names clash case-insensitively are skipped and logged so an admin can merge them.
+func backfillGenreSlugs(db *gorm.DB) {
+	var genres []models.Genre
+	if err := db.Where("slug IS NULL OR slug = ''").Find(&genres).Error; err != nil {
+		log.Printf("⚠️ Failed to load genres for slug backfill: %v", err)
+		return
+	}
+
+	for _, genre := range genres {
+		slug := utils.Slugify(genre.Name)
+		if slug == "" {
+			slug = "genre"
+		}
+
+		var count int64
+		db.Model(&models.Genre{}).Where("slug = ?", slug).Count(&count)
+		if count > 0 {
+			slug = fmt.Sprintf("%s-%d", slug, genre.ID)
+		}
+
+		if err := db.Model(&genre).Updates(map[string]interface{}{
+			"normalized_name": utils.NormalizeName(genre.Name),
+			"slug":            slug,
+		}).Error; err != nil {
+			log.Printf("⚠️ Skipped slug backfill for genre [%d] %q: %v", genre.ID, genre.Name, err)
+		}
+	}
+}
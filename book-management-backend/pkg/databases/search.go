@@ -0,0 +1,121 @@
+package databases
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+/*
+Full-text search setup (PostgreSQL only), run after AutoMigrate:
+- authors.search_vector / genres.search_vector: generated columns over the name.
+- books.search_vector: weighted vector kept up to date by triggers because it spans tables
+  (A: title, B: author name + genre names, C: description).
+- GIN indexes on all three columns.
+- pg_trgm + unaccent: trigram GIN indexes on accent-stripped, lowercased book titles and
+  author names for typo-tolerant type-ahead (f_unaccent is an IMMUTABLE wrapper so it can be indexed).
+The "simple" text search configuration is used everywhere: it does no language-specific
+stemming, which suits a mixed English/Vietnamese catalogue.
+All statements are idempotent so they can run on every start.
+*/
+
+var fullTextSearchStatements = []string{
+	// Authors & genres: a single weighted name, maintained by Postgres itself
+	`ALTER TABLE authors ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(name, '')), 'A')) STORED`,
+	`ALTER TABLE genres ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (setweight(to_tsvector('simple', coalesce(name, '')), 'A')) STORED`,
+
+	// Books: vector built from the book row plus its author and genres
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE OR REPLACE FUNCTION book_search_vector(b_id bigint, b_title text, b_description text, b_author_id bigint)
+	RETURNS tsvector LANGUAGE sql STABLE AS $$
+		SELECT setweight(to_tsvector('simple', coalesce(b_title, '')), 'A')
+			|| setweight(to_tsvector('simple', coalesce((SELECT name FROM authors WHERE id = b_author_id), '')), 'B')
+			|| setweight(to_tsvector('simple', coalesce((
+				SELECT string_agg(g.name, ' ') FROM genres g
+				JOIN book_genres bg ON bg.genre_id = g.id
+				WHERE bg.book_id = b_id), '')), 'B')
+			|| setweight(to_tsvector('simple', coalesce(b_description, '')), 'C')
+	$$`,
+
+	// Row changes on books
+	`CREATE OR REPLACE FUNCTION books_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		NEW.search_vector := book_search_vector(NEW.id, NEW.title, NEW.description, NEW.author_id);
+		RETURN NEW;
+	END
+	$$`,
+	`DROP TRIGGER IF EXISTS books_search_vector_update ON books`,
+	`CREATE TRIGGER books_search_vector_update BEFORE INSERT OR UPDATE OF title, description, author_id
+		ON books FOR EACH ROW EXECUTE FUNCTION books_search_vector_trigger()`,
+
+	// Genre assignment changes
+	`CREATE OR REPLACE FUNCTION book_genres_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+	DECLARE
+		target_id bigint := CASE WHEN TG_OP = 'DELETE' THEN OLD.book_id ELSE NEW.book_id END;
+	BEGIN
+		UPDATE books SET search_vector = book_search_vector(id, title, description, author_id) WHERE id = target_id;
+		RETURN NULL;
+	END
+	$$`,
+	`DROP TRIGGER IF EXISTS book_genres_search_vector_update ON book_genres`,
+	`CREATE TRIGGER book_genres_search_vector_update AFTER INSERT OR DELETE
+		ON book_genres FOR EACH ROW EXECUTE FUNCTION book_genres_search_vector_trigger()`,
+
+	// Author / genre renames
+	`CREATE OR REPLACE FUNCTION authors_books_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE books SET search_vector = book_search_vector(id, title, description, author_id) WHERE author_id = NEW.id;
+		RETURN NULL;
+	END
+	$$`,
+	`DROP TRIGGER IF EXISTS authors_books_search_vector_update ON authors`,
+	`CREATE TRIGGER authors_books_search_vector_update AFTER UPDATE OF name
+		ON authors FOR EACH ROW EXECUTE FUNCTION authors_books_search_vector_trigger()`,
+	`CREATE OR REPLACE FUNCTION genres_books_search_vector_trigger() RETURNS trigger LANGUAGE plpgsql AS $$
+	BEGIN
+		UPDATE books SET search_vector = book_search_vector(id, title, description, author_id)
+		WHERE id IN (SELECT book_id FROM book_genres WHERE genre_id = NEW.id);
+		RETURN NULL;
+	END
+	$$`,
+	`DROP TRIGGER IF EXISTS genres_books_search_vector_update ON genres`,
+	`CREATE TRIGGER genres_books_search_vector_update AFTER UPDATE OF name
+		ON genres FOR EACH ROW EXECUTE FUNCTION genres_books_search_vector_trigger()`,
+
+	// Backfill rows created before the trigger existed
+	`UPDATE books SET search_vector = book_search_vector(id, title, description, author_id) WHERE search_vector IS NULL`,
+
+	// Indexes
+	`CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_authors_search_vector ON authors USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_genres_search_vector ON genres USING GIN (search_vector)`,
+
+	// Fuzzy, accent-insensitive matching for suggestions
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT AS
+		$$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$`,
+	`CREATE INDEX IF NOT EXISTS idx_books_title_trgm ON books USING GIN (lower(f_unaccent(title)) gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_authors_name_trgm ON authors USING GIN (lower(f_unaccent(name)) gin_trgm_ops)`,
+}
+
+// setupFullTextSearch creates the search columns, triggers and indexes
+func setupFullTextSearch(db *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range fullTextSearchStatements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("✅ Full-text search ready")
+	return nil
+}
@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Streaming tabular writers for catalogue exports:
+- Rows are written one at a time, nothing is buffered beyond a small write buffer,
+  so an export of any size runs in constant memory.
+- Every format shares the same column list. Values may be strings, integers, floats,
+  pointers to those (nil = empty), time.Time or []string.
+- CSV joins []string with "|" (the separator accepted by the CSV import), JSON Lines keeps
+  them as arrays and writes one object per line with keys in column order.
+*/
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatCSV, FormatJSONL, FormatXLSX}
+
+// ListSeparator joins list values in CSV cells
+const ListSeparator = "|"
+
+// ParseFormat validates a format name, an empty name means CSV
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format [%s], allowed: csv, jsonl, xlsx", s)
+}
+
+// ContentType is the MIME type of a file in this format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Writer writes one row per call, Close must be called to complete the file
+type Writer interface {
+	Write(values []any) error
+	Close() error
+}
+
+// NewWriter starts a file with the given columns, sheet names the XLSX worksheet
+func NewWriter(format Format, w io.Writer, columns []string, sheet string) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return newJSONLWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns, sheet)
+	}
+	return newCSVWriter(w, columns)
+}
+
+// ===== CSV =====
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(values []any) error {
+	for i, v := range values {
+		cw.record[i] = formatText(v)
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// ===== JSON Lines =====
+
+type jsonlWriter struct {
+	w    *bufio.Writer
+	keys [][]byte // pre-encoded `"column":` prefixes
+}
+
+func newJSONLWriter(w io.Writer, columns []string) (*jsonlWriter, error) {
+	jw := &jsonlWriter{w: bufio.NewWriter(w), keys: make([][]byte, len(columns))}
+	for i, c := range columns {
+		key, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		jw.keys[i] = append(key, ':')
+	}
+	return jw, nil
+}
+
+func (jw *jsonlWriter) Write(values []any) error {
+	jw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		jw.w.Write(jw.keys[i])
+
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(b)
+	}
+	jw.w.WriteByte('}')
+	return jw.w.WriteByte('\n')
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
+
+// formatText renders a value as cell text, nil pointers become ""
+func formatText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ListSeparator)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Minimal streaming XLSX (Office Open XML) writer:
+- A single worksheet, the first row holds the column names.
+- Numbers are written as numeric cells, everything else as inline strings, so no shared
+  string table has to be kept in memory.
+- The package parts are written up front and the worksheet last, the zip is produced on the fly.
+*/
+
+const (
+	xlsxMaxRows      = 1048576 // rows per worksheet, header included
+	xlsxMaxCellChars = 32767
+	xlsxMaxSheetName = 31
+)
+
+var ErrTooManyRows = errors.New("too many rows for a single XLSX worksheet")
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+func newXLSXWriter(w io.Writer, columns []string, sheet string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, escapeXML(sheetName(sheet)))},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zip: zw, sheet: bufio.NewWriter(f)}
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	if err := xw.Write(header); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(values []any) error {
+	if xw.rows == xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	xw.rows++
+
+	xw.sheet.WriteString("<row>")
+	for _, v := range values {
+		if n, ok := numericText(v); ok {
+			xw.sheet.WriteString(`<c t="n"><v>` + n + `</v></c>`)
+			continue
+		}
+
+		text := formatText(v)
+		if text == "" {
+			xw.sheet.WriteString("<c/>")
+			continue
+		}
+		xw.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xw.sheet.WriteString(escapeXML(truncateCell(text)))
+		xw.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := xw.sheet.WriteString("</row>")
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString("</sheetData></worksheet>")
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// numericText returns the text of numeric values, nil pointers are not numeric
+func numericText(v any) (string, bool) {
+	switch v := v.(type) {
+	case int, int64, uint, uint64:
+		return formatText(v), true
+	case float64:
+		return formatText(v), true
+	case *int, *uint, *float64:
+		text := formatText(v)
+		return text, text != ""
+	}
+	return "", false
+}
+
+// escapeXML escapes text content, characters that are invalid in XML are replaced by U+FFFD
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// truncateCell cuts text to the number of characters a cell can hold
+func truncateCell(s string) string {
+	if utf8.RuneCountInString(s) <= xlsxMaxCellChars {
+		return s
+	}
+	return string([]rune(s)[:xlsxMaxCellChars])
+}
+
+// sheetName drops the characters Excel forbids in sheet names and enforces the length limit
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "Sheet1"
+	}
+	if len([]rune(name)) > xlsxMaxSheetName {
+		name = string([]rune(name)[:xlsxMaxSheetName])
+	}
+	return name
+}
@@ -0,0 +1,110 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+/*
+This file handles sparse fieldsets and opt-in relations of list responses:
+- fields=id,title keeps only those top-level keys of each item, "id" is always kept.
+- include=books,genres embeds relations, relations that are not included are left out entirely
+  (and are not loaded by the repositories either).
+Relation names are the JSON keys of the relations in the response.
+*/
+
+// Selection is a validated fields/include pair
+type Selection struct {
+	fields    []string // empty means every non-relation field
+	include   []string
+	relations []string
+}
+
+// Parse validates comma separated fields and include lists against what a response offers
+func Parse(fieldsStr, includeStr string, fields, relations []string) (Selection, error) {
+	s := Selection{relations: relations}
+
+	for _, name := range splitList(includeStr) {
+		if !slices.Contains(relations, name) {
+			return s, fmt.Errorf("invalid include [%s], allowed: %s", name, strings.Join(relations, ", "))
+		}
+		if !slices.Contains(s.include, name) {
+			s.include = append(s.include, name)
+		}
+	}
+
+	for _, name := range splitList(fieldsStr) {
+		if slices.Contains(relations, name) {
+			return s, fmt.Errorf("[%s] is a relation, use include=%s to embed it", name, name)
+		}
+		if !slices.Contains(fields, name) {
+			return s, fmt.Errorf("invalid field [%s]", name)
+		}
+		if !slices.Contains(s.fields, name) {
+			s.fields = append(s.fields, name)
+		}
+	}
+
+	return s, nil
+}
+
+// Include returns the relations to load
+func (s Selection) Include() []string {
+	return s.include
+}
+
+// Apply renders v (a response struct) as a map holding only the selected fields and included relations
+func (s Selection) Apply(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var item map[string]any
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+
+	for key := range item {
+		switch {
+		case slices.Contains(s.relations, key):
+			if !slices.Contains(s.include, key) {
+				delete(item, key)
+			}
+		case len(s.fields) > 0 && key != "id" && !slices.Contains(s.fields, key):
+			delete(item, key)
+		}
+	}
+	return item, nil
+}
+
+// JSONFields lists the JSON keys of a struct, e.g. JSONFields(BookResponse{})
+func JSONFields(v any) []string {
+	t := reflect.TypeOf(v)
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = t.Field(i).Name
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
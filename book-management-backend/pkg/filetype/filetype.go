@@ -0,0 +1,93 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+/*
+Detection of the digital files that can be attached to a book (e-books and audiobooks).
+The type is sniffed from the first bytes of the content, never taken from the file name
+or the Content-Type sent by the client:
+- EPUB: a ZIP archive whose first entry is an uncompressed "mimetype" file
+  containing "application/epub+zip" (required by the OCF specification),
+- PDF: "%PDF-" header,
+- MP3: an ID3v2 tag or an MPEG audio layer III frame header.
+*/
+
+type Format string
+
+const (
+	FormatEPUB Format = "epub"
+	FormatPDF  Format = "pdf"
+	FormatMP3  Format = "mp3"
+)
+
+// HeaderSize is the number of leading bytes Sniff needs to recognize every format
+const HeaderSize = 512
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, allowed: EPUB, PDF, MP3")
+
+// ContentType is the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatMP3:
+		return "audio/mpeg"
+	}
+	return "application/octet-stream"
+}
+
+// Extension is the usual file extension of the format, with the leading dot
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Sniff detects the format from the first bytes of a file, see HeaderSize
+func Sniff(header []byte) (Format, error) {
+	switch {
+	case isEPUB(header):
+		return FormatEPUB, nil
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		return FormatPDF, nil
+	case isMP3(header):
+		return FormatMP3, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func isEPUB(header []byte) bool {
+	const localHeaderSize = 30
+	if len(header) < localHeaderSize || !bytes.HasPrefix(header, []byte("PK\x03\x04")) {
+		return false
+	}
+	// The mimetype entry must be stored, not compressed
+	if binary.LittleEndian.Uint16(header[8:10]) != 0 {
+		return false
+	}
+	nameLen := int(binary.LittleEndian.Uint16(header[26:28]))
+	extraLen := int(binary.LittleEndian.Uint16(header[28:30]))
+	name := header[localHeaderSize:min(len(header), localHeaderSize+nameLen)]
+	if string(name) != "mimetype" {
+		return false
+	}
+	content := header[min(len(header), localHeaderSize+nameLen+extraLen):]
+	return bytes.HasPrefix(content, []byte(FormatEPUB.ContentType()))
+}
+
+func isMP3(header []byte) bool {
+	if bytes.HasPrefix(header, []byte("ID3")) {
+		return true
+	}
+	// Frame sync (11 bits set), then MPEG version 1, 2 or 2.5 and layer III
+	if len(header) < 2 || header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	return version != 0x01 && layer == 0x01
+}
@@ -0,0 +1,126 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registers the JPEG header decoder used by DecodeConfig
+	_ "image/png"  // registers the PNG header decoder used by DecodeConfig
+	"io"
+)
+
+/*
+Validation and sanitizing of uploaded images, without decoding the pixels:
+- the format is sniffed from the content (JPEG, PNG and WebP only), never taken from the file name,
+- byte size and dimensions are checked against Limits,
+- metadata (EXIF, XMP, IPTC, comments, text chunks) is stripped losslessly, see strip.go,
+  the EXIF orientation is kept aside so the image can still be displayed upright,
+- the SHA-256 of the sanitized bytes names the image, identical uploads share one name.
+*/
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatWebP Format = "webp"
+)
+
+// ContentType is the MIME type of the format
+func (f Format) ContentType() string {
+	return "image/" + string(f)
+}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format, allowed: JPEG, PNG, WebP")
+	ErrTooLarge          = errors.New("image is too large")
+	ErrCorrupt           = errors.New("image is corrupt")
+)
+
+// Limits bounds accepted images, zero values disable a check
+type Limits struct {
+	MaxBytes     int64
+	MaxDimension int // maximum width and height in pixels
+}
+
+// Image is a validated upload with its metadata stripped
+type Image struct {
+	Data        []byte
+	Format      Format
+	Width       int
+	Height      int
+	Orientation int    // EXIF orientation 1-8 found before stripping, 1 when absent
+	Hash        string // hex SHA-256 of Data
+}
+
+// Sniff detects the format from the magic bytes
+func Sniff(data []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return FormatJPEG, nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG, nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// dimensions reads the width and height from the image header
+func dimensions(data []byte, format Format) (int, int, error) {
+	if format == FormatWebP {
+		return webpDimensions(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Process reads, validates and sanitizes an image
+func Process(r io.Reader, limits Limits) (*Image, error) {
+	if limits.MaxBytes > 0 {
+		r = io.LimitReader(r, limits.MaxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", ErrTooLarge, limits.MaxBytes)
+	}
+
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	width, height, err := dimensions(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if width <= 0 || height <= 0 {
+		return nil, ErrCorrupt
+	}
+	if limits.MaxDimension > 0 && (width > limits.MaxDimension || height > limits.MaxDimension) {
+		return nil, fmt.Errorf("%w, %dx%d exceeds %dx%d pixels", ErrTooLarge, width, height, limits.MaxDimension, limits.MaxDimension)
+	}
+
+	clean, orientation, err := Strip(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(clean)
+	return &Image{
+		Data:        clean,
+		Format:      format,
+		Width:       width,
+		Height:      height,
+		Orientation: orientation,
+		Hash:        hex.EncodeToString(sum[:]),
+	}, nil
+}
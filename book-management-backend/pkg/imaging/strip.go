@@ -0,0 +1,209 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Strip removes metadata from the image and returns the EXIF orientation it carried (1 when none)
+func Strip(data []byte, format Format) ([]byte, int, error) {
+	switch format {
+	case FormatJPEG:
+		return stripJPEG(data)
+	case FormatPNG:
+		return stripPNG(data)
+	case FormatWebP:
+		return stripWebP(data)
+	}
+	return nil, 0, ErrUnsupportedFormat
+}
+
+// stripJPEG drops APP1 (EXIF, XMP), APP3-APP13 (IPTC, vendor data), APP15 and comments up to
+// the start of scan. APP0 (JFIF), ICC profiles in APP2 and APP14 (Adobe colour transform) are kept.
+func stripJPEG(data []byte) ([]byte, int, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2]) // SOI
+	orientation := 1
+
+	pos := 2
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, 0, ErrCorrupt
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // fill byte
+			pos++
+			continue
+		}
+		if marker == 0xDA { // start of scan, the rest is entropy coded data
+			out.Write(data[pos:])
+			return out.Bytes(), orientation, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		payload := data[pos+4 : end]
+
+		keep := true
+		switch {
+		case marker == 0xE1: // APP1
+			if bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+				orientation = exifOrientation(payload[6:])
+			}
+			keep = false
+		case marker == 0xE2: // APP2
+			keep = bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+		case marker >= 0xE3 && marker <= 0xED, marker == 0xEF, marker == 0xFE:
+			keep = false
+		}
+		if keep {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+}
+
+// pngDroppedChunks carry metadata, none of them is needed to render the image
+var pngDroppedChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, int, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:8]) // signature
+	orientation := 1
+
+	pos := 8
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length // length, type, data, CRC
+		if length < 0 || end > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		kind := string(data[pos+4 : pos+8])
+
+		if kind == "eXIf" {
+			orientation = exifOrientation(data[pos+8 : pos+8+length])
+		}
+		if !pngDroppedChunks[kind] {
+			out.Write(data[pos:end])
+		}
+		pos = end
+		if kind == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), orientation, nil
+}
+
+// VP8X flags announcing metadata chunks
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+// stripWebP drops the EXIF and XMP chunks and clears their VP8X flags
+func stripWebP(data []byte) ([]byte, int, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12]) // RIFF header, size patched below
+	orientation := 1
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		kind := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // chunks are padded to an even size
+		if size < 0 || pos+8+size > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		end = min(end, len(data))
+
+		switch kind {
+		case "EXIF":
+			payload := data[pos+8 : pos+8+size]
+			orientation = exifOrientation(bytes.TrimPrefix(payload, []byte("Exif\x00\x00")))
+		case "XMP ":
+		case "VP8X":
+			chunk := bytes.Clone(data[pos:end])
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	clean := out.Bytes()
+	binary.LittleEndian.PutUint32(clean[4:], uint32(len(clean)-8))
+	return clean, orientation, nil
+}
+
+// webpDimensions reads the canvas size from the first chunk (VP8X, VP8L or VP8)
+func webpDimensions(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, ErrCorrupt
+	}
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8X":
+		w := int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16
+		h := int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16
+		return w + 1, h + 1, nil
+	case "VP8L":
+		if chunk[0] != 0x2F {
+			return 0, 0, ErrCorrupt
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		return int(bits&0x3FFF) + 1, int(bits>>14&0x3FFF) + 1, nil
+	case "VP8 ":
+		if chunk[3] != 0x9D || chunk[4] != 0x01 || chunk[5] != 0x2A {
+			return 0, 0, ErrCorrupt
+		}
+		return int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3FFF), int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3FFF), nil
+	}
+	return 0, 0, ErrCorrupt
+}
+
+// exifOrientation reads tag 0x0112 from the first IFD of a TIFF structured EXIF block, 1 when absent
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := range count {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
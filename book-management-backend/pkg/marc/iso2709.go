@@ -0,0 +1,209 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+/*
+MARC 21 binary (ISO 2709) serialization:
+- leader (24 bytes) | directory (12 bytes per field: tag, length, start) | field terminator
+- then the fields, each ending with a field terminator, and a record terminator.
+Records are read and written as UTF-8 (leader position 09 = "a"), MARC-8 records are rejected.
+*/
+
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+
+	leaderLength   = 24
+	directoryEntry = 12
+	maxRecordLen   = 99999
+)
+
+var ErrMARC8 = errors.New("MARC-8 encoded records are not supported, convert the file to UTF-8")
+
+// Reader reads binary records one at a time
+type Reader struct {
+	r *bufio.Reader
+	n int // records read
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record, io.EOF after the last one
+func (rd *Reader) Read() (*Record, error) {
+	// Tolerate line breaks some tools add between records
+	for {
+		b, err := rd.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] != '\n' && b[0] != '\r' {
+			break
+		}
+		rd.r.ReadByte()
+	}
+	rd.n++
+
+	head, err := rd.r.Peek(5)
+	if err != nil {
+		return nil, fmt.Errorf("record %d: truncated leader", rd.n)
+	}
+	length, ok := parseDigits(head)
+	if !ok || length < leaderLength+1 {
+		return nil, fmt.Errorf("record %d: invalid record length %q", rd.n, head)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(rd.r, data); err != nil {
+		return nil, fmt.Errorf("record %d: truncated record", rd.n)
+	}
+
+	rec, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("record %d: %w", rd.n, err)
+	}
+	return rec, nil
+}
+
+// Unmarshal decodes one binary record
+func Unmarshal(data []byte) (*Record, error) {
+	if len(data) < leaderLength || data[len(data)-1] != recordTerminator {
+		return nil, errors.New("missing record terminator")
+	}
+	leader := string(data[:leaderLength])
+	if leader[9] != 'a' {
+		return nil, ErrMARC8
+	}
+
+	base, ok := parseDigits([]byte(leader[12:17]))
+	if !ok || base <= leaderLength || base > len(data) {
+		return nil, fmt.Errorf("invalid base address %q", leader[12:17])
+	}
+
+	directory := data[leaderLength : base-1]
+	if len(directory)%directoryEntry != 0 {
+		return nil, errors.New("invalid directory length")
+	}
+
+	rec := &Record{Leader: leader}
+	for i := 0; i < len(directory); i += directoryEntry {
+		entry := directory[i : i+directoryEntry]
+		tag := string(entry[:3])
+		length, ok1 := parseDigits(entry[3:7])
+		start, ok2 := parseDigits(entry[7:12])
+		if !ok1 || !ok2 || length < 1 || base+start+length > len(data) {
+			return nil, fmt.Errorf("invalid directory entry for field %s", tag)
+		}
+
+		raw := data[base+start : base+start+length-1] // without the field terminator
+		if !utf8.Valid(raw) {
+			return nil, fmt.Errorf("field %s is not valid UTF-8", tag)
+		}
+
+		if IsControl(tag) {
+			rec.Fields = append(rec.Fields, NewControlField(tag, string(raw)))
+			continue
+		}
+
+		if len(raw) < 2 {
+			return nil, fmt.Errorf("field %s is missing its indicators", tag)
+		}
+		f := Field{Tag: tag, Ind1: raw[0], Ind2: raw[1]}
+		for _, part := range bytes.Split(raw[2:], []byte{subfieldDelimiter}) {
+			if len(part) == 0 {
+				continue
+			}
+			f.Subfields = append(f.Subfields, Subfield{Code: part[0], Value: string(part[1:])})
+		}
+		rec.Fields = append(rec.Fields, f)
+	}
+	return rec, nil
+}
+
+// Marshal encodes a record, the lengths and addresses of the leader are computed
+func Marshal(rec *Record) ([]byte, error) {
+	var directory, body bytes.Buffer
+
+	for _, f := range rec.Fields {
+		if len(f.Tag) != 3 {
+			return nil, fmt.Errorf("invalid tag %q", f.Tag)
+		}
+
+		start := body.Len()
+		if IsControl(f.Tag) {
+			body.WriteString(f.Value)
+		} else {
+			body.WriteByte(indicator(f.Ind1))
+			body.WriteByte(indicator(f.Ind2))
+			for _, sf := range f.Subfields {
+				body.WriteByte(subfieldDelimiter)
+				body.WriteByte(sf.Code)
+				body.WriteString(sf.Value)
+			}
+		}
+		body.WriteByte(fieldTerminator)
+
+		length := body.Len() - start
+		if length > 9999 {
+			return nil, fmt.Errorf("field %s is too long", f.Tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", f.Tag, length, start)
+	}
+	directory.WriteByte(fieldTerminator)
+
+	base := leaderLength + directory.Len()
+	total := base + body.Len() + 1
+	if total > maxRecordLen {
+		return nil, errors.New("record is too long")
+	}
+
+	leader := []byte(rec.Leader)
+	if len(leader) != leaderLength {
+		leader = []byte(DefaultLeader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", total))
+	leader[9] = 'a' // UCS/Unicode
+	leader[10], leader[11] = '2', '2'
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	out := make([]byte, 0, total)
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, body.Bytes()...)
+	return append(out, recordTerminator), nil
+}
+
+// DefaultLeader describes a new, complete bibliographic record of a monograph, lengths are filled in by Marshal
+const DefaultLeader = "00000nam a2200000 i 4500"
+
+func indicator(b byte) byte {
+	if b == 0 {
+		return ' '
+	}
+	return b
+}
+
+// parseDigits reads an unsigned decimal number, the leader and directory hold digits only
+func parseDigits(b []byte) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
@@ -0,0 +1,90 @@
+package marc
+
+import (
+	"bytes"
+	"testing"
+)
+
+// validRecord encodes a record with a control field (directory entry 0) and a data field (entry 1)
+func validRecord(t *testing.T) []byte {
+	t.Helper()
+	rec := &Record{Leader: DefaultLeader}
+	rec.Add(NewControlField("001", "ocm12345"))
+	rec.Add(NewDataField("245", '1', '0', "a", "The Hobbit"))
+	data, err := Marshal(rec)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return data
+}
+
+// setEntry overwrites bytes of the n-th directory entry, at offset within the entry
+func setEntry(data []byte, n, offset int, value string) {
+	copy(data[leaderLength+n*directoryEntry+offset:], value)
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(data []byte)
+	}{
+		{"negative field start", func(d []byte) { setEntry(d, 1, 7, "-0100") }},
+		{"signed field start", func(d []byte) { setEntry(d, 1, 7, "+0000") }},
+		{"field start past the end", func(d []byte) { setEntry(d, 1, 7, "99999") }},
+		{"negative field length", func(d []byte) { setEntry(d, 1, 3, "-005") }},
+		{"zero field length", func(d []byte) { setEntry(d, 1, 3, "0000") }},
+		{"field length past the end", func(d []byte) { setEntry(d, 1, 3, "9999") }},
+		{"non-digit field length", func(d []byte) { setEntry(d, 0, 3, "00 9") }},
+		{"negative base address", func(d []byte) { copy(d[12:17], "-0030") }},
+		{"signed base address", func(d []byte) { copy(d[12:17], "+0049") }},
+		{"base address inside the leader", func(d []byte) { copy(d[12:17], "00010") }},
+		{"base address past the end", func(d []byte) { copy(d[12:17], "99999") }},
+		{"non-digit base address", func(d []byte) { copy(d[12:17], "00x49") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := validRecord(t)
+			tt.mutate(data)
+			if _, err := Unmarshal(data); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalValid(t *testing.T) {
+	rec, err := Unmarshal(validRecord(t))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got := rec.ControlField("001"); got != "ocm12345" {
+		t.Errorf("001 = %q, want %q", got, "ocm12345")
+	}
+	fields := rec.DataFields("245")
+	if len(fields) != 1 || fields[0].Subfield('a') != "The Hobbit" {
+		t.Errorf("245 = %+v, want $a The Hobbit", fields)
+	}
+}
+
+func TestReaderMalformedLeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		length string
+	}{
+		{"negative record length", "-0050"},
+		{"signed record length", "+0050"},
+		{"non-digit record length", "00a50"},
+		{"record length shorter than the leader", "00010"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := validRecord(t)
+			copy(data[0:5], tt.length)
+			if _, err := NewReader(bytes.NewReader(data)).Read(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package marc
+
+// MARC language codes (008/35-37, 041) of the ISO 639-1 codes used by the catalogue.
+// MARC uses bibliographic codes, e.g. "fre" rather than "fra".
+var languageCodes = map[string]string{
+	"ar": "ara",
+	"de": "ger",
+	"en": "eng",
+	"es": "spa",
+	"fr": "fre",
+	"hi": "hin",
+	"id": "ind",
+	"it": "ita",
+	"ja": "jpn",
+	"ko": "kor",
+	"nl": "dut",
+	"pl": "pol",
+	"pt": "por",
+	"ru": "rus",
+	"sv": "swe",
+	"th": "tha",
+	"tr": "tur",
+	"uk": "ukr",
+	"vi": "vie",
+	"zh": "chi",
+}
+
+var languageCodesReverse = func() map[string]string {
+	m := make(map[string]string, len(languageCodes))
+	for iso, marc := range languageCodes {
+		m[marc] = iso
+	}
+	return m
+}()
+
+// LanguageToMARC converts an ISO 639-1 code, unknown codes give "und" (undetermined)
+func LanguageToMARC(iso string) string {
+	if code, ok := languageCodes[iso]; ok {
+		return code
+	}
+	return "und"
+}
+
+// LanguageFromMARC converts a MARC language code, unknown codes give ""
+func LanguageFromMARC(code string) string {
+	return languageCodesReverse[code]
+}
@@ -0,0 +1,151 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MARCXML serialization, see https://www.loc.gov/standards/marcxml/
+
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader reads the records of a <collection> (or a single <record>) one at a time
+type XMLReader struct {
+	d *xml.Decoder
+	n int
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{d: xml.NewDecoder(r)}
+}
+
+// Read returns the next record, io.EOF after the last one
+func (xr *XMLReader) Read() (*Record, error) {
+	for {
+		tok, err := xr.d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("invalid MARCXML: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+		xr.n++
+
+		var xrec xmlRecord
+		if err := xr.d.DecodeElement(&xrec, &start); err != nil {
+			return nil, fmt.Errorf("record %d: %w", xr.n, err)
+		}
+		rec, err := fromXML(xrec)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", xr.n, err)
+		}
+		return rec, nil
+	}
+}
+
+// Controlfields come before datafields in MARCXML, which matches MARC 21 tag order
+func fromXML(xrec xmlRecord) (*Record, error) {
+	rec := &Record{Leader: xrec.Leader}
+	for _, cf := range xrec.ControlFields {
+		if len(cf.Tag) != 3 {
+			return nil, fmt.Errorf("invalid tag %q", cf.Tag)
+		}
+		rec.Fields = append(rec.Fields, NewControlField(cf.Tag, cf.Value))
+	}
+	for _, df := range xrec.DataFields {
+		if len(df.Tag) != 3 {
+			return nil, fmt.Errorf("invalid tag %q", df.Tag)
+		}
+		f := Field{Tag: df.Tag, Ind1: firstByte(df.Ind1), Ind2: firstByte(df.Ind2)}
+		for _, sf := range df.Subfields {
+			if sf.Code == "" {
+				return nil, errors.New("subfield without code in field " + df.Tag)
+			}
+			f.Subfields = append(f.Subfields, Subfield{Code: sf.Code[0], Value: sf.Value})
+		}
+		rec.Fields = append(rec.Fields, f)
+	}
+	return rec, nil
+}
+
+func toXML(rec *Record) xmlRecord {
+	xrec := xmlRecord{Leader: rec.Leader}
+	for _, f := range rec.Fields {
+		if IsControl(f.Tag) {
+			xrec.ControlFields = append(xrec.ControlFields, xmlControlField{Tag: f.Tag, Value: f.Value})
+			continue
+		}
+		df := xmlDataField{Tag: f.Tag, Ind1: string(indicator(f.Ind1)), Ind2: string(indicator(f.Ind2))}
+		for _, sf := range f.Subfields {
+			df.Subfields = append(df.Subfields, xmlSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		xrec.DataFields = append(xrec.DataFields, df)
+	}
+	return xrec
+}
+
+// XMLWriter writes records inside a <collection>, Close ends the document
+type XMLWriter struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func NewXMLWriter(w io.Writer) (*XMLWriter, error) {
+	if _, err := io.WriteString(w, xml.Header+`<collection xmlns="`+Namespace+`">`+"\n"); err != nil {
+		return nil, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &XMLWriter{w: w, enc: enc}, nil
+}
+
+func (xw *XMLWriter) Write(rec *Record) error {
+	if err := xw.enc.Encode(toXML(rec)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(xw.w, "\n")
+	return err
+}
+
+func (xw *XMLWriter) Close() error {
+	_, err := io.WriteString(xw.w, "</collection>\n")
+	return err
+}
+
+func firstByte(s string) byte {
+	if s == "" {
+		return ' '
+	}
+	return s[0]
+}
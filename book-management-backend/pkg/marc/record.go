@@ -0,0 +1,116 @@
+package marc
+
+import (
+	"strings"
+)
+
+/*
+MARC 21 bibliographic records, independent of their serialization:
+- Control fields (001-009) hold a single value, data fields have two indicators and subfields.
+- Fields keep the order they were read in, which both serializations preserve.
+See https://www.loc.gov/marc/bibliographic/ for the meaning of the tags.
+*/
+
+// Record is one MARC record
+type Record struct {
+	Leader string // 24 characters
+	Fields []Field
+}
+
+// Field is a control field (Value set) or a data field (indicators and subfields set)
+type Field struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// IsControl tells whether the tag is a control field tag (00X)
+func IsControl(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
+
+// NewControlField builds a control field
+func NewControlField(tag, value string) Field {
+	return Field{Tag: tag, Value: value}
+}
+
+// NewDataField builds a data field from code/value pairs, empty values are skipped
+func NewDataField(tag string, ind1, ind2 byte, pairs ...string) Field {
+	f := Field{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			f.Subfields = append(f.Subfields, Subfield{Code: pairs[i][0], Value: pairs[i+1]})
+		}
+	}
+	return f
+}
+
+// Add appends a field, data fields without subfields are dropped
+func (r *Record) Add(f Field) {
+	if !IsControl(f.Tag) && len(f.Subfields) == 0 {
+		return
+	}
+	r.Fields = append(r.Fields, f)
+}
+
+// ControlField returns the value of the first control field with this tag
+func (r *Record) ControlField(tag string) string {
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// DataFields returns every data field with this tag
+func (r *Record) DataFields(tag string) []Field {
+	var fields []Field
+	for _, f := range r.Fields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Subfield returns the first value of a subfield code
+func (f Field) Subfield(code byte) string {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value
+		}
+	}
+	return ""
+}
+
+// SubfieldValues returns every value of a subfield code
+func (f Field) SubfieldValues(code byte) []string {
+	var values []string
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			values = append(values, sf.Value)
+		}
+	}
+	return values
+}
+
+// TrimPunctuation strips the ISBD punctuation MARC puts at the end of subfields (" /", ":", ",", ".")
+func TrimPunctuation(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, " /:;,=")
+	// Keep the period of initials ("Tolkien, J. R. R.") and abbreviations, drop a final sentence period
+	if strings.HasSuffix(s, ".") && !strings.HasSuffix(s, "..") {
+		if i := strings.LastIndexAny(s[:len(s)-1], " ."); i < 0 || len(s)-1-i > 2 {
+			s = s[:len(s)-1]
+		}
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,92 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const DefaultGoogleBooksURL = "https://www.googleapis.com"
+
+// GoogleBooks looks books up with the Google Books volumes API, the API key is optional
+type GoogleBooks struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewGoogleBooks(baseURL, apiKey string, client *http.Client) *GoogleBooks {
+	if baseURL == "" {
+		baseURL = DefaultGoogleBooksURL
+	}
+	return &GoogleBooks{baseURL: trimSlash(baseURL), apiKey: apiKey, client: clientOrDefault(client)}
+}
+
+func (p *GoogleBooks) Name() string {
+	return "googlebooks"
+}
+
+type googleBooksVolumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo struct {
+			Title         string   `json:"title"`
+			Subtitle      string   `json:"subtitle"`
+			Authors       []string `json:"authors"`
+			Publisher     string   `json:"publisher"`
+			PublishedDate string   `json:"publishedDate"`
+			Description   string   `json:"description"`
+			Language      string   `json:"language"`
+			ImageLinks    struct {
+				SmallThumbnail string `json:"smallThumbnail"`
+				Thumbnail      string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (p *GoogleBooks) LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	query := url.Values{"q": {"isbn:" + isbn}}
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	body, err := getJSON(ctx, p.client, p.baseURL+"/books/v1/volumes?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes googleBooksVolumes
+	if err := json.Unmarshal(body, &volumes); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if len(volumes.Items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	v := volumes.Items[0].VolumeInfo
+	md := &BookMetadata{
+		ISBN:            isbn,
+		Title:           v.Title,
+		Authors:         v.Authors,
+		Publisher:       v.Publisher,
+		PublishedDate:   v.PublishedDate,
+		PublicationYear: parseYear(v.PublishedDate),
+		Description:     strings.TrimSpace(v.Description),
+		Language:        v.Language,
+		Sources:         []string{p.Name()},
+	}
+	if v.Subtitle != "" {
+		md.Title += ": " + v.Subtitle
+	}
+	cover := v.ImageLinks.Thumbnail
+	if cover == "" {
+		cover = v.ImageLinks.SmallThumbnail
+	}
+	// Google serves covers over http by default, https works the same
+	md.CoverURL = strings.Replace(cover, "http://", "https://", 1)
+	return md, nil
+}
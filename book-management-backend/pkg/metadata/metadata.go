@@ -0,0 +1,142 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+Bibliographic metadata lookup by ISBN from external sources (Open Library, Google Books).
+Every provider takes its base URL and *http.Client from the caller, so it can be pointed at a
+local HTTP stub (e.g. httptest.Server) instead of the real service.
+*/
+
+// ErrNotFound is returned when a provider has no record for the ISBN
+var ErrNotFound = errors.New("no metadata found")
+
+// BookMetadata is what a provider knows about an edition, empty fields are unknown
+type BookMetadata struct {
+	ISBN            string   `json:"isbn"`
+	Title           string   `json:"title"`
+	Authors         []string `json:"authors"`
+	Publisher       string   `json:"publisher"`
+	PublishedDate   string   `json:"published_date"` // as given by the source, e.g. "1937", "Sep 21, 1937", "1937-09-21"
+	PublicationYear *int     `json:"publication_year"`
+	Description     string   `json:"description"`
+	CoverURL        string   `json:"cover_url"`
+	Language        string   `json:"language"` // ISO 639-1 when the source says
+	Sources         []string `json:"sources"`  // providers the fields came from
+}
+
+// MetadataProvider looks up an edition by its compact ISBN (10 or 13 characters)
+type MetadataProvider interface {
+	Name() string
+	LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error)
+}
+
+// Merge fills the empty fields of dst from src, dst keeps its own values
+func Merge(dst, src *BookMetadata) {
+	fill := func(d *string, s string) {
+		if *d == "" {
+			*d = s
+		}
+	}
+	fill(&dst.ISBN, src.ISBN)
+	fill(&dst.Title, src.Title)
+	fill(&dst.Publisher, src.Publisher)
+	fill(&dst.PublishedDate, src.PublishedDate)
+	fill(&dst.Description, src.Description)
+	fill(&dst.CoverURL, src.CoverURL)
+	fill(&dst.Language, src.Language)
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	if dst.PublicationYear == nil {
+		dst.PublicationYear = src.PublicationYear
+	}
+	dst.Sources = append(dst.Sources, src.Sources...)
+}
+
+// LookupAll asks every provider in order and merges the answers, earlier providers win.
+// ErrNotFound is returned when no provider knows the ISBN, otherwise provider failures
+// are only reported when nothing was found at all.
+func LookupAll(ctx context.Context, providers []MetadataProvider, isbn string) (*BookMetadata, error) {
+	var (
+		result *BookMetadata
+		errs   []error
+	)
+	for _, p := range providers {
+		md, err := p.LookupISBN(ctx, isbn)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			}
+			continue
+		}
+		if result == nil {
+			result = &BookMetadata{}
+		}
+		Merge(result, md)
+	}
+
+	if result != nil {
+		return result, nil
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nil, ErrNotFound
+}
+
+var yearPattern = regexp.MustCompile(`\b(\d{4})\b`)
+
+// parseYear extracts the first four digit year of a free-form date
+func parseYear(date string) *int {
+	m := yearPattern.FindStringSubmatch(date)
+	if m == nil {
+		return nil
+	}
+	year, _ := strconv.Atoi(m[1])
+	return &year
+}
+
+// getJSON fetches url and returns the body of a 200 response, 404 becomes ErrNotFound
+func getJSON(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+}
+
+// clientOrDefault keeps providers usable with a nil client
+func clientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
+
+func trimSlash(s string) string {
+	return strings.TrimRight(s, "/")
+}
@@ -0,0 +1,256 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const testISBN = "9780261103344"
+
+// stubServer answers every request with status and body, and records the last request
+func stubServer(t *testing.T, status int, body string) (*httptest.Server, **http.Request) {
+	t.Helper()
+	var last *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &last
+}
+
+const openLibraryFound = `{
+	"ISBN:9780261103344": {
+		"details": {
+			"title": "The Hobbit",
+			"subtitle": "There and Back Again",
+			"publishers": ["HarperCollins", "Unwin"],
+			"publish_date": "Sep 21, 1937",
+			"description": {"type": "/type/text", "value": "  A hobbit goes on an adventure.  "},
+			"covers": [12345],
+			"authors": [{"name": "J.R.R. Tolkien"}],
+			"languages": [{"key": "/languages/eng"}]
+		}
+	}
+}`
+
+const googleBooksFound = `{
+	"totalItems": 1,
+	"items": [{
+		"volumeInfo": {
+			"title": "The Hobbit",
+			"authors": ["J. R. R. Tolkien"],
+			"publisher": "Houghton Mifflin",
+			"publishedDate": "2012-09-18",
+			"description": "Bilbo Baggins is a hobbit.",
+			"language": "en",
+			"imageLinks": {"smallThumbnail": "http://books.google.com/small", "thumbnail": "http://books.google.com/thumb"}
+		}
+	}]
+}`
+
+func TestOpenLibraryLookupISBN(t *testing.T) {
+	srv, last := stubServer(t, http.StatusOK, openLibraryFound)
+	p := NewOpenLibrary(srv.URL+"/", "https://covers.example", srv.Client())
+
+	md, err := p.LookupISBN(context.Background(), testISBN)
+	if err != nil {
+		t.Fatalf("LookupISBN: %v", err)
+	}
+
+	req := *last
+	if req.URL.Path != "/api/books" || req.URL.Query().Get("bibkeys") != "ISBN:"+testISBN || req.URL.Query().Get("jscmd") != "details" {
+		t.Errorf("unexpected request %s", req.URL)
+	}
+
+	year := 1937
+	want := &BookMetadata{
+		ISBN:            testISBN,
+		Title:           "The Hobbit: There and Back Again",
+		Authors:         []string{"J.R.R. Tolkien"},
+		Publisher:       "HarperCollins",
+		PublishedDate:   "Sep 21, 1937",
+		PublicationYear: &year,
+		Description:     "A hobbit goes on an adventure.",
+		CoverURL:        "https://covers.example/b/id/12345-L.jpg",
+		Language:        "en",
+		Sources:         []string{"openlibrary"},
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("got %+v, want %+v", md, want)
+	}
+}
+
+func TestGoogleBooksLookupISBN(t *testing.T) {
+	srv, last := stubServer(t, http.StatusOK, googleBooksFound)
+	p := NewGoogleBooks(srv.URL, "secret", srv.Client())
+
+	md, err := p.LookupISBN(context.Background(), testISBN)
+	if err != nil {
+		t.Fatalf("LookupISBN: %v", err)
+	}
+
+	req := *last
+	if req.URL.Path != "/books/v1/volumes" || req.URL.Query().Get("q") != "isbn:"+testISBN || req.URL.Query().Get("key") != "secret" {
+		t.Errorf("unexpected request %s", req.URL)
+	}
+
+	year := 2012
+	want := &BookMetadata{
+		ISBN:            testISBN,
+		Title:           "The Hobbit",
+		Authors:         []string{"J. R. R. Tolkien"},
+		Publisher:       "Houghton Mifflin",
+		PublishedDate:   "2012-09-18",
+		PublicationYear: &year,
+		Description:     "Bilbo Baggins is a hobbit.",
+		CoverURL:        "https://books.google.com/thumb",
+		Language:        "en",
+		Sources:         []string{"googlebooks"},
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("got %+v, want %+v", md, want)
+	}
+}
+
+func TestLookupISBNNotFound(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		new    func(url string, client *http.Client) MetadataProvider
+	}{
+		{"openlibrary empty object", http.StatusOK, `{}`, newTestOpenLibrary},
+		{"openlibrary 404", http.StatusNotFound, `{}`, newTestOpenLibrary},
+		{"googlebooks no items", http.StatusOK, `{"totalItems": 0}`, newTestGoogleBooks},
+		{"googlebooks 404", http.StatusNotFound, `{}`, newTestGoogleBooks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := stubServer(t, tt.status, tt.body)
+			md, err := tt.new(srv.URL, srv.Client()).LookupISBN(context.Background(), testISBN)
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("got %v, %v, want ErrNotFound", md, err)
+			}
+		})
+	}
+}
+
+func TestLookupISBNUpstreamError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		new    func(url string, client *http.Client) MetadataProvider
+	}{
+		{"openlibrary 500", http.StatusInternalServerError, `oops`, newTestOpenLibrary},
+		{"openlibrary 503", http.StatusServiceUnavailable, ``, newTestOpenLibrary},
+		{"openlibrary invalid json", http.StatusOK, `<html>`, newTestOpenLibrary},
+		{"googlebooks 500", http.StatusInternalServerError, `oops`, newTestGoogleBooks},
+		{"googlebooks 502", http.StatusBadGateway, ``, newTestGoogleBooks},
+		{"googlebooks invalid json", http.StatusOK, `<html>`, newTestGoogleBooks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := stubServer(t, tt.status, tt.body)
+			md, err := tt.new(srv.URL, srv.Client()).LookupISBN(context.Background(), testISBN)
+			if err == nil || errors.Is(err, ErrNotFound) {
+				t.Fatalf("got %v, %v, want an upstream error", md, err)
+			}
+		})
+	}
+}
+
+func TestLookupAll(t *testing.T) {
+	openLibrary, _ := stubServer(t, http.StatusOK, openLibraryFound)
+	googleBooks, _ := stubServer(t, http.StatusOK, googleBooksFound)
+	notFound, _ := stubServer(t, http.StatusOK, `{}`)
+	failing, _ := stubServer(t, http.StatusInternalServerError, ``)
+
+	t.Run("earlier providers win", func(t *testing.T) {
+		providers := []MetadataProvider{
+			newTestOpenLibrary(openLibrary.URL, openLibrary.Client()),
+			newTestGoogleBooks(googleBooks.URL, googleBooks.Client()),
+		}
+		md, err := LookupAll(context.Background(), providers, testISBN)
+		if err != nil {
+			t.Fatalf("LookupAll: %v", err)
+		}
+		if md.Title != "The Hobbit: There and Back Again" || md.Publisher != "HarperCollins" || *md.PublicationYear != 1937 {
+			t.Errorf("fields of the first provider were overwritten: %+v", md)
+		}
+		if !reflect.DeepEqual(md.Sources, []string{"openlibrary", "googlebooks"}) {
+			t.Errorf("got sources %v", md.Sources)
+		}
+	})
+
+	t.Run("missing fields are filled by later providers", func(t *testing.T) {
+		sparse, _ := stubServer(t, http.StatusOK, `{"ISBN:9780261103344": {"details": {"title": "Hobbit"}}}`)
+		providers := []MetadataProvider{
+			newTestOpenLibrary(sparse.URL, sparse.Client()),
+			newTestGoogleBooks(googleBooks.URL, googleBooks.Client()),
+		}
+		md, err := LookupAll(context.Background(), providers, testISBN)
+		if err != nil {
+			t.Fatalf("LookupAll: %v", err)
+		}
+		if md.Title != "Hobbit" {
+			t.Errorf("got title %q, want the first provider's", md.Title)
+		}
+		if md.Publisher != "Houghton Mifflin" || md.Language != "en" || md.CoverURL != "https://books.google.com/thumb" ||
+			len(md.Authors) != 1 || md.PublicationYear == nil || *md.PublicationYear != 2012 {
+			t.Errorf("empty fields were not filled: %+v", md)
+		}
+	})
+
+	t.Run("failures are ignored when a provider answers", func(t *testing.T) {
+		providers := []MetadataProvider{
+			newTestOpenLibrary(failing.URL, failing.Client()),
+			newTestGoogleBooks(googleBooks.URL, googleBooks.Client()),
+		}
+		md, err := LookupAll(context.Background(), providers, testISBN)
+		if err != nil {
+			t.Fatalf("LookupAll: %v", err)
+		}
+		if !reflect.DeepEqual(md.Sources, []string{"googlebooks"}) {
+			t.Errorf("got sources %v", md.Sources)
+		}
+	})
+
+	t.Run("not found everywhere", func(t *testing.T) {
+		providers := []MetadataProvider{
+			newTestOpenLibrary(notFound.URL, notFound.Client()),
+			newTestGoogleBooks(notFound.URL, notFound.Client()),
+		}
+		if _, err := LookupAll(context.Background(), providers, testISBN); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("failure reported when nothing is found", func(t *testing.T) {
+		providers := []MetadataProvider{
+			newTestOpenLibrary(notFound.URL, notFound.Client()),
+			newTestGoogleBooks(failing.URL, failing.Client()),
+		}
+		_, err := LookupAll(context.Background(), providers, testISBN)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			t.Fatalf("got %v, want the googlebooks failure", err)
+		}
+	})
+}
+
+func newTestOpenLibrary(url string, client *http.Client) MetadataProvider {
+	return NewOpenLibrary(url, "https://covers.example", client)
+}
+
+func newTestGoogleBooks(url string, client *http.Client) MetadataProvider {
+	return NewGoogleBooks(url, "", client)
+}
@@ -0,0 +1,124 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultOpenLibraryURL       = "https://openlibrary.org"
+	DefaultOpenLibraryCoversURL = "https://covers.openlibrary.org"
+)
+
+// openLibraryLanguages maps the MARC codes used by Open Library to ISO 639-1
+var openLibraryLanguages = map[string]string{
+	"eng": "en", "vie": "vi", "fre": "fr", "ger": "de", "spa": "es", "ita": "it", "por": "pt",
+	"rus": "ru", "jpn": "ja", "chi": "zh", "kor": "ko", "dut": "nl", "swe": "sv", "pol": "pl",
+}
+
+// OpenLibrary looks books up with the Open Library Books API (jscmd=details)
+type OpenLibrary struct {
+	baseURL   string
+	coversURL string
+	client    *http.Client
+}
+
+func NewOpenLibrary(baseURL, coversURL string, client *http.Client) *OpenLibrary {
+	if baseURL == "" {
+		baseURL = DefaultOpenLibraryURL
+	}
+	if coversURL == "" {
+		coversURL = DefaultOpenLibraryCoversURL
+	}
+	return &OpenLibrary{baseURL: trimSlash(baseURL), coversURL: trimSlash(coversURL), client: clientOrDefault(client)}
+}
+
+func (p *OpenLibrary) Name() string {
+	return "openlibrary"
+}
+
+// openLibraryText is a plain string or a {"type": "/type/text", "value": "..."} object
+type openLibraryText string
+
+func (t *openLibraryText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = openLibraryText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = openLibraryText(v.Value)
+	return nil
+}
+
+type openLibraryRecord struct {
+	Details struct {
+		Title       string          `json:"title"`
+		Subtitle    string          `json:"subtitle"`
+		Publishers  []string        `json:"publishers"`
+		PublishDate string          `json:"publish_date"`
+		Description openLibraryText `json:"description"`
+		Covers      []int           `json:"covers"`
+		Authors     []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+		Languages []struct {
+			Key string `json:"key"` // e.g. "/languages/eng"
+		} `json:"languages"`
+	} `json:"details"`
+}
+
+func (p *OpenLibrary) LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"details"}}
+
+	body, err := getJSON(ctx, p.client, p.baseURL+"/api/books?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	// Unknown ISBNs answer 200 with an empty object
+	var records map[string]openLibraryRecord
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	record, ok := records[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	d := record.Details
+	md := &BookMetadata{
+		ISBN:          isbn,
+		Title:         d.Title,
+		PublishedDate: d.PublishDate,
+		Description:   strings.TrimSpace(string(d.Description)),
+		Sources:       []string{p.Name()},
+	}
+	if d.Subtitle != "" {
+		md.Title += ": " + d.Subtitle
+	}
+	for _, a := range d.Authors {
+		md.Authors = append(md.Authors, a.Name)
+	}
+	if len(d.Publishers) > 0 {
+		md.Publisher = d.Publishers[0]
+	}
+	md.PublicationYear = parseYear(d.PublishDate)
+	if len(d.Covers) > 0 && d.Covers[0] > 0 {
+		md.CoverURL = fmt.Sprintf("%s/b/id/%d-L.jpg", p.coversURL, d.Covers[0])
+	}
+	if len(d.Languages) > 0 {
+		md.Language = openLibraryLanguages[strings.TrimPrefix(d.Languages[0].Key, "/languages/")]
+	}
+	return md, nil
+}
@@ -0,0 +1,26 @@
+package metadata
+
+import (
+	config "book-management/configs"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewProviders builds the providers listed in the config, in order, sharing one HTTP client
+func NewProviders(cfg *config.Config) ([]MetadataProvider, error) {
+	client := &http.Client{Timeout: time.Duration(cfg.MetadataTimeoutSeconds) * time.Second}
+
+	providers := make([]MetadataProvider, 0, len(cfg.MetadataProviders))
+	for _, name := range cfg.MetadataProviders {
+		switch name {
+		case "openlibrary":
+			providers = append(providers, NewOpenLibrary(cfg.OpenLibraryURL, cfg.OpenLibraryCoversURL, client))
+		case "googlebooks":
+			providers = append(providers, NewGoogleBooks(cfg.GoogleBooksURL, cfg.GoogleBooksAPIKey, client))
+		default:
+			return nil, fmt.Errorf("unknown metadata provider [%s], allowed: openlibrary, googlebooks", name)
+		}
+	}
+	return providers, nil
+}
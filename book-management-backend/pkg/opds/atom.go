@@ -0,0 +1,190 @@
+package opds
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// Namespaces of an OPDS 1.2 feed
+const (
+	NamespaceAtom       = "http://www.w3.org/2005/Atom"
+	NamespaceDC         = "http://purl.org/dc/terms/"
+	NamespaceOPDS       = "http://opds-spec.org/2010/catalog"
+	NamespaceOpenSearch = "http://a9.com/-/spec/opensearch/1.1/"
+)
+
+type atomFeed struct {
+	XMLName      xml.Name    `xml:"feed"`
+	Xmlns        string      `xml:"xmlns,attr"`
+	XmlnsDC      string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS    string      `xml:"xmlns:opds,attr"`
+	XmlnsOS      string      `xml:"xmlns:opensearch,attr"`
+	ID           string      `xml:"id"`
+	Title        string      `xml:"title"`
+	Updated      string      `xml:"updated"`
+	Author       *atomPerson `xml:"author,omitempty"`
+	TotalResults string      `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage string      `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex   string      `xml:"opensearch:startIndex,omitempty"`
+	Links        []atomLink  `xml:"link"`
+	Entries      []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomPerson   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Language   string         `xml:"dc:language,omitempty"`
+	Publisher  string         `xml:"dc:publisher,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Links      []atomLink     `xml:"link"`
+}
+
+// atomType is the Atom type of a link, links to other feeds get the profile of the feed kind
+func atomType(l Link) string {
+	if l.Type != "" || l.Kind == "" {
+		return l.Type
+	}
+	if l.Kind == KindNavigation {
+		return TypeNavigation
+	}
+	return TypeAcquisition
+}
+
+func atomLinks(links []Link) []atomLink {
+	out := make([]atomLink, 0, len(links))
+	for _, l := range links {
+		if l.Templated {
+			continue // Atom has no URI templates, search goes through the OpenSearch description
+		}
+		out = append(out, atomLink{Rel: l.Rel, Href: l.Href, Type: atomType(l), Title: l.Title})
+	}
+	return out
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// MarshalAtom serializes the feed as an OPDS 1.2 Atom document
+func (f *Feed) MarshalAtom() ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:     NamespaceAtom,
+		XmlnsDC:   NamespaceDC,
+		XmlnsOPDS: NamespaceOPDS,
+		XmlnsOS:   NamespaceOpenSearch,
+		ID:        f.ID,
+		Title:     f.Title,
+		Updated:   atomTime(f.Updated),
+		Links:     atomLinks(f.Links),
+	}
+	if f.ItemsPerPage > 0 {
+		feed.TotalResults = strconv.FormatInt(f.TotalResults, 10)
+		feed.ItemsPerPage = strconv.Itoa(f.ItemsPerPage)
+		feed.StartIndex = strconv.Itoa(f.StartIndex)
+	}
+
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			Title:      e.Title,
+			ID:         e.ID,
+			Updated:    atomTime(e.Updated),
+			Identifier: e.Identifier,
+			Language:   e.Language,
+			Publisher:  e.Publisher,
+			Issued:     e.Issued,
+			Links:      atomLinks(e.Links),
+		}
+		for _, a := range e.Authors {
+			entry.Authors = append(entry.Authors, atomPerson{Name: a.Name, URI: a.URI})
+		}
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c.Term, Label: c.Label})
+		}
+		if e.Summary != "" {
+			// Navigation entries describe themselves in content, publications in summary
+			text := &atomText{Type: "text", Text: e.Summary}
+			if f.Kind == KindNavigation {
+				entry.Content = text
+			} else {
+				entry.Summary = text
+			}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+type openSearchDescription struct {
+	XMLName        xml.Name        `xml:"OpenSearchDescription"`
+	Xmlns          string          `xml:"xmlns,attr"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// SearchTemplate is a search URL of the OpenSearch description, {searchTerms} is replaced by the query
+type SearchTemplate struct {
+	Type     string
+	Template string
+}
+
+// MarshalOpenSearch builds the OpenSearch 1.1 description document linked from Atom feeds
+func MarshalOpenSearch(shortName, description string, templates []SearchTemplate) ([]byte, error) {
+	doc := openSearchDescription{
+		Xmlns:          NamespaceOpenSearch,
+		ShortName:      shortName,
+		Description:    description,
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+	}
+	for _, t := range templates {
+		doc.URLs = append(doc.URLs, openSearchURL{Type: t.Type, Template: t.Template})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
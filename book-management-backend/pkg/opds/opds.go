@@ -0,0 +1,138 @@
+package opds
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+/*
+OPDS catalog feeds, built once as a version-neutral Feed and then serialized as
+OPDS 1.2 (Atom XML, see atom.go) or OPDS 2.0 (JSON, see opds2.go).
+- Navigation feeds list links to other feeds (genres, authors, newest books).
+- Acquisition feeds list publications with their metadata, cover and alternate links.
+All hrefs are expected to be absolute, e-reader apps resolve relative links inconsistently.
+*/
+
+// MIME types of catalog documents
+const (
+	TypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	TypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	TypeEntry       = "application/atom+xml;type=entry;profile=opds-catalog"
+	TypeOPDS2       = "application/opds+json"
+	TypeOpenSearch  = "application/opensearchdescription+xml"
+)
+
+// Link relations used by the catalog
+const (
+	RelSelf        = "self"
+	RelStart       = "start"
+	RelUp          = "up"
+	RelSearch      = "search"
+	RelNext        = "next"
+	RelPrevious    = "previous"
+	RelFirst       = "first"
+	RelSubsection  = "subsection"
+	RelAlternate   = "alternate"
+	RelRelated     = "related"
+	RelNew         = "http://opds-spec.org/sort/new"
+	RelImage       = "http://opds-spec.org/image"
+	RelThumbnail   = "http://opds-spec.org/image/thumbnail"
+	RelAcquisition = "http://opds-spec.org/acquisition"
+	RelSample      = "http://opds-spec.org/acquisition/sample"
+)
+
+// Kind tells navigation feeds from acquisition feeds
+type Kind string
+
+const (
+	KindNavigation  Kind = "navigation"
+	KindAcquisition Kind = "acquisition"
+)
+
+// Link is a typed link of a feed or an entry, the Type of a link to another feed should be
+// left empty: it is filled with the type of the target feed in the output version
+type Link struct {
+	Rel       string
+	Href      string
+	Type      string
+	Title     string
+	Kind      Kind // kind of the linked feed, used when Type is empty
+	Templated bool // Href is an RFC 6570 URI template (OPDS 2 only)
+}
+
+// Person is an author of an entry
+type Person struct {
+	Name string
+	URI  string
+}
+
+// Category is a subject of an entry
+type Category struct {
+	Term  string
+	Label string
+}
+
+// Entry is a navigation entry (Links to a feed) or a publication (metadata and links)
+type Entry struct {
+	ID         string
+	Title      string
+	Updated    time.Time
+	Summary    string
+	Authors    []Person
+	Categories []Category
+	Identifier string // e.g. "urn:isbn:9780261103573"
+	Language   string
+	Publisher  string
+	Issued     string // publication date or year
+	Series     string
+	Position   *float64
+	Links      []Link
+}
+
+// Feed is a page of a catalog
+type Feed struct {
+	ID      string
+	Title   string
+	Kind    Kind
+	Updated time.Time
+	Links   []Link
+	Entries []Entry
+
+	// OpenSearch paging, left zero when the feed is not paged
+	TotalResults int64
+	ItemsPerPage int
+	StartIndex   int // 1-based
+}
+
+// ImageType guesses the MIME type of a cover from its URL, JPEG is assumed when unknown
+func ImageType(href string) string {
+	if i := strings.IndexAny(href, "?#"); i >= 0 {
+		href = href[:i]
+	}
+	switch strings.ToLower(path.Ext(href)) {
+	case ".png":
+		return "image/png"
+	case ".webp":
+		return "image/webp"
+	case ".gif":
+		return "image/gif"
+	}
+	return "image/jpeg"
+}
+
+// CoverLinks returns the full size and thumbnail links of a cover, none when href is empty.
+// The thumbnail falls back to the full size image, both are expected to share a format.
+func CoverLinks(href, thumbnail string) []Link {
+	if href == "" {
+		return nil
+	}
+	if thumbnail == "" {
+		thumbnail = href
+	}
+	t := ImageType(href)
+	return []Link{
+		{Rel: RelImage, Href: href, Type: t},
+		{Rel: RelThumbnail, Href: thumbnail, Type: t},
+	}
+}
@@ -0,0 +1,147 @@
+package opds
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type opds2Feed struct {
+	Metadata     opds2FeedMetadata  `json:"metadata"`
+	Links        []opds2Link        `json:"links"`
+	Navigation   []opds2Link        `json:"navigation,omitempty"`
+	Publications []opds2Publication `json:"publications,omitempty"`
+}
+
+type opds2FeedMetadata struct {
+	Title         string `json:"title"`
+	Modified      string `json:"modified"`
+	NumberOfItems *int64 `json:"numberOfItems,omitempty"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+}
+
+type opds2Link struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type opds2Contributor struct {
+	Name  string      `json:"name"`
+	Links []opds2Link `json:"links,omitempty"`
+}
+
+type opds2Subject struct {
+	Name string `json:"name"`
+	Code string `json:"code,omitempty"`
+}
+
+type opds2SeriesRef struct {
+	Name     string   `json:"name"`
+	Position *float64 `json:"position,omitempty"`
+}
+
+type opds2PublicationMetadata struct {
+	Type        string                    `json:"@type"`
+	Title       string                    `json:"title"`
+	Identifier  string                    `json:"identifier,omitempty"`
+	Author      []opds2Contributor        `json:"author,omitempty"`
+	Publisher   string                    `json:"publisher,omitempty"`
+	Language    string                    `json:"language,omitempty"`
+	Published   string                    `json:"published,omitempty"`
+	Modified    string                    `json:"modified"`
+	Description string                    `json:"description,omitempty"`
+	Subject     []opds2Subject            `json:"subject,omitempty"`
+	BelongsTo   map[string]opds2SeriesRef `json:"belongsTo,omitempty"`
+}
+
+type opds2Publication struct {
+	Metadata opds2PublicationMetadata `json:"metadata"`
+	Links    []opds2Link              `json:"links"`
+	Images   []opds2Link              `json:"images,omitempty"`
+}
+
+func opds2Links(links []Link) []opds2Link {
+	out := make([]opds2Link, 0, len(links))
+	for _, l := range links {
+		t := l.Type
+		if t == "" && l.Kind != "" {
+			t = TypeOPDS2
+		}
+		out = append(out, opds2Link{Rel: l.Rel, Href: l.Href, Type: t, Title: l.Title, Templated: l.Templated})
+	}
+	return out
+}
+
+func opds2Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// MarshalOPDS2 serializes the feed as an OPDS 2.0 JSON document
+func (f *Feed) MarshalOPDS2() ([]byte, error) {
+	feed := opds2Feed{
+		Metadata: opds2FeedMetadata{Title: f.Title, Modified: opds2Time(f.Updated)},
+		Links:    opds2Links(f.Links),
+	}
+	if f.ItemsPerPage > 0 {
+		total := f.TotalResults
+		feed.Metadata.NumberOfItems = &total
+		feed.Metadata.ItemsPerPage = f.ItemsPerPage
+		feed.Metadata.CurrentPage = (f.StartIndex-1)/f.ItemsPerPage + 1
+	}
+
+	for _, e := range f.Entries {
+		if f.Kind == KindNavigation {
+			// A navigation entry is a single link to the feed it describes
+			for _, l := range opds2Links(e.Links) {
+				if l.Title == "" {
+					l.Title = e.Title
+				}
+				feed.Navigation = append(feed.Navigation, l)
+			}
+			continue
+		}
+
+		pub := opds2Publication{
+			Metadata: opds2PublicationMetadata{
+				Type:        "http://schema.org/Book",
+				Title:       e.Title,
+				Identifier:  e.Identifier,
+				Publisher:   e.Publisher,
+				Language:    e.Language,
+				Published:   e.Issued,
+				Modified:    opds2Time(e.Updated),
+				Description: e.Summary,
+			},
+			Links: []opds2Link{},
+		}
+		for _, a := range e.Authors {
+			author := opds2Contributor{Name: a.Name}
+			if a.URI != "" {
+				author.Links = []opds2Link{{Href: a.URI, Type: TypeOPDS2}}
+			}
+			pub.Metadata.Author = append(pub.Metadata.Author, author)
+		}
+		for _, c := range e.Categories {
+			pub.Metadata.Subject = append(pub.Metadata.Subject, opds2Subject{Name: c.Label, Code: c.Term})
+		}
+		if e.Series != "" {
+			pub.Metadata.BelongsTo = map[string]opds2SeriesRef{"series": {Name: e.Series, Position: e.Position}}
+		}
+		for _, l := range opds2Links(e.Links) {
+			// Covers go to images, the thumbnail is the same picture
+			switch l.Rel {
+			case RelImage:
+				pub.Images = append(pub.Images, opds2Link{Href: l.Href, Type: l.Type})
+			case RelThumbnail:
+			default:
+				pub.Links = append(pub.Links, l)
+			}
+		}
+		feed.Publications = append(feed.Publications, pub)
+	}
+
+	return json.Marshal(feed)
+}
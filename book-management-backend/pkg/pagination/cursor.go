@@ -0,0 +1,50 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+/*
+This file handles keyset (cursor) pagination tokens:
+- A cursor remembers the sort key, its direction and the (value, id) of the last row of a page,
+  the next page starts strictly after that row.
+- Cursors are opaque to clients: base64url encoded JSON, they must be passed back unchanged.
+*/
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points right after the last row of a page ordered by (Key, id)
+type Cursor struct {
+	Key   string `json:"k"`           // sort key, e.g. "title" or "id"
+	Desc  bool   `json:"d,omitempty"` // sort direction
+	Value string `json:"v,omitempty"` // sort key value of the last row, empty when Key is "id"
+	ID    uint   `json:"i"`           // id of the last row, breaks ties on Value
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Key == "" || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// IDCursor returns the cursor of a listing ordered by id only
+func IDCursor(id uint) string {
+	return Cursor{Key: "id", ID: id}.Encode()
+}
@@ -0,0 +1,62 @@
+package pagination
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Link is one entry of an RFC 8288 Link header
+type Link struct {
+	Rel string
+	URL string
+}
+
+// FormatLinks joins links into a Link header value, e.g. `</api/books?offset=10>; rel="next"`
+func FormatLinks(links []Link) string {
+	parts := make([]string, len(links))
+	for i, l := range links {
+		parts[i] = fmt.Sprintf("<%s>; rel=\"%s\"", l.URL, l.Rel)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PageURL copies the request URL with some query parameters replaced and others removed,
+// so that filters and sorting carry over to the linked page
+func PageURL(u *url.URL, set map[string]string, drop ...string) string {
+	query := u.Query()
+	for _, key := range drop {
+		query.Del(key)
+	}
+	for key, value := range set {
+		query.Set(key, value)
+	}
+
+	next := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return next.String()
+}
+
+// PageLinks builds the next/prev/first links of a page, each mode links to pages of the same mode.
+// In cursor mode only "next" is known, in offset mode "prev" and "first" are added once past the first page.
+func PageLinks(u *url.URL, params Params, info PageInfo) []Link {
+	var links []Link
+	limit := strconv.Itoa(params.Limit)
+
+	switch {
+	case params.CursorMode() && info.NextCursor != "":
+		links = append(links, Link{Rel: "next", URL: PageURL(u, map[string]string{"cursor": info.NextCursor, "limit": limit}, "offset")})
+	case !params.CursorMode() && info.HasMore:
+		links = append(links, Link{Rel: "next", URL: PageURL(u, map[string]string{"offset": strconv.Itoa(params.Offset + params.Limit), "limit": limit})})
+	}
+
+	if !params.CursorMode() && params.Offset > 0 {
+		prev := max(params.Offset-params.Limit, 0)
+		links = append(links,
+			Link{Rel: "prev", URL: PageURL(u, map[string]string{"offset": strconv.Itoa(prev), "limit": limit})},
+			Link{Rel: "first", URL: PageURL(u, map[string]string{"offset": "0", "limit": limit})},
+		)
+	}
+
+	return links
+}
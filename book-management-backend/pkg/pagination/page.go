@@ -0,0 +1,38 @@
+package pagination
+
+import "net/url"
+
+// PageInfo is what a service knows about a page once it has been loaded
+type PageInfo struct {
+	HasMore    bool
+	NextCursor string // empty on the last page or when the sort cannot be expressed as a keyset
+	Total      *int64 // nil unless the total was requested
+}
+
+// Page is the "page" object of the {data, page} envelope returned by every list endpoint
+type Page struct {
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	Total      *int64  `json:"total"`                 // null unless include_total=true
+	Next       *string `json:"next"`                  // URL of the next page, null on the last page
+	NextCursor string  `json:"next_cursor,omitempty"` // cursor of the next page, when the sort allows it
+}
+
+// NewPage builds the page envelope and the matching Link header entries, u is the request URL
+func NewPage(u *url.URL, params Params, info PageInfo) (Page, []Link) {
+	links := PageLinks(u, params, info)
+
+	page := Page{
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+		Total:      info.Total,
+		NextCursor: info.NextCursor,
+	}
+	for _, l := range links {
+		if l.Rel == "next" {
+			next := l.URL
+			page.Next = &next
+		}
+	}
+	return page, links
+}
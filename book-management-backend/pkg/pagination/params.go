@@ -0,0 +1,116 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+/*
+This file handles the query parameters shared by every list endpoint:
+- limit: page size, defaults to DefaultLimit and is capped to the configured maximum.
+- offset: rows to skip (offset mode).
+- cursor: next_cursor of the previous page (cursor mode), cannot be combined with offset.
+- include_total: also count all matching rows.
+Garbage values are rejected instead of being silently replaced by defaults.
+*/
+
+const (
+	DefaultLimit    = 10
+	DefaultMaxLimit = 100
+)
+
+var maxLimit = DefaultMaxLimit
+
+// SetMaxLimit sets the largest accepted page size, non-positive values restore DefaultMaxLimit
+func SetMaxLimit(limit int) {
+	if limit < 1 {
+		limit = DefaultMaxLimit
+	}
+	maxLimit = limit
+}
+
+// MaxLimit returns the largest accepted page size
+func MaxLimit() int {
+	return maxLimit
+}
+
+// Params are the validated pagination parameters of a list request
+type Params struct {
+	Limit        int
+	Offset       int     // always 0 in cursor mode
+	Cursor       *Cursor // nil in offset mode
+	IncludeTotal bool
+}
+
+// CursorMode tells whether the page continues from a cursor instead of an offset
+func (p Params) CursorMode() bool {
+	return p.Cursor != nil
+}
+
+// AfterID returns the id a cursor of a listing ordered by id points after, 0 in offset mode
+func (p Params) AfterID() (uint, error) {
+	if p.Cursor == nil {
+		return 0, nil
+	}
+	if p.Cursor.Key != "id" || p.Cursor.Desc {
+		return 0, ErrInvalidCursor
+	}
+	return p.Cursor.ID, nil
+}
+
+// ParseLimit validates the limit of an endpoint with its own bounds (e.g. suggestions),
+// an empty value gives def and values outside 1-upper are rejected
+func ParseLimit(s string, def, upper int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 || limit > upper {
+		return 0, fmt.Errorf("invalid limit [%s], expected an integer between 1 and %d", s, upper)
+	}
+	return limit, nil
+}
+
+// Parse validates limit, offset, cursor and include_total from a request query
+func Parse(query url.Values) (Params, error) {
+	params := Params{Limit: DefaultLimit}
+
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 {
+			return params, fmt.Errorf("invalid limit [%s], expected a positive integer", s)
+		}
+		params.Limit = min(limit, maxLimit)
+	}
+
+	if s := query.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return params, fmt.Errorf("invalid offset [%s], expected a non-negative integer", s)
+		}
+		params.Offset = offset
+	}
+
+	if s := query.Get("cursor"); s != "" {
+		if params.Offset > 0 {
+			return params, errors.New("cursor and offset cannot be combined")
+		}
+		cursor, err := DecodeCursor(s)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = cursor
+	}
+
+	if s := query.Get("include_total"); s != "" {
+		includeTotal, err := strconv.ParseBool(s)
+		if err != nil {
+			return params, fmt.Errorf("invalid include_total [%s], expected true or false", s)
+		}
+		params.IncludeTotal = includeTotal
+	}
+
+	return params, nil
+}
@@ -0,0 +1,80 @@
+package schemaorg
+
+import "encoding/json"
+
+/*
+schema.org structured data as JSON-LD, see https://schema.org/Book.
+Only the properties the catalogue can fill are modelled, empty ones are omitted.
+*/
+
+const Context = "https://schema.org"
+
+// ContentType is the MIME type of a JSON-LD document
+const ContentType = "application/ld+json"
+
+// Book formats, see https://schema.org/BookFormatType
+const (
+	FormatHardcover = "https://schema.org/Hardcover"
+	FormatPaperback = "https://schema.org/Paperback"
+	FormatEBook     = "https://schema.org/EBook"
+	FormatAudiobook = "https://schema.org/AudiobookFormat"
+)
+
+type Person struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type Organization struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type BookSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type Book struct {
+	Context       string        `json:"@context,omitempty"`
+	Type          string        `json:"@type"`
+	ID            string        `json:"@id,omitempty"`
+	URL           string        `json:"url,omitempty"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description,omitempty"`
+	Image         string        `json:"image,omitempty"`
+	Author        *Person       `json:"author,omitempty"`
+	ISBN          string        `json:"isbn,omitempty"`
+	InLanguage    string        `json:"inLanguage,omitempty"`
+	Publisher     *Organization `json:"publisher,omitempty"`
+	DatePublished string        `json:"datePublished,omitempty"`
+	BookFormat    string        `json:"bookFormat,omitempty"`
+	Genre         []string      `json:"genre,omitempty"`
+	Keywords      string        `json:"keywords,omitempty"` // comma separated
+	IsPartOf      *BookSeries   `json:"isPartOf,omitempty"`
+	Position      *float64      `json:"position,omitempty"` // position in IsPartOf
+	DateModified  string        `json:"dateModified,omitempty"`
+}
+
+func NewPerson(name, url string) *Person {
+	return &Person{Type: "Person", Name: name, URL: url}
+}
+
+func NewOrganization(name string) *Organization {
+	return &Organization{Type: "Organization", Name: name}
+}
+
+func NewBookSeries(name string) *BookSeries {
+	return &BookSeries{Type: "BookSeries", Name: name}
+}
+
+// NewBook starts a top-level Book node, carrying the @context
+func NewBook(name string) *Book {
+	return &Book{Context: Context, Type: "Book", Name: name}
+}
+
+// Marshal encodes a node as JSON-LD, <, > and & are escaped so the result can be inlined in a <script> tag
+func Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
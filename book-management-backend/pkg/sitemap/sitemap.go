@@ -0,0 +1,76 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+/*
+XML sitemaps, see https://www.sitemaps.org/protocol.html.
+A sitemap holds at most MaxURLs URLs, larger sites publish a sitemap index of several sitemaps.
+*/
+
+const Namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// MaxURLs is the protocol limit of URLs per sitemap
+const MaxURLs = 50000
+
+// ContentType of sitemaps and sitemap indexes
+const ContentType = "application/xml"
+
+type URL struct {
+	Loc        string    `xml:"loc"`
+	LastMod    time.Time `xml:"-"`
+	ChangeFreq string    `xml:"changefreq,omitempty"`
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []xmlURL `xml:"sitemap"`
+}
+
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// MarshalURLSet builds a sitemap of urls
+func MarshalURLSet(urls []URL) ([]byte, error) {
+	set := urlSet{Xmlns: Namespace, URLs: make([]xmlURL, len(urls))}
+	for i, u := range urls {
+		set.URLs[i] = xmlURL{Loc: u.Loc, LastMod: lastMod(u.LastMod), ChangeFreq: u.ChangeFreq}
+	}
+	return marshal(set)
+}
+
+// MarshalIndex builds a sitemap index, each entry is the URL of a sitemap and the last change of its URLs
+func MarshalIndex(sitemaps []URL) ([]byte, error) {
+	index := sitemapIndex{Xmlns: Namespace, Sitemaps: make([]xmlURL, len(sitemaps))}
+	for i, s := range sitemaps {
+		index.Sitemaps[i] = xmlURL{Loc: s.Loc, LastMod: lastMod(s.LastMod)}
+	}
+	return marshal(index)
+}
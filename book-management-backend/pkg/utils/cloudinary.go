@@ -2,16 +2,62 @@ package utils
 
 import (
 	config "book-management/configs"
+	"book-management/pkg/filetype"
+	"book-management/pkg/imaging"
+	"bytes"
 	"context"
 	"fmt"
-	"mime/multipart"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
 type CloudinaryUtil struct {
-	cld *cloudinary.Cloudinary
+	cld       *cloudinary.Cloudinary
+	cloudName string
+}
+
+// ImageVariant is a resized copy generated by Cloudinary at upload time, the image is scaled
+// down to fit Width x Height keeping its aspect ratio (never scaled up)
+type ImageVariant struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+func (v ImageVariant) transformation() string {
+	return fmt.Sprintf("c_limit,w_%d,h_%d", v.Width, v.Height)
+}
+
+// CoverVariants are the sizes generated for book covers
+var CoverVariants = []ImageVariant{
+	{Name: "thumbnail", Width: 150, Height: 225},
+	{Name: "medium", Width: 400, Height: 600},
+	{Name: "large", Width: 1000, Height: 1500},
+}
+
+// UploadedImage locates an uploaded image and its variants by variant name
+type UploadedImage struct {
+	PublicID string
+	URL      string
+	Variants map[string]string
+}
+
+// orientationTransformations turn an image upright for each EXIF orientation, as the tag itself is stripped
+var orientationTransformations = map[int]string{
+	2: "a_hflip",
+	3: "a_180",
+	4: "a_vflip",
+	5: "a_90/a_hflip",
+	6: "a_90",
+	7: "a_270/a_hflip",
+	8: "a_270",
 }
 
 func NewCloudinaryUtil(cfg *config.Config) (*CloudinaryUtil, error) {
@@ -19,17 +65,185 @@ func NewCloudinaryUtil(cfg *config.Config) (*CloudinaryUtil, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &CloudinaryUtil{cld: cld}, nil
+	cld.Config.URL.Analytics = false // keep generated variant URLs clean
+	return &CloudinaryUtil{cld: cld, cloudName: cfg.CloudName}, nil
 }
 
-func (c *CloudinaryUtil) UploadImage(file multipart.File, fileHeader *multipart.FileHeader, folder string) (string, error) {
+// UploadImage stores a sanitized image under its content hash, so uploading the same image twice
+// does not create a second asset, and has Cloudinary generate the variants right away
+func (c *CloudinaryUtil) UploadImage(img *imaging.Image, folder string, variants []ImageVariant) (*UploadedImage, error) {
 	ctx := context.Background()
-	result, err := c.cld.Upload.Upload(ctx, file, uploader.UploadParams{
-		PublicID: fileHeader.Filename,
-		Folder:   folder,
+
+	eager := ""
+	for i, v := range variants {
+		if i > 0 {
+			eager += "|"
+		}
+		eager += v.transformation()
+	}
+
+	result, err := c.cld.Upload.Upload(ctx, bytes.NewReader(img.Data), uploader.UploadParams{
+		PublicID:       img.Hash,
+		Folder:         folder,
+		Overwrite:      api.Bool(false),
+		Transformation: orientationTransformations[img.Orientation],
+		Eager:          eager,
 	})
 	if err != nil {
-		return "", fmt.Errorf("cloudinary upload failed: %w", err)
+		return nil, fmt.Errorf("cloudinary upload failed: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("cloudinary upload failed: %s", result.Error.Message)
+	}
+
+	uploaded := &UploadedImage{
+		PublicID: result.PublicID,
+		URL:      result.SecureURL,
+		Variants: make(map[string]string, len(variants)),
+	}
+	// Variant URLs are built rather than read from the eager results, which are missing
+	// when the image already existed
+	for _, v := range variants {
+		asset, err := c.cld.Image(result.PublicID)
+		if err != nil {
+			return nil, err
+		}
+		asset.Transformation = v.transformation()
+		asset.Version = result.Version
+		url, err := asset.String()
+		if err != nil {
+			return nil, err
+		}
+		uploaded.Variants[v.Name] = url
+	}
+	return uploaded, nil
+}
+
+// DeleteImage removes an image and its derived variants, deleting a missing image is not an error
+func (c *CloudinaryUtil) DeleteImage(publicID string) error {
+	result, err := c.cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
+		PublicID:   publicID,
+		Invalidate: api.Bool(true), // purge cached copies from the CDN
+	})
+	if err != nil {
+		return fmt.Errorf("cloudinary delete failed: %w", err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("cloudinary delete failed: %s", result.Error.Message)
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("cloudinary delete failed: %s", result.Result)
+	}
+	return nil
+}
+
+// PublicIDFromURL recovers the public ID of an image delivered from this cloud, e.g.
+// https://res.cloudinary.com/<cloud>/image/upload/v123/books/cover.jpg -> books/cover.
+// ok is false for URLs of other hosts, which are not ours to delete.
+func (c *CloudinaryUtil) PublicIDFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "res.cloudinary.com" {
+		return "", false
+	}
+	rest, ok := strings.CutPrefix(u.Path, "/"+c.cloudName+"/image/upload/")
+	if !ok {
+		return "", false
+	}
+
+	// Drop the version segment (v + digits) when present
+	if version, after, found := strings.Cut(rest, "/"); found && len(version) > 1 && version[0] == 'v' && strings.Trim(version[1:], "0123456789") == "" {
+		rest = after
+	}
+	rest = strings.TrimSuffix(rest, path.Ext(rest))
+	return rest, rest != ""
+}
+
+// UploadedFile locates a private file, it is only reachable through OpenFile
+type UploadedFile struct {
+	PublicID     string
+	ResourceType string // "raw" for documents, "video" for audio
+	Bytes        int64
+}
+
+// fileResourceType is the Cloudinary resource type holding a format, audio has to be stored as video
+func fileResourceType(format filetype.Format) string {
+	if format == filetype.FormatMP3 {
+		return string(api.Video)
+	}
+	return string(api.File)
+}
+
+// UploadFile stores a digital file with the "authenticated" delivery type, so its URL is
+// useless without a signature and files are only served through this API
+func (c *CloudinaryUtil) UploadFile(r io.Reader, folder string, format filetype.Format) (*UploadedFile, error) {
+	resourceType := fileResourceType(format)
+	result, err := c.cld.Upload.Upload(context.Background(), r, uploader.UploadParams{
+		Folder:         folder,
+		ResourceType:   resourceType,
+		Type:           api.Authenticated,
+		UniqueFilename: api.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary upload failed: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("cloudinary upload failed: %s", result.Error.Message)
+	}
+	return &UploadedFile{PublicID: result.PublicID, ResourceType: resourceType, Bytes: int64(result.Bytes)}, nil
+}
+
+// OpenFile fetches a private file, byteRange is an optional HTTP Range header passed through
+// so audio players can seek. The caller closes the response body.
+func (c *CloudinaryUtil) OpenFile(ctx context.Context, publicID, resourceType string, format filetype.Format, byteRange string) (*http.Response, error) {
+	asset, err := c.cld.File(publicID)
+	if err != nil {
+		return nil, err
+	}
+	asset.AssetType = api.AssetType(resourceType)
+	asset.DeliveryType = api.Authenticated
+	asset.Config.URL.SignURL = true
+	if resourceType == string(api.Video) {
+		asset.PublicID = publicID + format.Extension()
+	}
+	fileURL, err := asset.String()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cloudinary download failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloudinary download failed: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// DeleteFile removes a private file, deleting a missing file is not an error
+func (c *CloudinaryUtil) DeleteFile(publicID, resourceType string) error {
+	result, err := c.cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
+		PublicID:     publicID,
+		Type:         api.Authenticated,
+		ResourceType: resourceType,
+		Invalidate:   api.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("cloudinary delete failed: %w", err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("cloudinary delete failed: %s", result.Error.Message)
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("cloudinary delete failed: %s", result.Result)
 	}
-	return result.SecureURL, nil
+	return nil
 }
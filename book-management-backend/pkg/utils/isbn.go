@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+/*
+This file handles ISBN helpers:
+- NormalizeISBN: strips separators and validates an ISBN-10 / ISBN-13 checksum.
+- ISBN10To13: converts an ISBN-10 into its ISBN-13 form.
+*/
+
+var ErrInvalidISBN = errors.New("invalid ISBN")
+
+// NormalizeISBN removes hyphens/spaces and validates the checksum
+// Returns the compact ISBN (10 or 13 characters) or ErrInvalidISBN
+func NormalizeISBN(isbn string) (string, error) {
+	compact := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn)))
+
+	switch len(compact) {
+	case 10:
+		if !validISBN10(compact) {
+			return "", ErrInvalidISBN
+		}
+	case 13:
+		if !validISBN13(compact) {
+			return "", ErrInvalidISBN
+		}
+	default:
+		return "", ErrInvalidISBN
+	}
+	return compact, nil
+}
+
+// ISBN10To13 converts a valid compact ISBN-10 into ISBN-13 (978 prefix)
+// ISBN-13 input is returned unchanged
+func ISBN10To13(isbn string) string {
+	if len(isbn) != 10 {
+		return isbn
+	}
+
+	body := "978" + isbn[:9]
+	sum := 0
+	for i, r := range body {
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	check := (10 - sum%10) % 10
+	return body + string(rune('0'+check))
+}
+
+func validISBN10(isbn string) bool {
+	sum := 0
+	for i, r := range isbn {
+		var d int
+		switch {
+		case r >= '0' && r <= '9':
+			d = int(r - '0')
+		case r == 'X' && i == 9:
+			d = 10
+		default:
+			return false
+		}
+		sum += d * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func validISBN13(isbn string) bool {
+	sum := 0
+	for i, r := range isbn {
+		if r < '0' || r > '9' {
+			return false
+		}
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+This file handles text normalisation helpers:
+- NormalizeName: canonical form of a name used for case-insensitive uniqueness.
+- RemoveAccents: strips diacritics (e.g. Vietnamese "Tiểu thuyết" -> "Tieu thuyet").
+- Slugify: builds a URL-safe slug from any name.
+*/
+
+// NormalizeName lowercases a name and collapses surrounding/inner whitespace
+// Example: "  Science   Fiction " -> "science fiction"
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// RemoveAccents strips combining marks from a string
+// "đ"/"Đ" are not decomposable in Unicode so they are mapped explicitly
+func RemoveAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return strings.NewReplacer("đ", "d", "Đ", "D").Replace(result)
+}
+
+// Slugify converts a name into a lowercase, dash-separated, ASCII slug
+// Example: "Khoa học Viễn tưởng" -> "khoa-hoc-vien-tuong"
+func Slugify(name string) string {
+	var b strings.Builder
+	lastDash := true // avoid leading dash
+
+	for _, r := range strings.ToLower(RemoveAccents(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+		if !lastDash {
+			b.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}